@@ -0,0 +1,4 @@
+// Package simv1 is version 1 of the simulation API, generated from sim.proto
+package simv1
+
+//go:generate protoc -I ../../.. --go_out=../../.. --go_opt=paths=source_relative --go-grpc_out=../../.. --go-grpc_opt=paths=source_relative api/sim/v1/sim.proto
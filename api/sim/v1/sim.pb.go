@@ -0,0 +1,1351 @@
+// The simulation API served by headless with -api. The version is in the
+// package, so a client built against v1 keeps working, or fails loudly,
+// rather than being handed something else. Changes that would break a v1
+// client go in a v2 package served alongside it.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: api/sim/v1/sim.proto
+
+package simv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type VersionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VersionRequest) Reset() {
+	*x = VersionRequest{}
+	mi := &file_api_sim_v1_sim_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VersionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VersionRequest) ProtoMessage() {}
+
+func (x *VersionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_sim_v1_sim_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VersionRequest.ProtoReflect.Descriptor instead.
+func (*VersionRequest) Descriptor() ([]byte, []int) {
+	return file_api_sim_v1_sim_proto_rawDescGZIP(), []int{0}
+}
+
+type VersionReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Api           int32                  `protobuf:"varint,1,opt,name=api,proto3" json:"api,omitempty"`
+	Snapshot      int32                  `protobuf:"varint,2,opt,name=snapshot,proto3" json:"snapshot,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VersionReply) Reset() {
+	*x = VersionReply{}
+	mi := &file_api_sim_v1_sim_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VersionReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VersionReply) ProtoMessage() {}
+
+func (x *VersionReply) ProtoReflect() protoreflect.Message {
+	mi := &file_api_sim_v1_sim_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VersionReply.ProtoReflect.Descriptor instead.
+func (*VersionReply) Descriptor() ([]byte, []int) {
+	return file_api_sim_v1_sim_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *VersionReply) GetApi() int32 {
+	if x != nil {
+		return x.Api
+	}
+	return 0
+}
+
+func (x *VersionReply) GetSnapshot() int32 {
+	if x != nil {
+		return x.Snapshot
+	}
+	return 0
+}
+
+type SpawnRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Name of the entity type, such as "tree"
+	Type string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	// Where in metres
+	X             float64 `protobuf:"fixed64,2,opt,name=x,proto3" json:"x,omitempty"`
+	Y             float64 `protobuf:"fixed64,3,opt,name=y,proto3" json:"y,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SpawnRequest) Reset() {
+	*x = SpawnRequest{}
+	mi := &file_api_sim_v1_sim_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SpawnRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SpawnRequest) ProtoMessage() {}
+
+func (x *SpawnRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_sim_v1_sim_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SpawnRequest.ProtoReflect.Descriptor instead.
+func (*SpawnRequest) Descriptor() ([]byte, []int) {
+	return file_api_sim_v1_sim_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *SpawnRequest) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *SpawnRequest) GetX() float64 {
+	if x != nil {
+		return x.X
+	}
+	return 0
+}
+
+func (x *SpawnRequest) GetY() float64 {
+	if x != nil {
+		return x.Y
+	}
+	return 0
+}
+
+type SpawnReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SpawnReply) Reset() {
+	*x = SpawnReply{}
+	mi := &file_api_sim_v1_sim_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SpawnReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SpawnReply) ProtoMessage() {}
+
+func (x *SpawnReply) ProtoReflect() protoreflect.Message {
+	mi := &file_api_sim_v1_sim_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SpawnReply.ProtoReflect.Descriptor instead.
+func (*SpawnReply) Descriptor() ([]byte, []int) {
+	return file_api_sim_v1_sim_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SpawnReply) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type StepRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Steps int32                  `protobuf:"varint,1,opt,name=steps,proto3" json:"steps,omitempty"`
+	// Send back a snapshot after the steps
+	Snapshot      bool `protobuf:"varint,2,opt,name=snapshot,proto3" json:"snapshot,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StepRequest) Reset() {
+	*x = StepRequest{}
+	mi := &file_api_sim_v1_sim_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StepRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StepRequest) ProtoMessage() {}
+
+func (x *StepRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_sim_v1_sim_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StepRequest.ProtoReflect.Descriptor instead.
+func (*StepRequest) Descriptor() ([]byte, []int) {
+	return file_api_sim_v1_sim_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *StepRequest) GetSteps() int32 {
+	if x != nil {
+		return x.Steps
+	}
+	return 0
+}
+
+func (x *StepRequest) GetSnapshot() bool {
+	if x != nil {
+		return x.Snapshot
+	}
+	return false
+}
+
+type StepReply struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Steps the world has taken altogether
+	Steps uint64 `protobuf:"varint,1,opt,name=steps,proto3" json:"steps,omitempty"`
+	Trees int32  `protobuf:"varint,2,opt,name=trees,proto3" json:"trees,omitempty"`
+	// JSON, as written by trees.World.WriteSnapshot, if asked for
+	Snapshot      []byte `protobuf:"bytes,3,opt,name=snapshot,proto3" json:"snapshot,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StepReply) Reset() {
+	*x = StepReply{}
+	mi := &file_api_sim_v1_sim_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StepReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StepReply) ProtoMessage() {}
+
+func (x *StepReply) ProtoReflect() protoreflect.Message {
+	mi := &file_api_sim_v1_sim_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StepReply.ProtoReflect.Descriptor instead.
+func (*StepReply) Descriptor() ([]byte, []int) {
+	return file_api_sim_v1_sim_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *StepReply) GetSteps() uint64 {
+	if x != nil {
+		return x.Steps
+	}
+	return 0
+}
+
+func (x *StepReply) GetTrees() int32 {
+	if x != nil {
+		return x.Trees
+	}
+	return 0
+}
+
+func (x *StepReply) GetSnapshot() []byte {
+	if x != nil {
+		return x.Snapshot
+	}
+	return nil
+}
+
+// A rectangle in metres
+type QueryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MinX          float64                `protobuf:"fixed64,1,opt,name=min_x,json=minX,proto3" json:"min_x,omitempty"`
+	MinY          float64                `protobuf:"fixed64,2,opt,name=min_y,json=minY,proto3" json:"min_y,omitempty"`
+	MaxX          float64                `protobuf:"fixed64,3,opt,name=max_x,json=maxX,proto3" json:"max_x,omitempty"`
+	MaxY          float64                `protobuf:"fixed64,4,opt,name=max_y,json=maxY,proto3" json:"max_y,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueryRequest) Reset() {
+	*x = QueryRequest{}
+	mi := &file_api_sim_v1_sim_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryRequest) ProtoMessage() {}
+
+func (x *QueryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_sim_v1_sim_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryRequest.ProtoReflect.Descriptor instead.
+func (*QueryRequest) Descriptor() ([]byte, []int) {
+	return file_api_sim_v1_sim_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *QueryRequest) GetMinX() float64 {
+	if x != nil {
+		return x.MinX
+	}
+	return 0
+}
+
+func (x *QueryRequest) GetMinY() float64 {
+	if x != nil {
+		return x.MinY
+	}
+	return 0
+}
+
+func (x *QueryRequest) GetMaxX() float64 {
+	if x != nil {
+		return x.MaxX
+	}
+	return 0
+}
+
+func (x *QueryRequest) GetMaxY() float64 {
+	if x != nil {
+		return x.MaxY
+	}
+	return 0
+}
+
+// Where an entity is and how it's moving, in metres, radians and seconds
+type Body struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	X             float64                `protobuf:"fixed64,3,opt,name=x,proto3" json:"x,omitempty"`
+	Y             float64                `protobuf:"fixed64,4,opt,name=y,proto3" json:"y,omitempty"`
+	Angle         float64                `protobuf:"fixed64,5,opt,name=angle,proto3" json:"angle,omitempty"`
+	Vx            float64                `protobuf:"fixed64,6,opt,name=vx,proto3" json:"vx,omitempty"`
+	Vy            float64                `protobuf:"fixed64,7,opt,name=vy,proto3" json:"vy,omitempty"`
+	Spin          float64                `protobuf:"fixed64,8,opt,name=spin,proto3" json:"spin,omitempty"`
+	Awake         bool                   `protobuf:"varint,9,opt,name=awake,proto3" json:"awake,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Body) Reset() {
+	*x = Body{}
+	mi := &file_api_sim_v1_sim_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Body) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Body) ProtoMessage() {}
+
+func (x *Body) ProtoReflect() protoreflect.Message {
+	mi := &file_api_sim_v1_sim_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Body.ProtoReflect.Descriptor instead.
+func (*Body) Descriptor() ([]byte, []int) {
+	return file_api_sim_v1_sim_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Body) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Body) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Body) GetX() float64 {
+	if x != nil {
+		return x.X
+	}
+	return 0
+}
+
+func (x *Body) GetY() float64 {
+	if x != nil {
+		return x.Y
+	}
+	return 0
+}
+
+func (x *Body) GetAngle() float64 {
+	if x != nil {
+		return x.Angle
+	}
+	return 0
+}
+
+func (x *Body) GetVx() float64 {
+	if x != nil {
+		return x.Vx
+	}
+	return 0
+}
+
+func (x *Body) GetVy() float64 {
+	if x != nil {
+		return x.Vy
+	}
+	return 0
+}
+
+func (x *Body) GetSpin() float64 {
+	if x != nil {
+		return x.Spin
+	}
+	return 0
+}
+
+func (x *Body) GetAwake() bool {
+	if x != nil {
+		return x.Awake
+	}
+	return false
+}
+
+type QueryReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Bodies        []*Body                `protobuf:"bytes,1,rep,name=bodies,proto3" json:"bodies,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueryReply) Reset() {
+	*x = QueryReply{}
+	mi := &file_api_sim_v1_sim_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueryReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryReply) ProtoMessage() {}
+
+func (x *QueryReply) ProtoReflect() protoreflect.Message {
+	mi := &file_api_sim_v1_sim_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryReply.ProtoReflect.Descriptor instead.
+func (*QueryReply) Descriptor() ([]byte, []int) {
+	return file_api_sim_v1_sim_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *QueryReply) GetBodies() []*Body {
+	if x != nil {
+		return x.Bodies
+	}
+	return nil
+}
+
+type SnapshotRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SnapshotRequest) Reset() {
+	*x = SnapshotRequest{}
+	mi := &file_api_sim_v1_sim_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SnapshotRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SnapshotRequest) ProtoMessage() {}
+
+func (x *SnapshotRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_sim_v1_sim_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SnapshotRequest.ProtoReflect.Descriptor instead.
+func (*SnapshotRequest) Descriptor() ([]byte, []int) {
+	return file_api_sim_v1_sim_proto_rawDescGZIP(), []int{9}
+}
+
+type SnapshotsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Steps between snapshots, 1 if not set
+	Every         int32 `protobuf:"varint,1,opt,name=every,proto3" json:"every,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SnapshotsRequest) Reset() {
+	*x = SnapshotsRequest{}
+	mi := &file_api_sim_v1_sim_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SnapshotsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SnapshotsRequest) ProtoMessage() {}
+
+func (x *SnapshotsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_sim_v1_sim_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SnapshotsRequest.ProtoReflect.Descriptor instead.
+func (*SnapshotsRequest) Descriptor() ([]byte, []int) {
+	return file_api_sim_v1_sim_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *SnapshotsRequest) GetEvery() int32 {
+	if x != nil {
+		return x.Every
+	}
+	return 0
+}
+
+type SnapshotReply struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Steps the world had taken when the snapshot was taken
+	Steps uint64 `protobuf:"varint,1,opt,name=steps,proto3" json:"steps,omitempty"`
+	// JSON, as written by trees.World.WriteSnapshot
+	Snapshot      []byte `protobuf:"bytes,2,opt,name=snapshot,proto3" json:"snapshot,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SnapshotReply) Reset() {
+	*x = SnapshotReply{}
+	mi := &file_api_sim_v1_sim_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SnapshotReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SnapshotReply) ProtoMessage() {}
+
+func (x *SnapshotReply) ProtoReflect() protoreflect.Message {
+	mi := &file_api_sim_v1_sim_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SnapshotReply.ProtoReflect.Descriptor instead.
+func (*SnapshotReply) Descriptor() ([]byte, []int) {
+	return file_api_sim_v1_sim_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *SnapshotReply) GetSteps() uint64 {
+	if x != nil {
+		return x.Steps
+	}
+	return 0
+}
+
+func (x *SnapshotReply) GetSnapshot() []byte {
+	if x != nil {
+		return x.Snapshot
+	}
+	return nil
+}
+
+type RestoreRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// JSON or gzipped JSON, as read by trees.World.ReadSnapshot, so snapshots
+	// written by older versions are upgraded
+	Snapshot      []byte `protobuf:"bytes,1,opt,name=snapshot,proto3" json:"snapshot,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreRequest) Reset() {
+	*x = RestoreRequest{}
+	mi := &file_api_sim_v1_sim_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreRequest) ProtoMessage() {}
+
+func (x *RestoreRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_sim_v1_sim_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreRequest.ProtoReflect.Descriptor instead.
+func (*RestoreRequest) Descriptor() ([]byte, []int) {
+	return file_api_sim_v1_sim_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *RestoreRequest) GetSnapshot() []byte {
+	if x != nil {
+		return x.Snapshot
+	}
+	return nil
+}
+
+type RestoreReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreReply) Reset() {
+	*x = RestoreReply{}
+	mi := &file_api_sim_v1_sim_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreReply) ProtoMessage() {}
+
+func (x *RestoreReply) ProtoReflect() protoreflect.Message {
+	mi := &file_api_sim_v1_sim_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreReply.ProtoReflect.Descriptor instead.
+func (*RestoreReply) Descriptor() ([]byte, []int) {
+	return file_api_sim_v1_sim_proto_rawDescGZIP(), []int{13}
+}
+
+type RunRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Running       bool                   `protobuf:"varint,1,opt,name=running,proto3" json:"running,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RunRequest) Reset() {
+	*x = RunRequest{}
+	mi := &file_api_sim_v1_sim_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RunRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RunRequest) ProtoMessage() {}
+
+func (x *RunRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_sim_v1_sim_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RunRequest.ProtoReflect.Descriptor instead.
+func (*RunRequest) Descriptor() ([]byte, []int) {
+	return file_api_sim_v1_sim_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *RunRequest) GetRunning() bool {
+	if x != nil {
+		return x.Running
+	}
+	return false
+}
+
+type RunReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RunReply) Reset() {
+	*x = RunReply{}
+	mi := &file_api_sim_v1_sim_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RunReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RunReply) ProtoMessage() {}
+
+func (x *RunReply) ProtoReflect() protoreflect.Message {
+	mi := &file_api_sim_v1_sim_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RunReply.ProtoReflect.Descriptor instead.
+func (*RunReply) Descriptor() ([]byte, []int) {
+	return file_api_sim_v1_sim_proto_rawDescGZIP(), []int{15}
+}
+
+type ResetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Seed          int64                  `protobuf:"varint,1,opt,name=seed,proto3" json:"seed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResetRequest) Reset() {
+	*x = ResetRequest{}
+	mi := &file_api_sim_v1_sim_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResetRequest) ProtoMessage() {}
+
+func (x *ResetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_sim_v1_sim_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResetRequest.ProtoReflect.Descriptor instead.
+func (*ResetRequest) Descriptor() ([]byte, []int) {
+	return file_api_sim_v1_sim_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ResetRequest) GetSeed() int64 {
+	if x != nil {
+		return x.Seed
+	}
+	return 0
+}
+
+type EnvStepRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Where to drop the tree, from 0 at the left of the level's drop area to 1
+	// at its right
+	X             float64 `protobuf:"fixed64,1,opt,name=x,proto3" json:"x,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EnvStepRequest) Reset() {
+	*x = EnvStepRequest{}
+	mi := &file_api_sim_v1_sim_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EnvStepRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EnvStepRequest) ProtoMessage() {}
+
+func (x *EnvStepRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_sim_v1_sim_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EnvStepRequest.ProtoReflect.Descriptor instead.
+func (*EnvStepRequest) Descriptor() ([]byte, []int) {
+	return file_api_sim_v1_sim_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *EnvStepRequest) GetX() float64 {
+	if x != nil {
+		return x.X
+	}
+	return 0
+}
+
+// What fills each cell of a grid over the world, row by row from the bottom
+// left, one byte a cell: 0 for nothing, 1 for the ground, 2 for a tree and 3
+// for anything else
+type Occupancy struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MinX          float64                `protobuf:"fixed64,1,opt,name=min_x,json=minX,proto3" json:"min_x,omitempty"`
+	MinY          float64                `protobuf:"fixed64,2,opt,name=min_y,json=minY,proto3" json:"min_y,omitempty"`
+	MaxX          float64                `protobuf:"fixed64,3,opt,name=max_x,json=maxX,proto3" json:"max_x,omitempty"`
+	MaxY          float64                `protobuf:"fixed64,4,opt,name=max_y,json=maxY,proto3" json:"max_y,omitempty"`
+	Columns       int32                  `protobuf:"varint,5,opt,name=columns,proto3" json:"columns,omitempty"`
+	Rows          int32                  `protobuf:"varint,6,opt,name=rows,proto3" json:"rows,omitempty"`
+	Cells         []byte                 `protobuf:"bytes,7,opt,name=cells,proto3" json:"cells,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Occupancy) Reset() {
+	*x = Occupancy{}
+	mi := &file_api_sim_v1_sim_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Occupancy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Occupancy) ProtoMessage() {}
+
+func (x *Occupancy) ProtoReflect() protoreflect.Message {
+	mi := &file_api_sim_v1_sim_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Occupancy.ProtoReflect.Descriptor instead.
+func (*Occupancy) Descriptor() ([]byte, []int) {
+	return file_api_sim_v1_sim_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *Occupancy) GetMinX() float64 {
+	if x != nil {
+		return x.MinX
+	}
+	return 0
+}
+
+func (x *Occupancy) GetMinY() float64 {
+	if x != nil {
+		return x.MinY
+	}
+	return 0
+}
+
+func (x *Occupancy) GetMaxX() float64 {
+	if x != nil {
+		return x.MaxX
+	}
+	return 0
+}
+
+func (x *Occupancy) GetMaxY() float64 {
+	if x != nil {
+		return x.MaxY
+	}
+	return 0
+}
+
+func (x *Occupancy) GetColumns() int32 {
+	if x != nil {
+		return x.Columns
+	}
+	return 0
+}
+
+func (x *Occupancy) GetRows() int32 {
+	if x != nil {
+		return x.Rows
+	}
+	return 0
+}
+
+func (x *Occupancy) GetCells() []byte {
+	if x != nil {
+		return x.Cells
+	}
+	return nil
+}
+
+type Observation struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Height in metres of the top of the settled pile in each column across
+	// the drop area
+	Heights []float64 `protobuf:"fixed64,1,rep,packed,name=heights,proto3" json:"heights,omitempty"`
+	// Trees still moving, and trees left to drop in the episode
+	Moving        int32      `protobuf:"varint,2,opt,name=moving,proto3" json:"moving,omitempty"`
+	Left          int32      `protobuf:"varint,3,opt,name=left,proto3" json:"left,omitempty"`
+	Grid          *Occupancy `protobuf:"bytes,4,opt,name=grid,proto3" json:"grid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Observation) Reset() {
+	*x = Observation{}
+	mi := &file_api_sim_v1_sim_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Observation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Observation) ProtoMessage() {}
+
+func (x *Observation) ProtoReflect() protoreflect.Message {
+	mi := &file_api_sim_v1_sim_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Observation.ProtoReflect.Descriptor instead.
+func (*Observation) Descriptor() ([]byte, []int) {
+	return file_api_sim_v1_sim_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *Observation) GetHeights() []float64 {
+	if x != nil {
+		return x.Heights
+	}
+	return nil
+}
+
+func (x *Observation) GetMoving() int32 {
+	if x != nil {
+		return x.Moving
+	}
+	return 0
+}
+
+func (x *Observation) GetLeft() int32 {
+	if x != nil {
+		return x.Left
+	}
+	return 0
+}
+
+func (x *Observation) GetGrid() *Occupancy {
+	if x != nil {
+		return x.Grid
+	}
+	return nil
+}
+
+type EnvStepReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Observation   *Observation           `protobuf:"bytes,1,opt,name=observation,proto3" json:"observation,omitempty"`
+	Reward        float64                `protobuf:"fixed64,2,opt,name=reward,proto3" json:"reward,omitempty"`
+	Done          bool                   `protobuf:"varint,3,opt,name=done,proto3" json:"done,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EnvStepReply) Reset() {
+	*x = EnvStepReply{}
+	mi := &file_api_sim_v1_sim_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EnvStepReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EnvStepReply) ProtoMessage() {}
+
+func (x *EnvStepReply) ProtoReflect() protoreflect.Message {
+	mi := &file_api_sim_v1_sim_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EnvStepReply.ProtoReflect.Descriptor instead.
+func (*EnvStepReply) Descriptor() ([]byte, []int) {
+	return file_api_sim_v1_sim_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *EnvStepReply) GetObservation() *Observation {
+	if x != nil {
+		return x.Observation
+	}
+	return nil
+}
+
+func (x *EnvStepReply) GetReward() float64 {
+	if x != nil {
+		return x.Reward
+	}
+	return 0
+}
+
+func (x *EnvStepReply) GetDone() bool {
+	if x != nil {
+		return x.Done
+	}
+	return false
+}
+
+var File_api_sim_v1_sim_proto protoreflect.FileDescriptor
+
+const file_api_sim_v1_sim_proto_rawDesc = "" +
+	"\n" +
+	"\x14api/sim/v1/sim.proto\x12\x13fallingtrees.sim.v1\"\x10\n" +
+	"\x0eVersionRequest\"<\n" +
+	"\fVersionReply\x12\x10\n" +
+	"\x03api\x18\x01 \x01(\x05R\x03api\x12\x1a\n" +
+	"\bsnapshot\x18\x02 \x01(\x05R\bsnapshot\">\n" +
+	"\fSpawnRequest\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\f\n" +
+	"\x01x\x18\x02 \x01(\x01R\x01x\x12\f\n" +
+	"\x01y\x18\x03 \x01(\x01R\x01y\"\x1c\n" +
+	"\n" +
+	"SpawnReply\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\"?\n" +
+	"\vStepRequest\x12\x14\n" +
+	"\x05steps\x18\x01 \x01(\x05R\x05steps\x12\x1a\n" +
+	"\bsnapshot\x18\x02 \x01(\bR\bsnapshot\"S\n" +
+	"\tStepReply\x12\x14\n" +
+	"\x05steps\x18\x01 \x01(\x04R\x05steps\x12\x14\n" +
+	"\x05trees\x18\x02 \x01(\x05R\x05trees\x12\x1a\n" +
+	"\bsnapshot\x18\x03 \x01(\fR\bsnapshot\"b\n" +
+	"\fQueryRequest\x12\x13\n" +
+	"\x05min_x\x18\x01 \x01(\x01R\x04minX\x12\x13\n" +
+	"\x05min_y\x18\x02 \x01(\x01R\x04minY\x12\x13\n" +
+	"\x05max_x\x18\x03 \x01(\x01R\x04maxX\x12\x13\n" +
+	"\x05max_y\x18\x04 \x01(\x01R\x04maxY\"\xa6\x01\n" +
+	"\x04Body\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\f\n" +
+	"\x01x\x18\x03 \x01(\x01R\x01x\x12\f\n" +
+	"\x01y\x18\x04 \x01(\x01R\x01y\x12\x14\n" +
+	"\x05angle\x18\x05 \x01(\x01R\x05angle\x12\x0e\n" +
+	"\x02vx\x18\x06 \x01(\x01R\x02vx\x12\x0e\n" +
+	"\x02vy\x18\a \x01(\x01R\x02vy\x12\x12\n" +
+	"\x04spin\x18\b \x01(\x01R\x04spin\x12\x14\n" +
+	"\x05awake\x18\t \x01(\bR\x05awake\"?\n" +
+	"\n" +
+	"QueryReply\x121\n" +
+	"\x06bodies\x18\x01 \x03(\v2\x19.fallingtrees.sim.v1.BodyR\x06bodies\"\x11\n" +
+	"\x0fSnapshotRequest\"(\n" +
+	"\x10SnapshotsRequest\x12\x14\n" +
+	"\x05every\x18\x01 \x01(\x05R\x05every\"A\n" +
+	"\rSnapshotReply\x12\x14\n" +
+	"\x05steps\x18\x01 \x01(\x04R\x05steps\x12\x1a\n" +
+	"\bsnapshot\x18\x02 \x01(\fR\bsnapshot\",\n" +
+	"\x0eRestoreRequest\x12\x1a\n" +
+	"\bsnapshot\x18\x01 \x01(\fR\bsnapshot\"\x0e\n" +
+	"\fRestoreReply\"&\n" +
+	"\n" +
+	"RunRequest\x12\x18\n" +
+	"\arunning\x18\x01 \x01(\bR\arunning\"\n" +
+	"\n" +
+	"\bRunReply\"\"\n" +
+	"\fResetRequest\x12\x12\n" +
+	"\x04seed\x18\x01 \x01(\x03R\x04seed\"\x1e\n" +
+	"\x0eEnvStepRequest\x12\f\n" +
+	"\x01x\x18\x01 \x01(\x01R\x01x\"\xa3\x01\n" +
+	"\tOccupancy\x12\x13\n" +
+	"\x05min_x\x18\x01 \x01(\x01R\x04minX\x12\x13\n" +
+	"\x05min_y\x18\x02 \x01(\x01R\x04minY\x12\x13\n" +
+	"\x05max_x\x18\x03 \x01(\x01R\x04maxX\x12\x13\n" +
+	"\x05max_y\x18\x04 \x01(\x01R\x04maxY\x12\x18\n" +
+	"\acolumns\x18\x05 \x01(\x05R\acolumns\x12\x12\n" +
+	"\x04rows\x18\x06 \x01(\x05R\x04rows\x12\x14\n" +
+	"\x05cells\x18\a \x01(\fR\x05cells\"\x87\x01\n" +
+	"\vObservation\x12\x18\n" +
+	"\aheights\x18\x01 \x03(\x01R\aheights\x12\x16\n" +
+	"\x06moving\x18\x02 \x01(\x05R\x06moving\x12\x12\n" +
+	"\x04left\x18\x03 \x01(\x05R\x04left\x122\n" +
+	"\x04grid\x18\x04 \x01(\v2\x1e.fallingtrees.sim.v1.OccupancyR\x04grid\"~\n" +
+	"\fEnvStepReply\x12B\n" +
+	"\vobservation\x18\x01 \x01(\v2 .fallingtrees.sim.v1.ObservationR\vobservation\x12\x16\n" +
+	"\x06reward\x18\x02 \x01(\x01R\x06reward\x12\x12\n" +
+	"\x04done\x18\x03 \x01(\bR\x04done2\x86\x05\n" +
+	"\x03Sim\x12Q\n" +
+	"\aVersion\x12#.fallingtrees.sim.v1.VersionRequest\x1a!.fallingtrees.sim.v1.VersionReply\x12K\n" +
+	"\x05Spawn\x12!.fallingtrees.sim.v1.SpawnRequest\x1a\x1f.fallingtrees.sim.v1.SpawnReply\x12H\n" +
+	"\x04Step\x12 .fallingtrees.sim.v1.StepRequest\x1a\x1e.fallingtrees.sim.v1.StepReply\x12K\n" +
+	"\x05Query\x12!.fallingtrees.sim.v1.QueryRequest\x1a\x1f.fallingtrees.sim.v1.QueryReply\x12T\n" +
+	"\bSnapshot\x12$.fallingtrees.sim.v1.SnapshotRequest\x1a\".fallingtrees.sim.v1.SnapshotReply\x12X\n" +
+	"\tSnapshots\x12%.fallingtrees.sim.v1.SnapshotsRequest\x1a\".fallingtrees.sim.v1.SnapshotReply0\x01\x12Q\n" +
+	"\aRestore\x12#.fallingtrees.sim.v1.RestoreRequest\x1a!.fallingtrees.sim.v1.RestoreReply\x12E\n" +
+	"\x03Run\x12\x1f.fallingtrees.sim.v1.RunRequest\x1a\x1d.fallingtrees.sim.v1.RunReply2\xa3\x01\n" +
+	"\x03Env\x12L\n" +
+	"\x05Reset\x12!.fallingtrees.sim.v1.ResetRequest\x1a .fallingtrees.sim.v1.Observation\x12N\n" +
+	"\x04Step\x12#.fallingtrees.sim.v1.EnvStepRequest\x1a!.fallingtrees.sim.v1.EnvStepReplyB3Z1github.com/scottyw/falling-trees/api/sim/v1;simv1b\x06proto3"
+
+var (
+	file_api_sim_v1_sim_proto_rawDescOnce sync.Once
+	file_api_sim_v1_sim_proto_rawDescData []byte
+)
+
+func file_api_sim_v1_sim_proto_rawDescGZIP() []byte {
+	file_api_sim_v1_sim_proto_rawDescOnce.Do(func() {
+		file_api_sim_v1_sim_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_api_sim_v1_sim_proto_rawDesc), len(file_api_sim_v1_sim_proto_rawDesc)))
+	})
+	return file_api_sim_v1_sim_proto_rawDescData
+}
+
+var file_api_sim_v1_sim_proto_msgTypes = make([]protoimpl.MessageInfo, 21)
+var file_api_sim_v1_sim_proto_goTypes = []any{
+	(*VersionRequest)(nil),   // 0: fallingtrees.sim.v1.VersionRequest
+	(*VersionReply)(nil),     // 1: fallingtrees.sim.v1.VersionReply
+	(*SpawnRequest)(nil),     // 2: fallingtrees.sim.v1.SpawnRequest
+	(*SpawnReply)(nil),       // 3: fallingtrees.sim.v1.SpawnReply
+	(*StepRequest)(nil),      // 4: fallingtrees.sim.v1.StepRequest
+	(*StepReply)(nil),        // 5: fallingtrees.sim.v1.StepReply
+	(*QueryRequest)(nil),     // 6: fallingtrees.sim.v1.QueryRequest
+	(*Body)(nil),             // 7: fallingtrees.sim.v1.Body
+	(*QueryReply)(nil),       // 8: fallingtrees.sim.v1.QueryReply
+	(*SnapshotRequest)(nil),  // 9: fallingtrees.sim.v1.SnapshotRequest
+	(*SnapshotsRequest)(nil), // 10: fallingtrees.sim.v1.SnapshotsRequest
+	(*SnapshotReply)(nil),    // 11: fallingtrees.sim.v1.SnapshotReply
+	(*RestoreRequest)(nil),   // 12: fallingtrees.sim.v1.RestoreRequest
+	(*RestoreReply)(nil),     // 13: fallingtrees.sim.v1.RestoreReply
+	(*RunRequest)(nil),       // 14: fallingtrees.sim.v1.RunRequest
+	(*RunReply)(nil),         // 15: fallingtrees.sim.v1.RunReply
+	(*ResetRequest)(nil),     // 16: fallingtrees.sim.v1.ResetRequest
+	(*EnvStepRequest)(nil),   // 17: fallingtrees.sim.v1.EnvStepRequest
+	(*Occupancy)(nil),        // 18: fallingtrees.sim.v1.Occupancy
+	(*Observation)(nil),      // 19: fallingtrees.sim.v1.Observation
+	(*EnvStepReply)(nil),     // 20: fallingtrees.sim.v1.EnvStepReply
+}
+var file_api_sim_v1_sim_proto_depIdxs = []int32{
+	7,  // 0: fallingtrees.sim.v1.QueryReply.bodies:type_name -> fallingtrees.sim.v1.Body
+	18, // 1: fallingtrees.sim.v1.Observation.grid:type_name -> fallingtrees.sim.v1.Occupancy
+	19, // 2: fallingtrees.sim.v1.EnvStepReply.observation:type_name -> fallingtrees.sim.v1.Observation
+	0,  // 3: fallingtrees.sim.v1.Sim.Version:input_type -> fallingtrees.sim.v1.VersionRequest
+	2,  // 4: fallingtrees.sim.v1.Sim.Spawn:input_type -> fallingtrees.sim.v1.SpawnRequest
+	4,  // 5: fallingtrees.sim.v1.Sim.Step:input_type -> fallingtrees.sim.v1.StepRequest
+	6,  // 6: fallingtrees.sim.v1.Sim.Query:input_type -> fallingtrees.sim.v1.QueryRequest
+	9,  // 7: fallingtrees.sim.v1.Sim.Snapshot:input_type -> fallingtrees.sim.v1.SnapshotRequest
+	10, // 8: fallingtrees.sim.v1.Sim.Snapshots:input_type -> fallingtrees.sim.v1.SnapshotsRequest
+	12, // 9: fallingtrees.sim.v1.Sim.Restore:input_type -> fallingtrees.sim.v1.RestoreRequest
+	14, // 10: fallingtrees.sim.v1.Sim.Run:input_type -> fallingtrees.sim.v1.RunRequest
+	16, // 11: fallingtrees.sim.v1.Env.Reset:input_type -> fallingtrees.sim.v1.ResetRequest
+	17, // 12: fallingtrees.sim.v1.Env.Step:input_type -> fallingtrees.sim.v1.EnvStepRequest
+	1,  // 13: fallingtrees.sim.v1.Sim.Version:output_type -> fallingtrees.sim.v1.VersionReply
+	3,  // 14: fallingtrees.sim.v1.Sim.Spawn:output_type -> fallingtrees.sim.v1.SpawnReply
+	5,  // 15: fallingtrees.sim.v1.Sim.Step:output_type -> fallingtrees.sim.v1.StepReply
+	8,  // 16: fallingtrees.sim.v1.Sim.Query:output_type -> fallingtrees.sim.v1.QueryReply
+	11, // 17: fallingtrees.sim.v1.Sim.Snapshot:output_type -> fallingtrees.sim.v1.SnapshotReply
+	11, // 18: fallingtrees.sim.v1.Sim.Snapshots:output_type -> fallingtrees.sim.v1.SnapshotReply
+	13, // 19: fallingtrees.sim.v1.Sim.Restore:output_type -> fallingtrees.sim.v1.RestoreReply
+	15, // 20: fallingtrees.sim.v1.Sim.Run:output_type -> fallingtrees.sim.v1.RunReply
+	19, // 21: fallingtrees.sim.v1.Env.Reset:output_type -> fallingtrees.sim.v1.Observation
+	20, // 22: fallingtrees.sim.v1.Env.Step:output_type -> fallingtrees.sim.v1.EnvStepReply
+	13, // [13:23] is the sub-list for method output_type
+	3,  // [3:13] is the sub-list for method input_type
+	3,  // [3:3] is the sub-list for extension type_name
+	3,  // [3:3] is the sub-list for extension extendee
+	0,  // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_api_sim_v1_sim_proto_init() }
+func file_api_sim_v1_sim_proto_init() {
+	if File_api_sim_v1_sim_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_sim_v1_sim_proto_rawDesc), len(file_api_sim_v1_sim_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   21,
+			NumExtensions: 0,
+			NumServices:   2,
+		},
+		GoTypes:           file_api_sim_v1_sim_proto_goTypes,
+		DependencyIndexes: file_api_sim_v1_sim_proto_depIdxs,
+		MessageInfos:      file_api_sim_v1_sim_proto_msgTypes,
+	}.Build()
+	File_api_sim_v1_sim_proto = out.File
+	file_api_sim_v1_sim_proto_goTypes = nil
+	file_api_sim_v1_sim_proto_depIdxs = nil
+}
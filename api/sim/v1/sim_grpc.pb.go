@@ -0,0 +1,572 @@
+// The simulation API served by headless with -api. The version is in the
+// package, so a client built against v1 keeps working, or fails loudly,
+// rather than being handed something else. Changes that would break a v1
+// client go in a v2 package served alongside it.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: api/sim/v1/sim.proto
+
+package simv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Sim_Version_FullMethodName   = "/fallingtrees.sim.v1.Sim/Version"
+	Sim_Spawn_FullMethodName     = "/fallingtrees.sim.v1.Sim/Spawn"
+	Sim_Step_FullMethodName      = "/fallingtrees.sim.v1.Sim/Step"
+	Sim_Query_FullMethodName     = "/fallingtrees.sim.v1.Sim/Query"
+	Sim_Snapshot_FullMethodName  = "/fallingtrees.sim.v1.Sim/Snapshot"
+	Sim_Snapshots_FullMethodName = "/fallingtrees.sim.v1.Sim/Snapshots"
+	Sim_Restore_FullMethodName   = "/fallingtrees.sim.v1.Sim/Restore"
+	Sim_Run_FullMethodName       = "/fallingtrees.sim.v1.Sim/Run"
+)
+
+// SimClient is the client API for Sim service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Sim drives the world headless is running. Every call is run on the main
+// loop between steps.
+type SimClient interface {
+	// Which versions of the API and of snapshots are served
+	Version(ctx context.Context, in *VersionRequest, opts ...grpc.CallOption) (*VersionReply, error)
+	// Spawns an entity, returning its ID
+	Spawn(ctx context.Context, in *SpawnRequest, opts ...grpc.CallOption) (*SpawnReply, error)
+	// Steps the world, whether or not it's running in real time
+	Step(ctx context.Context, in *StepRequest, opts ...grpc.CallOption) (*StepReply, error)
+	// Lists the bodies in a rectangle in ID order
+	Query(ctx context.Context, in *QueryRequest, opts ...grpc.CallOption) (*QueryReply, error)
+	// Takes a snapshot of the world
+	Snapshot(ctx context.Context, in *SnapshotRequest, opts ...grpc.CallOption) (*SnapshotReply, error)
+	// Streams a snapshot every so many steps, however the world is stepped,
+	// until the client hangs up. Snapshots a slow client can't keep up with
+	// are skipped rather than holding up the world.
+	Snapshots(ctx context.Context, in *SnapshotsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SnapshotReply], error)
+	// Replaces the world with a snapshot
+	Restore(ctx context.Context, in *RestoreRequest, opts ...grpc.CallOption) (*RestoreReply, error)
+	// Starts or stops the world stepping itself in real time
+	Run(ctx context.Context, in *RunRequest, opts ...grpc.CallOption) (*RunReply, error)
+}
+
+type simClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSimClient(cc grpc.ClientConnInterface) SimClient {
+	return &simClient{cc}
+}
+
+func (c *simClient) Version(ctx context.Context, in *VersionRequest, opts ...grpc.CallOption) (*VersionReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VersionReply)
+	err := c.cc.Invoke(ctx, Sim_Version_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *simClient) Spawn(ctx context.Context, in *SpawnRequest, opts ...grpc.CallOption) (*SpawnReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SpawnReply)
+	err := c.cc.Invoke(ctx, Sim_Spawn_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *simClient) Step(ctx context.Context, in *StepRequest, opts ...grpc.CallOption) (*StepReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StepReply)
+	err := c.cc.Invoke(ctx, Sim_Step_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *simClient) Query(ctx context.Context, in *QueryRequest, opts ...grpc.CallOption) (*QueryReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(QueryReply)
+	err := c.cc.Invoke(ctx, Sim_Query_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *simClient) Snapshot(ctx context.Context, in *SnapshotRequest, opts ...grpc.CallOption) (*SnapshotReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SnapshotReply)
+	err := c.cc.Invoke(ctx, Sim_Snapshot_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *simClient) Snapshots(ctx context.Context, in *SnapshotsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SnapshotReply], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Sim_ServiceDesc.Streams[0], Sim_Snapshots_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SnapshotsRequest, SnapshotReply]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Sim_SnapshotsClient = grpc.ServerStreamingClient[SnapshotReply]
+
+func (c *simClient) Restore(ctx context.Context, in *RestoreRequest, opts ...grpc.CallOption) (*RestoreReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RestoreReply)
+	err := c.cc.Invoke(ctx, Sim_Restore_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *simClient) Run(ctx context.Context, in *RunRequest, opts ...grpc.CallOption) (*RunReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RunReply)
+	err := c.cc.Invoke(ctx, Sim_Run_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SimServer is the server API for Sim service.
+// All implementations must embed UnimplementedSimServer
+// for forward compatibility.
+//
+// Sim drives the world headless is running. Every call is run on the main
+// loop between steps.
+type SimServer interface {
+	// Which versions of the API and of snapshots are served
+	Version(context.Context, *VersionRequest) (*VersionReply, error)
+	// Spawns an entity, returning its ID
+	Spawn(context.Context, *SpawnRequest) (*SpawnReply, error)
+	// Steps the world, whether or not it's running in real time
+	Step(context.Context, *StepRequest) (*StepReply, error)
+	// Lists the bodies in a rectangle in ID order
+	Query(context.Context, *QueryRequest) (*QueryReply, error)
+	// Takes a snapshot of the world
+	Snapshot(context.Context, *SnapshotRequest) (*SnapshotReply, error)
+	// Streams a snapshot every so many steps, however the world is stepped,
+	// until the client hangs up. Snapshots a slow client can't keep up with
+	// are skipped rather than holding up the world.
+	Snapshots(*SnapshotsRequest, grpc.ServerStreamingServer[SnapshotReply]) error
+	// Replaces the world with a snapshot
+	Restore(context.Context, *RestoreRequest) (*RestoreReply, error)
+	// Starts or stops the world stepping itself in real time
+	Run(context.Context, *RunRequest) (*RunReply, error)
+	mustEmbedUnimplementedSimServer()
+}
+
+// UnimplementedSimServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedSimServer struct{}
+
+func (UnimplementedSimServer) Version(context.Context, *VersionRequest) (*VersionReply, error) {
+	return nil, status.Error(codes.Unimplemented, "method Version not implemented")
+}
+func (UnimplementedSimServer) Spawn(context.Context, *SpawnRequest) (*SpawnReply, error) {
+	return nil, status.Error(codes.Unimplemented, "method Spawn not implemented")
+}
+func (UnimplementedSimServer) Step(context.Context, *StepRequest) (*StepReply, error) {
+	return nil, status.Error(codes.Unimplemented, "method Step not implemented")
+}
+func (UnimplementedSimServer) Query(context.Context, *QueryRequest) (*QueryReply, error) {
+	return nil, status.Error(codes.Unimplemented, "method Query not implemented")
+}
+func (UnimplementedSimServer) Snapshot(context.Context, *SnapshotRequest) (*SnapshotReply, error) {
+	return nil, status.Error(codes.Unimplemented, "method Snapshot not implemented")
+}
+func (UnimplementedSimServer) Snapshots(*SnapshotsRequest, grpc.ServerStreamingServer[SnapshotReply]) error {
+	return status.Error(codes.Unimplemented, "method Snapshots not implemented")
+}
+func (UnimplementedSimServer) Restore(context.Context, *RestoreRequest) (*RestoreReply, error) {
+	return nil, status.Error(codes.Unimplemented, "method Restore not implemented")
+}
+func (UnimplementedSimServer) Run(context.Context, *RunRequest) (*RunReply, error) {
+	return nil, status.Error(codes.Unimplemented, "method Run not implemented")
+}
+func (UnimplementedSimServer) mustEmbedUnimplementedSimServer() {}
+func (UnimplementedSimServer) testEmbeddedByValue()             {}
+
+// UnsafeSimServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SimServer will
+// result in compilation errors.
+type UnsafeSimServer interface {
+	mustEmbedUnimplementedSimServer()
+}
+
+func RegisterSimServer(s grpc.ServiceRegistrar, srv SimServer) {
+	// If the following call panics, it indicates UnimplementedSimServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Sim_ServiceDesc, srv)
+}
+
+func _Sim_Version_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VersionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimServer).Version(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Sim_Version_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimServer).Version(ctx, req.(*VersionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sim_Spawn_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SpawnRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimServer).Spawn(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Sim_Spawn_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimServer).Spawn(ctx, req.(*SpawnRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sim_Step_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StepRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimServer).Step(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Sim_Step_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimServer).Step(ctx, req.(*StepRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sim_Query_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimServer).Query(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Sim_Query_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimServer).Query(ctx, req.(*QueryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sim_Snapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SnapshotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimServer).Snapshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Sim_Snapshot_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimServer).Snapshot(ctx, req.(*SnapshotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sim_Snapshots_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SnapshotsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SimServer).Snapshots(m, &grpc.GenericServerStream[SnapshotsRequest, SnapshotReply]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Sim_SnapshotsServer = grpc.ServerStreamingServer[SnapshotReply]
+
+func _Sim_Restore_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimServer).Restore(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Sim_Restore_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimServer).Restore(ctx, req.(*RestoreRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sim_Run_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RunRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimServer).Run(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Sim_Run_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimServer).Run(ctx, req.(*RunRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Sim_ServiceDesc is the grpc.ServiceDesc for Sim service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Sim_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "fallingtrees.sim.v1.Sim",
+	HandlerType: (*SimServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Version",
+			Handler:    _Sim_Version_Handler,
+		},
+		{
+			MethodName: "Spawn",
+			Handler:    _Sim_Spawn_Handler,
+		},
+		{
+			MethodName: "Step",
+			Handler:    _Sim_Step_Handler,
+		},
+		{
+			MethodName: "Query",
+			Handler:    _Sim_Query_Handler,
+		},
+		{
+			MethodName: "Snapshot",
+			Handler:    _Sim_Snapshot_Handler,
+		},
+		{
+			MethodName: "Restore",
+			Handler:    _Sim_Restore_Handler,
+		},
+		{
+			MethodName: "Run",
+			Handler:    _Sim_Run_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Snapshots",
+			Handler:       _Sim_Snapshots_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "api/sim/v1/sim.proto",
+}
+
+const (
+	Env_Reset_FullMethodName = "/fallingtrees.sim.v1.Env/Reset"
+	Env_Step_FullMethodName  = "/fallingtrees.sim.v1.Env/Step"
+)
+
+// EnvClient is the client API for Env service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Env is a reinforcement learning environment in a world of its own, which
+// drops a tree each step wherever the agent chooses
+type EnvClient interface {
+	// Starts an episode
+	Reset(ctx context.Context, in *ResetRequest, opts ...grpc.CallOption) (*Observation, error)
+	// Drops a tree and lets the world settle
+	Step(ctx context.Context, in *EnvStepRequest, opts ...grpc.CallOption) (*EnvStepReply, error)
+}
+
+type envClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewEnvClient(cc grpc.ClientConnInterface) EnvClient {
+	return &envClient{cc}
+}
+
+func (c *envClient) Reset(ctx context.Context, in *ResetRequest, opts ...grpc.CallOption) (*Observation, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Observation)
+	err := c.cc.Invoke(ctx, Env_Reset_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *envClient) Step(ctx context.Context, in *EnvStepRequest, opts ...grpc.CallOption) (*EnvStepReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EnvStepReply)
+	err := c.cc.Invoke(ctx, Env_Step_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// EnvServer is the server API for Env service.
+// All implementations must embed UnimplementedEnvServer
+// for forward compatibility.
+//
+// Env is a reinforcement learning environment in a world of its own, which
+// drops a tree each step wherever the agent chooses
+type EnvServer interface {
+	// Starts an episode
+	Reset(context.Context, *ResetRequest) (*Observation, error)
+	// Drops a tree and lets the world settle
+	Step(context.Context, *EnvStepRequest) (*EnvStepReply, error)
+	mustEmbedUnimplementedEnvServer()
+}
+
+// UnimplementedEnvServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedEnvServer struct{}
+
+func (UnimplementedEnvServer) Reset(context.Context, *ResetRequest) (*Observation, error) {
+	return nil, status.Error(codes.Unimplemented, "method Reset not implemented")
+}
+func (UnimplementedEnvServer) Step(context.Context, *EnvStepRequest) (*EnvStepReply, error) {
+	return nil, status.Error(codes.Unimplemented, "method Step not implemented")
+}
+func (UnimplementedEnvServer) mustEmbedUnimplementedEnvServer() {}
+func (UnimplementedEnvServer) testEmbeddedByValue()             {}
+
+// UnsafeEnvServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to EnvServer will
+// result in compilation errors.
+type UnsafeEnvServer interface {
+	mustEmbedUnimplementedEnvServer()
+}
+
+func RegisterEnvServer(s grpc.ServiceRegistrar, srv EnvServer) {
+	// If the following call panics, it indicates UnimplementedEnvServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Env_ServiceDesc, srv)
+}
+
+func _Env_Reset_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EnvServer).Reset(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Env_Reset_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EnvServer).Reset(ctx, req.(*ResetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Env_Step_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EnvStepRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EnvServer).Step(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Env_Step_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EnvServer).Step(ctx, req.(*EnvStepRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Env_ServiceDesc is the grpc.ServiceDesc for Env service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Env_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "fallingtrees.sim.v1.Env",
+	HandlerType: (*EnvServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Reset",
+			Handler:    _Env_Reset_Handler,
+		},
+		{
+			MethodName: "Step",
+			Handler:    _Env_Step_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "api/sim/v1/sim.proto",
+}
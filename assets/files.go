@@ -0,0 +1,103 @@
+package assets
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// Files is a directory or zip archive of files named with slashes
+type Files interface {
+	Names() ([]string, error)
+	Read(name string) ([]byte, error)
+}
+
+// Dir is the files in a directory and every directory under it
+type Dir string
+
+func (d Dir) Names() ([]string, error) {
+	var names []string
+	err := filepath.Walk(string(d), func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(string(d), p)
+		names = append(names, filepath.ToSlash(rel))
+		return err
+	})
+	return names, err
+}
+
+func (d Dir) Read(name string) ([]byte, error) {
+	return ioutil.ReadFile(filepath.Join(string(d), filepath.FromSlash(name)))
+}
+
+// FS is the files in a file system, such as a pack built into a program with
+// go:embed
+type FS struct {
+	fs.FS
+}
+
+func (f FS) Names() ([]string, error) {
+	var names []string
+	err := fs.WalkDir(f.FS, ".", func(p string, entry fs.DirEntry, err error) error {
+		if err == nil && !entry.IsDir() {
+			names = append(names, p)
+		}
+		return err
+	})
+	return names, err
+}
+
+func (f FS) Read(name string) ([]byte, error) {
+	return fs.ReadFile(f.FS, name)
+}
+
+// Zip holds a whole archive in memory, since packs and mods are small
+type Zip struct {
+	files map[string]*zip.File
+}
+
+// OpenZip reads a zip archive
+func OpenZip(p string) (*Zip, error) {
+	data, err := ioutil.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	z := &Zip{files: map[string]*zip.File{}}
+	for _, f := range r.File {
+		if !f.FileInfo().IsDir() {
+			z.files[f.Name] = f
+		}
+	}
+	return z, nil
+}
+
+func (z *Zip) Names() ([]string, error) {
+	var names []string
+	for name := range z.files {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func (z *Zip) Read(name string) ([]byte, error) {
+	f, ok := z.files[name]
+	if !ok {
+		return nil, fmt.Errorf("open %s: %w", name, os.ErrNotExist)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return ioutil.ReadAll(rc)
+}
@@ -0,0 +1,94 @@
+// Package assets loads the packs of art the trees are drawn with. A pack is a
+// spritesheet, trees.png, along with trees.json cutting it up into sprites
+// and a manifest.json vouching for every file in the pack, kept in a
+// directory or a zip archive. Every spritesheet comes in the colours of
+// summer, as drawn, and of autumn and winter too.
+package assets
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	_ "image/png" // Spritesheets are PNGs
+
+	"github.com/faiface/pixel"
+	"github.com/scottyw/falling-trees/trees"
+)
+
+// Manifest lists every file in an asset pack with the SHA-256 hash of its
+// contents, so that a corrupt download or a file swapped in from some other
+// pack is caught as it's loaded rather than showing up as odd sprites
+type Manifest struct {
+	Version int               `json:"version"`
+	Files   map[string]string `json:"files"`
+}
+
+// ManifestFormat is the format of manifest.json
+var ManifestFormat = trees.Format{Name: "asset manifest"}
+
+// Pack is a set of assets along with its manifest
+type Pack struct {
+	Name string
+
+	// Unchecked reads files without checking their hashes, for a pack that's
+	// being drawn and changes faster than its manifest is kept up to date.
+	// Files still have to be listed.
+	Unchecked bool
+
+	files    Files
+	manifest Manifest
+}
+
+// Open reads the manifest of the pack in a set of files
+func Open(name string, files Files) (*Pack, error) {
+	p := &Pack{Name: name, files: files}
+	data, err := files.Read("manifest.json")
+	if err != nil {
+		return nil, fmt.Errorf("asset pack %q: %v", name, err)
+	}
+	if err := ManifestFormat.Decode(bytes.NewReader(data), &p.manifest); err != nil {
+		return nil, fmt.Errorf("asset pack %q: manifest.json: %v", name, err)
+	}
+	return p, nil
+}
+
+// Files are where the pack is read from
+func (p *Pack) Files() Files {
+	return p.files
+}
+
+// Read loads one of the files in the pack, checking it against the manifest
+func (p *Pack) Read(file string) ([]byte, error) {
+	want, ok := p.manifest.Files[file]
+	if !ok {
+		return nil, fmt.Errorf("asset pack %q: %s isn't listed in its manifest", p.Name, file)
+	}
+	data, err := p.files.Read(file)
+	if err != nil {
+		return nil, fmt.Errorf("asset pack %q: %v", p.Name, err)
+	}
+	if p.Unchecked {
+		return data, nil
+	}
+	sum := sha256.Sum256(data)
+	if got := hex.EncodeToString(sum[:]); got != want {
+		return nil, fmt.Errorf("asset pack %q: %s is corrupt or from a different version of the pack: its SHA-256 is %s but the manifest says %s",
+			p.Name, file, got, want)
+	}
+	return data, nil
+}
+
+// Picture loads an image from the pack
+func (p *Pack) Picture(file string) (*pixel.PictureData, error) {
+	data, err := p.Read(file)
+	if err != nil {
+		return nil, err
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("asset pack %q: %s: %v", p.Name, file, err)
+	}
+	return pixel.PictureDataFromImage(img), nil
+}
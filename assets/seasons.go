@@ -0,0 +1,71 @@
+package assets
+
+import (
+	"image/color"
+	"sort"
+
+	"github.com/faiface/pixel"
+	"golang.org/x/image/colornames"
+)
+
+// Season is a palette the tree foliage can be swapped to. The ramp runs from
+// the darkest shade to the lightest.
+type Season struct {
+	Name string
+	Ramp []color.RGBA
+}
+
+// Seasons other than summer, which is the spritesheet as drawn
+var Seasons = []Season{
+	{"autumn", []color.RGBA{colornames.Darkred, colornames.Firebrick, colornames.Chocolate, colornames.Darkorange, colornames.Orange, colornames.Gold}},
+	{"winter", []color.RGBA{colornames.Slategray, colornames.Lightslategray, colornames.Lightsteelblue, colornames.Gainsboro, colornames.Whitesmoke, colornames.White}},
+}
+
+// foliage picks out the green leaves, leaving trunks and the like alone
+func foliage(c color.RGBA) bool {
+	return c.A > 0 && c.G > c.R && c.G > c.B
+}
+
+func luminance(c color.RGBA) float64 {
+	return 0.3*float64(c.R) + 0.59*float64(c.G) + 0.11*float64(c.B)
+}
+
+// AddSeasons stacks a recoloured copy of the spritesheet above the original
+// for every one of the Seasons, so all the variants share one picture. It
+// returns the combined picture and the offset in pixels of each season's
+// copy, summer's included.
+func AddSeasons(sheet *pixel.PictureData) (*pixel.PictureData, map[string]pixel.Vec) {
+	// Every shade of green in the sheet, darkest first
+	var greens []color.RGBA
+	seen := map[color.RGBA]bool{}
+	for _, c := range sheet.Pix {
+		if foliage(c) && !seen[c] {
+			seen[c] = true
+			greens = append(greens, c)
+		}
+	}
+	sort.Slice(greens, func(i, j int) bool { return luminance(greens[i]) < luminance(greens[j]) })
+
+	h := sheet.Rect.H()
+	atlas := pixel.MakePictureData(pixel.R(sheet.Rect.Min.X, sheet.Rect.Min.Y, sheet.Rect.Max.X, sheet.Rect.Max.Y+h*float64(len(Seasons))))
+	copy(atlas.Pix, sheet.Pix)
+	offsets := map[string]pixel.Vec{"summer": pixel.ZV}
+	for i, s := range Seasons {
+		// Swap each shade for the one at the same rank in the season's ramp
+		swap := map[color.RGBA]color.RGBA{}
+		for rank, c := range greens {
+			to := s.Ramp[rank*len(s.Ramp)/len(greens)]
+			to.A = c.A
+			swap[c] = to
+		}
+		start := (i + 1) * len(sheet.Pix)
+		for j, c := range sheet.Pix {
+			if to, ok := swap[c]; ok {
+				c = to
+			}
+			atlas.Pix[start+j] = c
+		}
+		offsets[s.Name] = pixel.V(0, h*float64(i+1))
+	}
+	return atlas, offsets
+}
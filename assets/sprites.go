@@ -0,0 +1,74 @@
+package assets
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/faiface/pixel"
+)
+
+// SpriteSheet describes the cells of trees.png. Columns and rows count from
+// the top left of the sheet and pivots are in pixels from the top left of the
+// cell, the same as an image editor shows them. An animated sprite has more
+// than one frame, in the cells to the right of its own, played FrameRate
+// times a second.
+type SpriteSheet struct {
+	Cell    float64
+	Sprites []struct {
+		Column, Row int
+		Pivot       [2]float64
+		Frames      int
+		FrameRate   float64
+	}
+}
+
+// Sprite is a tree sprite along with the point on it that sits over the
+// centre of the body, in pixels from the centre of the sprite's frame, ready
+// for Options.Sprite and Options.SpritePivot
+type Sprite struct {
+	*pixel.Sprite
+	Pivot pixel.Vec
+
+	// Frames of its animation, starting with Sprite, if it has more than one
+	Frames    []*pixel.Sprite
+	FrameRate float64
+}
+
+// Sprites cuts the pack's spritesheet into the sprites listed in its
+// metadata, with a set of sprites for every season
+func (p *Pack) Sprites() (map[string][]Sprite, error) {
+	spritesheet, err := p.Picture("trees.png")
+	if err != nil {
+		return nil, err
+	}
+	data, err := p.Read("trees.json")
+	if err != nil {
+		return nil, err
+	}
+	var meta SpriteSheet
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, fmt.Errorf("asset pack %q: trees.json: %v", p.Name, err)
+	}
+	atlas, offsets := AddSeasons(spritesheet)
+	sprites := map[string][]Sprite{}
+	for name, offset := range offsets {
+		sheet := spritesheet.Bounds().Moved(offset)
+		for _, s := range meta.Sprites {
+			var frames []*pixel.Sprite
+			for f := 0; f < s.Frames || f == 0; f++ {
+				min := pixel.V(sheet.Min.X+float64(s.Column+f)*meta.Cell, sheet.Max.Y-float64(s.Row+1)*meta.Cell)
+				if min.X+meta.Cell > sheet.Max.X+0.5 {
+					return nil, fmt.Errorf("asset pack %q: trees.json: sprite at column %d, row %d runs off the sheet", p.Name, s.Column, s.Row)
+				}
+				frames = append(frames, pixel.NewSprite(atlas, pixel.Rect{Min: min, Max: min.Add(pixel.V(meta.Cell, meta.Cell))}))
+			}
+			pivot := pixel.V(s.Pivot[0]-meta.Cell/2, meta.Cell/2-s.Pivot[1])
+			sprite := Sprite{Sprite: frames[0], Pivot: pivot}
+			if len(frames) > 1 {
+				sprite.Frames, sprite.FrameRate = frames, s.FrameRate
+			}
+			sprites[name] = append(sprites[name], sprite)
+		}
+	}
+	return sprites, nil
+}
@@ -0,0 +1,103 @@
+// Package camera is the maths behind the view onto a world of trees. Cameras
+// are plain values and every method returns a new camera rather than changing
+// the old one, so the conversions between screen and world can be relied on
+// by tools that need to know exactly what's under the mouse.
+//
+// Screen positions are in pixels and world positions in metres, with
+// trees.PixelsPerMetre pixels to the metre before any zoom is applied.
+package camera
+
+import (
+	"math"
+
+	"github.com/faiface/pixel"
+	"github.com/scottyw/falling-trees/trees"
+)
+
+// Camera is a view onto the world. Pos is where the world origin appears on
+// screen, and Zoom and Angle (in radians) are the scale and rotation applied
+// about that origin.
+type Camera struct {
+	Pos   pixel.Vec
+	Zoom  float64
+	Angle float64
+}
+
+// Matrix takes world pixels to screen pixels
+func (c Camera) Matrix() pixel.Matrix {
+	return pixel.IM.Scaled(pixel.ZV, c.Zoom).Rotated(pixel.ZV, c.Angle).Moved(c.Pos)
+}
+
+// Lerp moves t of the way from c to other. Zoom is interpolated geometrically
+// so that zooming in and out feel like they happen at the same speed.
+func (c Camera) Lerp(other Camera, t float64) Camera {
+	return Camera{
+		Pos:   pixel.Lerp(c.Pos, other.Pos, t),
+		Zoom:  c.Zoom * math.Pow(other.Zoom/c.Zoom, t),
+		Angle: c.Angle + (other.Angle-c.Angle)*t,
+	}
+}
+
+// ToWorld converts a position on screen into world coordinates in metres
+func (c Camera) ToWorld(screen pixel.Vec) pixel.Vec {
+	return c.Matrix().Unproject(screen).Scaled(1.0 / trees.PixelsPerMetre)
+}
+
+// ToScreen converts world coordinates in metres into a position on screen
+func (c Camera) ToScreen(world pixel.Vec) pixel.Vec {
+	return c.Matrix().Project(world.Scaled(trees.PixelsPerMetre))
+}
+
+// View is the smallest rectangle of the world in metres, lined up with its
+// axes, that covers everything shown on a screen rectangle in pixels, turned
+// or not
+func (c Camera) View(screen pixel.Rect) pixel.Rect {
+	corners := screen.Vertices()
+	view := pixel.Rect{Min: c.ToWorld(corners[0]), Max: c.ToWorld(corners[0])}
+	for _, corner := range corners[1:] {
+		p := c.ToWorld(corner)
+		view.Min = pixel.V(math.Min(view.Min.X, p.X), math.Min(view.Min.Y, p.Y))
+		view.Max = pixel.V(math.Max(view.Max.X, p.X), math.Max(view.Max.Y, p.Y))
+	}
+	return view
+}
+
+// ZoomAbout scales the zoom by a factor while keeping the world point under a
+// screen position fixed in place
+func (c Camera) ZoomAbout(screen pixel.Vec, factor float64) Camera {
+	c.Pos = screen.Sub(screen.Sub(c.Pos).Scaled(factor))
+	c.Zoom *= factor
+	return c
+}
+
+// ClampTo moves the camera as little as possible so that the world point
+// shown at a screen position lies inside bounds, given in metres
+func (c Camera) ClampTo(bounds pixel.Rect, screen pixel.Vec) Camera {
+	world := c.ToWorld(screen)
+	clamped := pixel.V(
+		pixel.Clamp(world.X, bounds.Min.X, bounds.Max.X),
+		pixel.Clamp(world.Y, bounds.Min.Y, bounds.Max.Y),
+	)
+	if clamped == world {
+		return c
+	}
+	c.Pos = c.Pos.Add(screen.Sub(c.ToScreen(clamped)))
+	return c
+}
+
+// Snapped adjusts the camera so that each texel of a sprite drawn texel world
+// pixels wide covers a whole number of screen pixels, or a whole fraction of
+// one when zoomed out, and the world origin sits on a pixel boundary. Pixel
+// art drawn through the snapped camera stays crisp with nearest sampling. The
+// zoom is snapped about a screen position, usually the centre of the view.
+func (c Camera) Snapped(texel float64, screen pixel.Vec) Camera {
+	scale := c.Zoom * texel
+	if scale >= 1 {
+		scale = math.Round(scale)
+	} else {
+		scale = 1 / math.Round(1/scale)
+	}
+	c = c.ZoomAbout(screen, scale/texel/c.Zoom)
+	c.Pos = pixel.V(math.Round(c.Pos.X), math.Round(c.Pos.Y))
+	return c
+}
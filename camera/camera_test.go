@@ -0,0 +1,176 @@
+package camera
+
+import (
+	"math"
+	"testing"
+
+	"github.com/faiface/pixel"
+)
+
+const epsilon = 1e-9
+
+func near(a, b pixel.Vec) bool {
+	return math.Abs(a.X-b.X) < epsilon && math.Abs(a.Y-b.Y) < epsilon
+}
+
+func TestToWorld(t *testing.T) {
+	tests := []struct {
+		name   string
+		cam    Camera
+		screen pixel.Vec
+		world  pixel.Vec
+	}{
+		{"origin", Camera{Zoom: 1}, pixel.ZV, pixel.ZV},
+		{"one metre", Camera{Zoom: 1}, pixel.V(32, 64), pixel.V(1, 2)},
+		{"moved", Camera{Pos: pixel.V(512, 0), Zoom: 1}, pixel.V(512, 32), pixel.V(0, 1)},
+		{"zoomed out", Camera{Pos: pixel.V(512, 0), Zoom: 0.5}, pixel.V(528, 0), pixel.V(1, 0)},
+		{"zoomed in", Camera{Zoom: 4}, pixel.V(128, -256), pixel.V(1, -2)},
+		{"quarter turn", Camera{Zoom: 1, Angle: math.Pi / 2}, pixel.V(0, 32), pixel.V(1, 0)},
+		{"everything", Camera{Pos: pixel.V(100, 50), Zoom: 2, Angle: math.Pi}, pixel.V(36, 50), pixel.V(1, 0)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.cam.ToWorld(test.screen); !near(got, test.world) {
+				t.Errorf("ToWorld(%v) = %v, want %v", test.screen, got, test.world)
+			}
+			if got := test.cam.ToScreen(test.world); !near(got, test.screen) {
+				t.Errorf("ToScreen(%v) = %v, want %v", test.world, got, test.screen)
+			}
+		})
+	}
+}
+
+func TestView(t *testing.T) {
+	screen := pixel.R(0, 0, 1024, 768)
+	tests := []struct {
+		name string
+		cam  Camera
+		view pixel.Rect
+	}{
+		{"origin at the corner", Camera{Zoom: 1}, pixel.R(0, 0, 32, 24)},
+		{"centred and zoomed in", Camera{Pos: pixel.V(512, 384), Zoom: 4}, pixel.R(-4, -3, 4, 3)},
+		{"quarter turn", Camera{Pos: pixel.V(512, 384), Zoom: 1, Angle: math.Pi / 2}, pixel.R(-12, -16, 12, 16)},
+		{"eighth turn", Camera{Pos: pixel.V(512, 384), Zoom: math.Sqrt2, Angle: math.Pi / 4}, pixel.R(-14, -14, 14, 14)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.cam.View(screen)
+			if !near(got.Min, test.view.Min) || !near(got.Max, test.view.Max) {
+				t.Errorf("View = %v, want %v", got, test.view)
+			}
+		})
+	}
+}
+
+func TestZoomAbout(t *testing.T) {
+	tests := []struct {
+		name   string
+		cam    Camera
+		screen pixel.Vec
+		factor float64
+		zoom   float64
+	}{
+		{"in at origin", Camera{Zoom: 1}, pixel.ZV, 2, 2},
+		{"in at cursor", Camera{Pos: pixel.V(512, 0), Zoom: 0.4}, pixel.V(300, 200), 1.2, 0.48},
+		{"out at cursor", Camera{Pos: pixel.V(512, 0), Zoom: 0.4}, pixel.V(300, 200), 1 / 1.2, 0.4 / 1.2},
+		{"rotated", Camera{Pos: pixel.V(10, 20), Zoom: 1, Angle: 0.7}, pixel.V(-40, 90), 3, 3},
+		{"no change", Camera{Pos: pixel.V(10, 20), Zoom: 1.5}, pixel.V(600, 400), 1, 1.5},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			before := test.cam.ToWorld(test.screen)
+			zoomed := test.cam.ZoomAbout(test.screen, test.factor)
+			if after := zoomed.ToWorld(test.screen); !near(before, after) {
+				t.Errorf("point under cursor moved from %v to %v", before, after)
+			}
+			if math.Abs(zoomed.Zoom-test.zoom) > epsilon {
+				t.Errorf("zoom = %v, want %v", zoomed.Zoom, test.zoom)
+			}
+			if zoomed.Angle != test.cam.Angle {
+				t.Errorf("angle changed from %v to %v", test.cam.Angle, zoomed.Angle)
+			}
+		})
+	}
+}
+
+func TestLerp(t *testing.T) {
+	from := Camera{Pos: pixel.V(0, 0), Zoom: 0.5, Angle: 0}
+	to := Camera{Pos: pixel.V(100, -50), Zoom: 2, Angle: 1}
+	tests := []struct {
+		t    float64
+		want Camera
+	}{
+		{0, from},
+		{1, to},
+		{0.5, Camera{Pos: pixel.V(50, -25), Zoom: 1, Angle: 0.5}},
+	}
+	for _, test := range tests {
+		got := from.Lerp(to, test.t)
+		if !near(got.Pos, test.want.Pos) || math.Abs(got.Zoom-test.want.Zoom) > epsilon || math.Abs(got.Angle-test.want.Angle) > epsilon {
+			t.Errorf("Lerp(%v) = %+v, want %+v", test.t, got, test.want)
+		}
+	}
+}
+
+func TestClampTo(t *testing.T) {
+	bounds := pixel.R(-10, -10, 10, 10)
+	centre := pixel.V(512, 384)
+	tests := []struct {
+		name  string
+		cam   Camera
+		world pixel.Vec // Where the centre of the screen should be afterwards
+	}{
+		{"inside", Camera{Pos: centre, Zoom: 1}, pixel.ZV},
+		{"off to the right", Camera{Pos: centre.Sub(pixel.V(20*32, 0)), Zoom: 1}, pixel.V(10, 0)},
+		{"off the bottom left", Camera{Pos: centre.Add(pixel.V(15*32, 30*32)), Zoom: 1}, pixel.V(-10, -10)},
+		{"zoomed and turned", Camera{Pos: centre.Sub(pixel.V(0, 40*32*2)), Zoom: 2, Angle: math.Pi / 2}, pixel.V(10, 0)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.cam.ClampTo(bounds, centre)
+			if world := got.ToWorld(centre); !near(world, test.world) {
+				t.Errorf("centre of screen shows %v, want %v", world, test.world)
+			}
+			if got.Zoom != test.cam.Zoom || got.Angle != test.cam.Angle {
+				t.Errorf("zoom or angle changed: %+v", got)
+			}
+		})
+	}
+}
+
+func TestSnapped(t *testing.T) {
+	centre := pixel.V(512, 384)
+	tests := []struct {
+		name  string
+		cam   Camera
+		texel float64
+		zoom  float64
+	}{
+		{"already whole", Camera{Pos: pixel.V(512, 0), Zoom: 1}, 2, 1},
+		{"rounds up", Camera{Pos: pixel.V(512, 0), Zoom: 0.4}, 2, 0.5},
+		{"rounds down", Camera{Pos: pixel.V(10.3, -4.6), Zoom: 1.2}, 2, 1},
+		{"zoomed out", Camera{Pos: pixel.V(512, 0), Zoom: 0.15}, 2, 1.0 / 6},
+		{"rotated", Camera{Pos: pixel.V(-3.5, 7.25), Zoom: 2.6, Angle: 0.3}, 1, 3},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.cam.Snapped(test.texel, centre)
+			if math.Abs(got.Zoom-test.zoom) > epsilon {
+				t.Errorf("zoom = %v, want %v", got.Zoom, test.zoom)
+			}
+			if got.Pos.X != math.Round(got.Pos.X) || got.Pos.Y != math.Round(got.Pos.Y) {
+				t.Errorf("origin at %v is off the pixel grid", got.Pos)
+			}
+			if got.Angle != test.cam.Angle {
+				t.Errorf("angle changed from %v to %v", test.cam.Angle, got.Angle)
+			}
+
+			// The view shouldn't jump, apart from the zoom and the rounding
+			before := test.cam.ToWorld(centre)
+			after := got.ToWorld(centre)
+			if d := before.To(after).Len() * 32 * got.Zoom; d > 1 {
+				t.Errorf("centre of screen moved %v pixels", d)
+			}
+		})
+	}
+}
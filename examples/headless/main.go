@@ -0,0 +1,30 @@
+// Headless runs the trees world without a window and reports how many trees
+// ended up resting on the mountain
+package main
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/scottyw/falling-trees/trees"
+)
+
+func main() {
+	opts := trees.DefaultOptions()
+	opts.Trees = 200
+	opts.Rand = rand.New(rand.NewSource(1))
+	world := trees.NewWorld(opts)
+
+	// Simulate 20 seconds at 60Hz
+	for i := 0; i < 20*60; i++ {
+		world.Step(1.0 / 60)
+	}
+
+	landed := 0
+	for _, tree := range world.Trees() {
+		if tree.GetPosition().Y > 0 {
+			landed++
+		}
+	}
+	fmt.Printf("%d of %d trees landed on the mountain\n", landed, len(world.Trees()))
+}
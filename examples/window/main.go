@@ -0,0 +1,45 @@
+// Window shows the trees world in a window with no sprites, adding a tree
+// wherever the mouse is clicked
+package main
+
+import (
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/pixelgl"
+	"github.com/scottyw/falling-trees/trees"
+	"golang.org/x/image/colornames"
+)
+
+func run() {
+	cfg := pixelgl.WindowConfig{
+		Title:  "Trees",
+		Bounds: pixel.R(0, 0, 800, 600),
+		VSync:  true,
+	}
+	win, err := pixelgl.NewWindow(cfg)
+	if err != nil {
+		panic(err)
+	}
+
+	opts := trees.DefaultOptions()
+	opts.Trees = 100
+	world := trees.NewWorld(opts)
+
+	// Fit the middle of the world into the window
+	cam := pixel.IM.Scaled(pixel.ZV, 0.25).Moved(pixel.V(400, 100))
+	win.SetMatrix(cam)
+
+	for !win.Closed() {
+		if win.JustPressed(pixelgl.MouseButtonLeft) {
+			pos := cam.Unproject(win.MousePosition()).Scaled(1.0 / trees.PixelsPerMetre)
+			world.SpawnTree(pos)
+		}
+		world.Step(1.0 / 60)
+		win.Clear(colornames.Whitesmoke)
+		world.Render(win)
+		win.Update()
+	}
+}
+
+func main() {
+	pixelgl.Run(run)
+}
@@ -0,0 +1,760 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"github.com/faiface/pixel/pixelgl"
+	"github.com/faiface/pixel/text"
+	"github.com/scottyw/falling-trees/camera"
+	"github.com/scottyw/falling-trees/trees"
+	"golang.org/x/image/colornames"
+	"golang.org/x/image/font/basicfont"
+)
+
+// app holds everything the demo needs from one frame to the next. Each frame
+// is made up of the systems registered with the scheduler, those of the
+// current screen first and then the ones every screen shares.
+type app struct {
+	title  string
+	win    *pixelgl.Window
+	world  *trees.World
+	sched  *scheduler
+	clock  *clock
+	screen screen
+	next   screen // Where to switch to once the frame is over
+	menu   *menuScreen
+
+	// What new worlds are built with, along with the trees to scatter if the
+	// number was given rather than left to each level
+	opts      trees.Options
+	seed      int64
+	treeCount *int
+
+	cam     camera.Camera
+	texel   float64 // World pixels per sprite pixel to snap the view to, or 0
+	marks   *bookmarks
+	pan     *panner
+	demo    *attract
+	pip     *pictureInPicture
+	track   *follower
+	repose  *reposeOverlay
+	density *densityOverlay
+	debug   *debugOverlay
+	outline *highlighter
+	landed  *landingHistogram
+	bins    *binOverlay
+	chain   *chainLabel
+	forces  *forceOverlay
+	mods    *modBrowser
+	prefs   *preferences
+	waves   *waveTable
+	tutor   *tutorial
+	energy  *energyMonitor
+	minimap *minimap
+	stats   *statsHUD
+	expose  *exposure
+	post    []*postStage
+	play    *player // Only on the replay screen, like rerun
+	rec     *recorder
+	clip    *capture // Only while capturing the window
+	clips   string   // Where Ctrl+R captures the window to
+	shots   string   // Where F12 saves screenshots, if not next to the executable
+	guest   *remote
+	host    *remote
+	sent    []command // Done here since input was last sent to the host
+	down    *shutdown
+	tools   []trees.Tool
+	tool    int
+	overlay *imdraw.IMDraw
+	pick    *pixel.Vec
+	scene   string // Where F11 saves a freeze frame of the world
+	save    string // Where Ctrl+S saves the world and Ctrl+L loads it from
+	saved   string // Where the preferences are saved, recent saves included
+	assets  string // Asset pack and season the trees are drawn with
+	season  string
+	watcher *assetWatcher // Only with -watch-assets
+
+	steps  int            // Fixed steps the world has taken
+	inputs *inputRecorder // Only when recording a replay
+	rerun  *rerun         // Only when re-simulating one
+
+	mouseTaken bool // The left mouse button is spoken for this frame, so it mustn't grab or pan
+
+	timings      *text.Text
+	showTimings  bool
+	solverLabel  *text.Text
+	gravityLabel *text.Text
+	clockLabel   *text.Text
+}
+
+// addSystems builds the frame out of the current screen's systems
+func (a *app) addSystems() {
+	a.sched = &scheduler{profiled: *pprofAddr != ""}
+	a.screen.systems(a)
+}
+
+// sharedSystems adds the systems of every screen but the menu, in the order
+// they run
+func (a *app) sharedSystems() {
+	a.sched.add(phaseInput, "clock", a.tick)
+	a.sched.add(phaseInput, "menu", a.backToMenu)
+	a.sched.add(phaseInput, "tutorial", a.followTutorial)
+	a.sched.add(phaseInput, "bookmarks", a.bookmarks)
+	a.sched.add(phaseInput, "zoom", a.zoom)
+	a.sched.add(phaseInput, "mods", a.browseMods)
+	a.sched.add(phaseInput, "spawn", a.spawnTree)
+	a.sched.add(phaseInput, "grab", a.grab)
+	a.sched.add(phaseInput, "pan", a.panning)
+	a.sched.add(phaseInput, "rotate", a.rotate)
+	a.sched.add(phaseInput, "pick", a.pickTree)
+	a.sched.add(phaseInput, "tools", a.useTools)
+	a.sched.add(phaseInput, "layers", a.toggleLayers)
+	a.sched.add(phaseInput, "waves", a.dropWaves)
+	a.sched.add(phaseInput, "scene", a.saveScene)
+	a.sched.add(phaseInput, "save", a.saveAndLoad)
+	a.sched.add(phaseInput, "remote", a.mirror)
+	a.sched.add(phaseInput, "follow", a.follow)
+	a.sched.add(phaseInput, "clamp", a.clamp)
+	a.sched.add(phaseScript, "attract", a.attract)
+	a.sched.add(phasePostPhysics, "record", a.record)
+	a.sched.add(phasePostPhysics, "animate", a.animate)
+	a.sched.add(phaseRender, "scene", a.drawScene)
+	a.sched.add(phaseRender, "tool", a.drawTool)
+	a.sched.add(phaseRender, "bins", a.drawBins)
+	a.sched.add(phaseRender, "density", a.drawDensity)
+	a.sched.add(phaseRender, "repose", a.drawRepose)
+	a.sched.add(phaseRender, "forces", a.drawForces)
+	a.sched.add(phaseRender, "debug", a.drawDebug)
+	a.sched.add(phaseRender, "highlight", a.drawHighlights)
+	a.sched.add(phaseRender, "pip", a.drawPip)
+	a.sched.add(phaseRender, "post", a.postProcess)
+	a.sched.add(phaseUI, "landings", a.drawLandings)
+	a.sched.add(phaseUI, "waves", a.drawWaves)
+	a.sched.add(phaseUI, "dominoes", a.drawChain)
+	a.sched.add(phaseUI, "mods", a.drawMods)
+	a.sched.add(phaseUI, "timings", a.drawTimings)
+	a.sched.add(phaseUI, "solver", a.solver)
+	a.sched.add(phaseUI, "gravity", a.gravity)
+	a.sched.add(phaseUI, "energy", a.drawEnergy)
+	a.sched.add(phaseUI, "minimap", a.drawMinimap)
+	a.sched.add(phaseUI, "stats", a.drawStats)
+	a.sched.add(phaseUI, "time", a.drawClock)
+	a.sched.add(phaseUI, "tutorial", a.drawTutorial)
+	a.sched.add(phaseUI, "assets", a.reloadAssets)
+	a.sched.add(phaseUI, "exposure", a.adjustExposure)
+	a.sched.add(phaseUI, "capture", a.capture)
+	a.sched.add(phaseUI, "screenshot", a.screenshot)
+}
+
+// Move simulated time on before anything uses it. Space pauses, period takes
+// a single physics step and minus and equals slow time down and speed it up,
+// unless a recording is playing, which has its own controls.
+func (a *app) tick(dt time.Duration) {
+	if a.play == nil {
+		switch {
+		case a.win.JustPressed(pixelgl.KeySpace):
+			a.clock.paused = !a.clock.paused
+		case a.win.JustPressed(pixelgl.KeyPeriod) || a.win.Repeated(pixelgl.KeyPeriod):
+			a.clock.stepOnce()
+		case a.win.JustPressed(pixelgl.KeyMinus):
+			a.clock.slower()
+		case a.win.JustPressed(pixelgl.KeyEqual):
+			a.clock.faster()
+		}
+	}
+	a.clock.tick(dt)
+}
+
+// Start the tutorial over with F1
+func (a *app) followTutorial(dt time.Duration) {
+	a.tutor.update(a.win, dt.Seconds())
+}
+
+// Save and recall camera bookmarks
+func (a *app) bookmarks(dt time.Duration) {
+	a.marks.update(a.win, &a.cam, dt.Seconds())
+}
+
+// Check the mouse wheel to zoom in or out on whatever is under the cursor
+func (a *app) zoom(dt time.Duration) {
+	if scroll := a.win.MouseScroll().Y; scroll != 0 {
+		a.cam = a.cam.ZoomAbout(a.win.MousePosition(), math.Pow(camZoomSpeed, scroll))
+		a.marks.cancel()
+		a.world.Events().Publish(viewZoomed{})
+	}
+}
+
+// Switch mods on and off with F9, which takes over the mouse while it's open
+func (a *app) browseMods(dt time.Duration) {
+	a.mouseTaken = a.mods.update(a.win)
+}
+
+// Shift-click to drop a tree under the cursor, whichever tool is chosen
+func (a *app) spawnTree(dt time.Duration) {
+	shift := a.win.Pressed(pixelgl.KeyLeftShift) || a.win.Pressed(pixelgl.KeyRightShift)
+	if a.mouseTaken || !shift || !a.win.JustPressed(pixelgl.MouseButtonLeft) || !a.live() {
+		return
+	}
+	at := a.view().ToWorld(a.win.MousePosition())
+	if a.do(command{Do: "spawn", X: at.X, Y: at.Y}) {
+		a.world.Events().Publish(treeDropped{})
+	}
+	a.mouseTaken = true
+}
+
+// Drag whatever is under the cursor with the left mouse button, while the
+// physics is running
+func (a *app) grab(dt time.Duration) {
+	if a.mouseTaken {
+		return
+	}
+	mouse := a.view().ToWorld(a.win.MousePosition())
+	switch {
+	case a.win.JustPressed(pixelgl.MouseButtonLeft):
+		if a.live() {
+			a.do(command{Do: "grab", X: mouse.X, Y: mouse.Y})
+		}
+	case a.win.Pressed(pixelgl.MouseButtonLeft) && a.world.Grabbed() != nil:
+		a.do(command{Do: "move", X: mouse.X, Y: mouse.Y})
+	case a.win.JustReleased(pixelgl.MouseButtonLeft) && a.world.Grabbed() != nil:
+		a.do(command{Do: "drop"})
+	}
+}
+
+// Drag the view around with the left mouse button, unless something in the
+// world is being dragged instead
+func (a *app) panning(dt time.Duration) {
+	if a.mouseTaken || a.world.Grabbed() != nil {
+		return
+	}
+	scrubbing := a.play != nil && a.play.scrubbing
+	if !scrubbing && a.pan.update(a.win, &a.cam, dt.Seconds()) {
+		a.marks.cancel()
+	}
+	if !a.pan.dragging && !a.demo.active && edgePan(a.win, &a.cam, *edgePanSpeed, *edgePanMargin, dt.Seconds()) {
+		a.marks.cancel()
+	}
+	if !a.demo.active && keyPan(a.win, &a.cam, *keyPanSpeed, dt.Seconds()) {
+		a.marks.cancel()
+		a.pan.stop()
+		a.track.target = nil
+	}
+	if a.marks.moving {
+		a.pan.stop()
+	}
+}
+
+// Rotate the view with Q and E, and straighten it up again with R
+func (a *app) rotate(dt time.Duration) {
+	if a.win.Pressed(pixelgl.KeyQ) {
+		a.cam.Angle += camRotateSpeed * dt.Seconds()
+		a.marks.cancel()
+	}
+	if a.win.Pressed(pixelgl.KeyE) {
+		a.cam.Angle -= camRotateSpeed * dt.Seconds()
+		a.marks.cancel()
+	}
+	if a.win.JustPressed(pixelgl.KeyR) && !ctrlPressed(a.win) {
+		a.cam.Angle = 0
+		a.marks.cancel()
+	}
+}
+
+// Pick or drop the tree followed by the picture-in-picture view
+func (a *app) pickTree(dt time.Duration) {
+	a.pick = nil
+	if a.win.JustPressed(pixelgl.KeyP) {
+		point := a.view().ToWorld(a.win.MousePosition())
+		a.pick = &point
+		a.pip.toggle(a.world, point)
+	}
+}
+
+// Cycle through the tools with T and use the current one with the right mouse button
+func (a *app) useTools(dt time.Duration) {
+	if a.win.JustPressed(pixelgl.KeyT) {
+		a.chooseTool((a.tool + 1) % len(a.tools))
+	}
+	if !a.live() {
+		return
+	}
+	mouse := a.view().ToWorld(a.win.MousePosition())
+	use := command{Name: a.tools[a.tool].Name(), X: mouse.X, Y: mouse.Y}
+	switch {
+	case a.win.JustPressed(pixelgl.MouseButtonRight):
+		use.Do = "press"
+	case a.win.Pressed(pixelgl.MouseButtonRight):
+		use.Do = "drag"
+	case a.win.JustReleased(pixelgl.MouseButtonRight):
+		use.Do = "release"
+	default:
+		return
+	}
+	a.do(use)
+}
+
+// chooseTool makes a tool the one the right mouse button uses
+func (a *app) chooseTool(i int) {
+	a.tool = i
+	a.win.SetTitle(a.title + " | " + a.tools[a.tool].Name())
+	a.world.Events().Publish(toolChosen{Name: a.tools[a.tool].Name()})
+}
+
+// Tint everything by collision layer with L
+func (a *app) toggleLayers(dt time.Duration) {
+	if a.win.JustPressed(pixelgl.KeyL) && !ctrlPressed(a.win) {
+		a.world.ShowLayers(!a.world.ShowingLayers())
+	}
+}
+
+// Drop a wave of trees with G and colour the trees by wave with F2
+func (a *app) dropWaves(dt time.Duration) {
+	if a.waves.update(a.win, a.world) && a.live() {
+		a.do(command{Do: "wave", N: float64(a.waves.size)})
+	}
+}
+
+// Freeze the world into a level file with F11
+func (a *app) saveScene(dt time.Duration) {
+	if a.win.JustPressed(pixelgl.KeyF11) && a.play == nil {
+		if err := saveScene(a.world, a.scene); err != nil {
+			log.Printf("can't save the scene to %s: %v", a.scene, err)
+			return
+		}
+	}
+}
+
+// Save the world with Ctrl+S and load it again with Ctrl+L
+func (a *app) saveAndLoad(dt time.Duration) {
+	if !ctrlPressed(a.win) || a.play != nil {
+		return
+	}
+	switch {
+	case a.win.JustPressed(pixelgl.KeyS):
+		if err := saveWorld(a.world, a.save, a.assets, a.season); err != nil {
+			log.Printf("can't save to %s: %v", a.save, err)
+			return
+		}
+		a.prefs.remember(a.save)
+		if err := a.prefs.save(a.saved); err != nil {
+			log.Printf("can't remember %s as a recent save: %v", a.save, err)
+		}
+	case a.win.JustPressed(pixelgl.KeyL) && a.inputs == nil && a.rerun == nil:
+		saved, err := loadSave(a.save)
+		if err == nil {
+			err = saved.restore(a.world)
+		}
+		if err != nil {
+			// It might be a save of another level, so carry on regardless
+			log.Printf("can't load %s: %v", a.save, err)
+		}
+	}
+}
+
+// Mirror a guest's input here, or our input onto the host
+func (a *app) mirror(dt time.Duration) {
+	if a.guest != nil {
+		for _, in := range a.guest.receive() {
+			a.cam = in.camera()
+			a.marks.cancel()
+			a.pan.stop()
+			if in.Pick != nil {
+				a.pip.toggle(a.world, *in.Pick)
+			}
+			for i, t := range a.tools {
+				if t.Name() == in.Tool && i != a.tool {
+					a.chooseTool(i)
+				}
+			}
+			for _, c := range in.Commands {
+				a.do(c)
+			}
+		}
+	}
+	if a.host != nil {
+		in := remoteInput{
+			CamX:     a.cam.Pos.X,
+			CamY:     a.cam.Pos.Y,
+			Zoom:     a.cam.Zoom,
+			Angle:    a.cam.Angle,
+			Pick:     a.pick,
+			Tool:     a.tools[a.tool].Name(),
+			Commands: a.sent,
+		}
+		a.sent = nil
+		if !a.host.send(in) {
+			log.Printf("pairing: no longer mirroring onto the host")
+			a.host = nil
+		}
+	}
+}
+
+// Follow the tree nearest the cursor with F, until F is pressed again or the
+// view is moved some other way
+func (a *app) follow(dt time.Duration) {
+	if a.win.JustPressed(pixelgl.KeyF) {
+		a.track.toggle(a.world, a.view().ToWorld(a.win.MousePosition()))
+		a.marks.cancel()
+		a.pan.stop()
+	}
+	if a.pan.dragging || a.marks.moving || a.demo.active {
+		a.track.target = nil
+	}
+	a.track.update(&a.cam, a.world, a.win.Bounds().Center(), dt.Seconds())
+}
+
+// Keep the view inside the world once everything else has moved it
+func (a *app) clamp(dt time.Duration) {
+	if *clampCamera {
+		a.cam = a.cam.ClampTo(trees.Bounds, a.win.Bounds().Center())
+	}
+}
+
+// Tour the world by itself if nobody is at the controls
+func (a *app) attract(dt time.Duration) {
+	wasAttracting := a.demo.active
+	attracting := a.demo.update(a.win, &a.cam, a.marks, a.world, a.live(), a.do, dt.Seconds())
+	if attracting != wasAttracting {
+		name := a.screen.name()
+		if attracting {
+			name = "attract"
+		}
+		a.world.Events().Publish(trees.ModeChanged{Mode: name})
+	}
+}
+
+// Record where every tree is this frame. If the recording can't be written
+// any more, recording stops and the world carries on without it.
+func (a *app) record(dt time.Duration) {
+	if a.rec == nil {
+		return
+	}
+	if err := a.rec.record(a.world.Trees(), a.clock.dt); err != nil {
+		log.Printf("stopped recording: %v", err)
+		a.rec.close()
+		a.rec = nil
+	}
+}
+
+// live reports whether the user is in charge of the world, rather than it
+// playing back a recording or re-simulating a replay
+func (a *app) live() bool {
+	return a.play == nil && a.rerun == nil
+}
+
+// do does something to the world on the user's behalf, recording it for the
+// replay if there is one. It reports whether the screen allowed it and it
+// could be done. If the replay can't be written any more, recording stops and
+// play carries on without it.
+func (a *app) do(c command) bool {
+	if !a.screen.allow(c) {
+		return false
+	}
+	c.Step = a.steps
+	if err := c.apply(a.world, a.tools); err != nil {
+		log.Printf("can't %s: %v", c.Do, err)
+		return false
+	}
+	if a.host != nil {
+		a.sent = append(a.sent, c)
+	}
+	if a.inputs != nil {
+		if err := a.inputs.record(c); err != nil {
+			log.Printf("stopped recording the replay: %v", err)
+			a.inputs.close()
+			a.inputs = nil
+		}
+	}
+	return true
+}
+
+// view is the camera the world is drawn through. It's the same as the camera
+// unless pixel snapping is on, in which case the zoom and position are nudged
+// so the sprites land on whole screen pixels.
+func (a *app) view() camera.Camera {
+	if a.texel <= 0 {
+		return a.cam
+	}
+	return a.cam.Snapped(a.texel, a.win.Bounds().Center())
+}
+
+// Draw the world and trees
+func (a *app) drawScene(dt time.Duration) {
+	a.win.SetMatrix(a.view().Matrix())
+	a.win.Clear(colornames.Whitesmoke)
+	a.world.SetView(a.view().View(a.win.Bounds()))
+	a.world.Render(a.win)
+	a.stats.drawn = a.world.Drawn() // Before the picture in picture draws some more
+
+}
+
+// Draw whatever the current tool shows while it's in use
+func (a *app) drawTool(dt time.Duration) {
+	overlay, ok := a.tools[a.tool].(trees.ToolOverlay)
+	if !ok {
+		return
+	}
+	if a.overlay == nil {
+		a.overlay = imdraw.New(nil)
+	}
+	a.overlay.Clear()
+	overlay.DrawOverlay(a.overlay)
+	a.overlay.Draw(a.win)
+}
+
+// Count the trees in each bin on levels that have them
+func (a *app) drawBins(dt time.Duration) {
+	a.bins.draw(a.win, a.view(), a.world)
+}
+
+// Show where piles are forming with F7
+func (a *app) drawDensity(dt time.Duration) {
+	a.density.draw(a.win, a.view(), a.world)
+}
+
+// Measure the angle of repose of the pile with F4
+func (a *app) drawRepose(dt time.Duration) {
+	a.repose.draw(a.win, a.view(), a.world)
+}
+
+// Outline every body as the physics sees it with B
+func (a *app) drawDebug(dt time.Duration) {
+	a.debug.draw(a.win, a.view(), a.world)
+}
+
+// Outline whatever is under the cursor, and the trees followed by the view
+// and the picture in picture and held with the mouse
+func (a *app) drawHighlights(dt time.Duration) {
+	selected := []*trees.Entity{a.world.Grabbed(), a.track.target}
+	if e, ok := a.world.Lookup(a.pip.target); ok {
+		selected = append(selected, e)
+	}
+	a.outline.draw(a.win, a.view(), a.world, selected)
+}
+
+// Label the forces on a body with F5
+func (a *app) drawForces(dt time.Duration) {
+	a.forces.draw(a.win, a.view(), a.world, a.pip.target)
+}
+
+// Draw the tracked view over the top of everything else
+func (a *app) drawPip(dt time.Duration) {
+	a.pip.draw(a.win, a.world)
+}
+
+// Show where the trees came to rest with F8
+func (a *app) drawLandings(dt time.Duration) {
+	a.landed.draw(a.win, a.world)
+}
+
+// Play the trees' animations along with simulated time, so they hold still
+// while it's paused
+func (a *app) animate(dt time.Duration) {
+	a.world.Animate(a.clock.dt.Seconds())
+}
+
+// Graph the energy of the world with N
+func (a *app) drawEnergy(dt time.Duration) {
+	a.energy.draw(a.win)
+}
+
+// Show the whole world in the corner with M
+func (a *app) drawMinimap(dt time.Duration) {
+	a.minimap.draw(a.win, a.view(), a.world)
+}
+
+// Draw the world and everything over it through the post-processing effects
+// chosen in the config, leaving the text drawn after it crisp
+func (a *app) postProcess(dt time.Duration) {
+	postProcess(a.win, a.post)
+}
+
+// Adjust the exposure, brightness and contrast of the finished frame with the
+// brackets, before it's captured
+func (a *app) adjustExposure(dt time.Duration) {
+	a.expose.draw(a.win, dt)
+}
+
+// Show the frame rate and what the physics is up to with H
+func (a *app) drawStats(dt time.Duration) {
+	var physics time.Duration
+	for _, sys := range a.sched.systems {
+		if sys.phase == phasePhysics {
+			physics += sys.took
+		}
+	}
+	a.stats.draw(a.win, dt, physics, a.world, a.view(), a.pip.target != nil)
+}
+
+func (a *app) drawWaves(dt time.Duration) {
+	a.waves.draw(a.win, a.world)
+}
+
+func (a *app) drawTutorial(dt time.Duration) {
+	a.tutor.draw(a.win)
+}
+
+func (a *app) drawMods(dt time.Duration) {
+	a.mods.draw(a.win)
+}
+
+// Keep score of the dominoes on levels that have them
+func (a *app) drawChain(dt time.Duration) {
+	a.chain.draw(a.win, a.world)
+}
+
+// Show how long each system takes with F3
+func (a *app) drawTimings(dt time.Duration) {
+	if a.win.JustPressed(pixelgl.KeyF3) {
+		a.showTimings = !a.showTimings
+	}
+	if !a.showTimings {
+		return
+	}
+	if a.timings == nil {
+		a.timings = text.New(pixel.ZV, text.NewAtlas(basicfont.Face7x13, text.ASCII))
+		a.timings.Color = colornames.Black
+	}
+	a.timings.Clear()
+	for _, sys := range a.sched.systems {
+		fmt.Fprintf(a.timings, "%-12s %-10s %6.3fms\n", sys.phase, sys.name, float64(sys.took)/float64(time.Millisecond))
+	}
+	a.win.SetMatrix(pixel.IM)
+	topLeft := pixel.V(a.win.Bounds().Min.X+8, a.win.Bounds().Max.Y-8-a.timings.LineHeight)
+	a.timings.Draw(a.win, pixel.IM.Moved(topLeft))
+}
+
+// Start and stop capturing the window with Ctrl+R, and capture the finished
+// frame while capturing, so this runs after all the drawing. A capture that
+// can't be started or written, say without ffmpeg, is logged and dropped.
+func (a *app) capture(dt time.Duration) {
+	if ctrlPressed(a.win) && a.win.JustPressed(pixelgl.KeyR) {
+		if a.clip != nil {
+			if err := a.stopCapture(); err != nil {
+				log.Printf("can't finish the capture: %v", err)
+			}
+			return
+		}
+		clip, err := newCapture(nextCapture(a.clips))
+		if err != nil {
+			log.Printf("can't capture: %v", err)
+			return
+		}
+		a.clip = clip
+		log.Printf("capturing to %s, Ctrl+R to stop", clip.path)
+	}
+	if a.clip != nil {
+		if err := a.clip.grab(a.win, dt); err != nil {
+			log.Printf("stopped capturing to %s: %v", a.clip.path, err)
+			a.clip.close()
+			a.clip = nil
+		}
+	}
+}
+
+// stopCapture finishes the capture, if there is one
+func (a *app) stopCapture() error {
+	if a.clip == nil {
+		return nil
+	}
+	clip := a.clip
+	a.clip = nil
+	if err := clip.close(); err != nil {
+		return err
+	}
+	log.Printf("saved capture to %s", clip.path)
+	return nil
+}
+
+// Cycle through the solver profiles with F6, showing the current one in the
+// bottom right corner
+func (a *app) solver(dt time.Duration) {
+	if a.win.JustPressed(pixelgl.KeyF6) && a.live() {
+		profiles := trees.SolverProfiles()
+		next := profiles[0]
+		for i, p := range profiles {
+			if p.Name == a.world.Solver().Name {
+				next = profiles[(i+1)%len(profiles)]
+			}
+		}
+		if a.do(command{Do: "solver", Name: next.Name}) {
+			a.world.Events().Publish(solverChanged{Name: next.Name})
+		}
+	}
+	if a.solverLabel == nil {
+		a.solverLabel = text.New(pixel.ZV, text.NewAtlas(basicfont.Face7x13, text.ASCII))
+		a.solverLabel.Color = colornames.Black
+	}
+	p := a.world.Solver()
+	a.solverLabel.Clear()
+	fmt.Fprintf(a.solverLabel, "solver %s (%d/%d x%d)", p.Name, p.VelocityIterations, p.PositionIterations, p.SubSteps)
+	bottom := a.win.Bounds().Min.Y + 8
+	if a.play != nil {
+		// Keep clear of the timeline
+		bottom += 36
+	}
+	a.win.SetMatrix(pixel.IM)
+	bottomRight := pixel.V(a.win.Bounds().Max.X-8-a.solverLabel.Bounds().W(), bottom+a.solverLabel.Atlas().Descent())
+	a.solverLabel.Draw(a.win, pixel.IM.Moved(bottomRight))
+}
+
+// Switch between the gravity presets with F10, shown above the solver profile
+func (a *app) gravity(dt time.Duration) {
+	presets := trees.GravityPresets()
+	current, ok := a.world.GravityPreset()
+	if a.win.JustPressed(pixelgl.KeyF10) && a.live() {
+		next := presets[0]
+		for i, p := range presets {
+			if ok && p.Name == current.Name {
+				next = presets[(i+1)%len(presets)]
+			}
+		}
+		if a.do(command{Do: "gravity", Name: next.Name}) {
+			current, ok = next, true
+		}
+	}
+	if a.gravityLabel == nil {
+		a.gravityLabel = text.New(pixel.ZV, text.NewAtlas(basicfont.Face7x13, text.ASCII))
+		a.gravityLabel.Color = colornames.Black
+	}
+	a.gravityLabel.Clear()
+	if ok {
+		fmt.Fprintf(a.gravityLabel, "gravity %s (%.2f m/s/s)", current.Name, current.Gravity.Len())
+	} else {
+		fmt.Fprint(a.gravityLabel, "gravity custom")
+	}
+	bottom := a.win.Bounds().Min.Y + 8 + a.gravityLabel.LineHeight
+	if a.play != nil {
+		bottom += 36
+	}
+	a.win.SetMatrix(pixel.IM)
+	bottomRight := pixel.V(a.win.Bounds().Max.X-8-a.gravityLabel.Bounds().W(), bottom+a.gravityLabel.Atlas().Descent())
+	a.gravityLabel.Draw(a.win, pixel.IM.Moved(bottomRight))
+}
+
+// Show how fast time is running above the gravity, unless it's running as
+// usual
+func (a *app) drawClock(dt time.Duration) {
+	if a.play != nil || (!a.clock.paused && a.clock.scale == 1) {
+		return
+	}
+	if a.clockLabel == nil {
+		a.clockLabel = text.New(pixel.ZV, text.NewAtlas(basicfont.Face7x13, text.ASCII))
+		a.clockLabel.Color = colornames.Black
+	}
+	a.clockLabel.Clear()
+	if a.clock.paused {
+		fmt.Fprint(a.clockLabel, "paused, period to step")
+	} else {
+		fmt.Fprintf(a.clockLabel, "time %gx", a.clock.scale)
+	}
+	bottom := a.win.Bounds().Min.Y + 8 + 2*a.clockLabel.LineHeight
+	a.win.SetMatrix(pixel.IM)
+	bottomRight := pixel.V(a.win.Bounds().Max.X-8-a.clockLabel.Bounds().W(), bottom+a.clockLabel.Atlas().Descent())
+	a.clockLabel.Draw(a.win, pixel.IM.Moved(bottomRight))
+}
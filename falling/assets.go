@@ -0,0 +1,36 @@
+package main
+
+import (
+	"embed"
+	"path/filepath"
+
+	"github.com/scottyw/falling-trees/assets"
+)
+
+// defaultPack is the pack of assets that comes with the demo, built into it
+// from the files alongside its code. Other packs live in directories of their
+// own under packsDir.
+const (
+	defaultPack = "default"
+	packsDir    = "falling/packs"
+)
+
+//go:embed manifest.json trees.json trees.png
+var builtinPack embed.FS
+
+// packs are asset packs that came with mods
+var packs = map[string]*assets.Pack{}
+
+// loadPack finds an asset pack by name and reads its manifest
+func loadPack(name string) (*assets.Pack, error) {
+	if p, ok := packs[name]; ok {
+		return p, nil
+	}
+	if name != defaultPack {
+		return assets.Open(name, assets.Dir(filepath.Join(packsDir, name)))
+	}
+	if *defaultAssets != "" {
+		return assets.Open(name, assets.Dir(*defaultAssets))
+	}
+	return assets.Open(name, assets.FS{FS: builtinPack})
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"math/rand"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/pixelgl"
+	"github.com/scottyw/falling-trees/camera"
+	"github.com/scottyw/falling-trees/trees"
+)
+
+const (
+	// Seconds the attract mode lingers on each view before moving on
+	attractInterval = 8.0
+
+	// Radius in metres and impulse of the explosions it sets off
+	attractBlastRadius  = 12
+	attractBlastImpulse = 60
+)
+
+// Views toured by the attract mode when no bookmarks have been saved
+var attractViews = []camera.Camera{
+	{Pos: pixel.V(512, 120), Zoom: 0.4},
+	{Pos: pixel.V(512, 60), Zoom: 1.2},
+	{Pos: pixel.V(200, 200), Zoom: 0.8, Angle: 0.2},
+	{Pos: pixel.V(800, 200), Zoom: 0.8, Angle: -0.2},
+}
+
+// attract takes over once nobody has touched the controls for a while,
+// touring the bookmarked views and livening things up with gusts of wind and
+// explosions. Any input hands control straight back.
+type attract struct {
+	idleAfter float64
+	idle      float64
+	active    bool
+	timer     float64
+	next      int
+}
+
+// update reports whether attract mode is running this frame. It only stirs
+// up the world if it's live, doing so through do.
+func (a *attract) update(win *pixelgl.Window, cam *camera.Camera, marks *bookmarks, world *trees.World, live bool, do func(command) bool, dt float64) bool {
+	if a.idleAfter <= 0 {
+		return false
+	}
+	if userInput(win) {
+		a.idle = 0
+		if a.active {
+			a.active = false
+			marks.cancel()
+		}
+		return false
+	}
+
+	a.idle += dt
+	if !a.active {
+		if a.idle < a.idleAfter {
+			return false
+		}
+		// Start the tour straight away
+		a.active = true
+		a.timer = attractInterval
+	}
+
+	a.timer += dt
+	if a.timer >= attractInterval {
+		a.timer = 0
+		a.show(cam, marks, world, live, do)
+	}
+	return true
+}
+
+// show glides to the next view in the tour and sets off an event
+func (a *attract) show(cam *camera.Camera, marks *bookmarks, world *trees.World, live bool, do func(command) bool) {
+	var views []camera.Camera
+	for _, saved := range marks.saved {
+		if saved != nil {
+			views = append(views, *saved)
+		}
+	}
+	if len(views) == 0 {
+		views = attractViews
+	}
+	// Follow a smooth path through all the views rather than cutting straight
+	// across from one to the next
+	n := len(views)
+	i := a.next % n
+	marks.glideThrough(*cam, views[i], views[(i+n-2)%n].Pos, views[(i+1)%n].Pos)
+	a.next++
+
+	forest := world.Trees()
+	if len(forest) == 0 || !live {
+		return
+	}
+	if rand.Intn(2) == 0 {
+		strength := 6.0
+		if rand.Intn(2) == 0 {
+			strength = -strength
+		}
+		do(command{Do: "gust", N: strength})
+	} else {
+		// Blow up around a random tree since that's usually somewhere in the pile
+		p := forest[rand.Intn(len(forest))].GetPosition()
+		do(command{Do: "explode", X: p.X, Y: p.Y})
+	}
+}
+
+// userInput reports whether the mouse moved or any key or button was pressed
+func userInput(win *pixelgl.Window) bool {
+	if win.MousePosition() != win.MousePreviousPosition() || win.MouseScroll() != pixel.ZV {
+		return true
+	}
+	for b := pixelgl.Button(0); b <= pixelgl.KeyLast; b++ {
+		if win.Pressed(b) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"github.com/faiface/pixel/pixelgl"
+	"github.com/faiface/pixel/text"
+	"github.com/scottyw/falling-trees/camera"
+	"github.com/scottyw/falling-trees/trees"
+	"golang.org/x/image/colornames"
+	"golang.org/x/image/font/basicfont"
+)
+
+// Fraction of a bin that trees piled up in it fill, for turning a count of
+// trees into the height of the pile
+const binPacking = 0.75
+
+// binOverlay labels every bin of a level like the Galton board with the trees
+// in it, and draws the normal curve that best fits the counts over the piles
+type binOverlay struct {
+	curve  *imdraw.IMDraw
+	labels *text.Text
+}
+
+func newBinOverlay() *binOverlay {
+	labels := text.New(pixel.ZV, text.NewAtlas(basicfont.Face7x13, text.ASCII))
+	labels.Color = colornames.Black
+	return &binOverlay{curve: imdraw.New(nil), labels: labels}
+}
+
+// draw shows the overlay over the scene drawn with the camera's matrix, for
+// levels that have bins
+func (b *binOverlay) draw(win *pixelgl.Window, cam camera.Camera, world *trees.World) {
+	bins := world.Level().Bins
+	if len(bins) == 0 {
+		return
+	}
+	counts := world.BinCounts()
+	var total, sum, squares float64
+	for i, n := range counts {
+		x := bins[i].Center().X
+		total += float64(n)
+		sum += float64(n) * x
+		squares += float64(n) * x * x
+	}
+
+	// How high a pile of trees would stand in each bin if the counts followed
+	// the fitted curve exactly
+	b.curve.Clear()
+	if total > 1 {
+		mean := sum / total
+		sd := math.Sqrt(math.Max(squares/total-mean*mean, 0))
+		if sd > 0 {
+			b.curve.Color = colornames.Firebrick
+			from, to := bins[0].Min.X, bins[len(bins)-1].Max.X
+			for x := from; x <= to; x += 0.5 {
+				fraction := bins[0].W() * math.Exp(-(x-mean)*(x-mean)/(2*sd*sd)) / (sd * math.Sqrt(2*math.Pi))
+				b.curve.Push(pixel.V(x, bins[0].Min.Y+pileHeight(total*fraction, bins[0].W())).Scaled(trees.PixelsPerMetre))
+			}
+			b.curve.Line(4)
+		}
+	}
+	win.SetMatrix(cam.Matrix())
+	b.curve.Draw(win)
+
+	// Label each bin in screen space so the counts stay readable at any zoom
+	win.SetMatrix(pixel.IM)
+	for i, n := range counts {
+		b.labels.Clear()
+		fmt.Fprintf(b.labels, "%d", n)
+		at := cam.ToScreen(pixel.V(bins[i].Center().X, bins[i].Min.Y)).Sub(pixel.V(b.labels.Bounds().W()/2, 16))
+		b.labels.Draw(win, pixel.IM.Moved(at))
+	}
+	win.SetMatrix(cam.Matrix())
+}
+
+// pileHeight is how high in metres a number of trees piles up in a bin of the
+// given width
+func pileHeight(count, width float64) float64 {
+	return count * math.Pi / (width * binPacking)
+}
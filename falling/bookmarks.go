@@ -0,0 +1,91 @@
+package main
+
+import (
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/pixelgl"
+	"github.com/scottyw/falling-trees/camera"
+	"github.com/scottyw/falling-trees/spline"
+)
+
+// How long it takes the camera to glide to a recalled bookmark, in seconds
+const bookmarkTransition = 0.6
+
+var bookmarkKeys = [...]pixelgl.Button{
+	pixelgl.Key1, pixelgl.Key2, pixelgl.Key3,
+	pixelgl.Key4, pixelgl.Key5, pixelgl.Key6,
+	pixelgl.Key7, pixelgl.Key8, pixelgl.Key9,
+}
+
+// bookmarks holds saved camera views and animates the camera between them.
+// Ctrl and a number key saves the current view, the number key alone recalls it.
+type bookmarks struct {
+	saved   [len(bookmarkKeys)]*camera.Camera
+	from    camera.Camera
+	to      camera.Camera
+	elapsed float64
+	moving  bool
+
+	// Positions before and after the move, which bend it into a Catmull-Rom
+	// curve when it's one leg of a longer camera path
+	before, after pixel.Vec
+}
+
+func (b *bookmarks) update(win *pixelgl.Window, cam *camera.Camera, dt float64) {
+	ctrl := ctrlPressed(win)
+	for i, key := range bookmarkKeys {
+		if !win.JustPressed(key) {
+			continue
+		}
+		if ctrl {
+			saved := *cam
+			b.saved[i] = &saved
+		} else if b.saved[i] != nil {
+			b.glideTo(*cam, *b.saved[i])
+		}
+	}
+
+	if !b.moving {
+		return
+	}
+	b.elapsed += dt
+	t := b.elapsed / bookmarkTransition
+	if t >= 1 {
+		*cam = b.to
+		b.moving = false
+		return
+	}
+	// Smoothstep so the camera eases in and out of the move
+	t = t * t * (3 - 2*t)
+	*cam = b.from.Lerp(b.to, t)
+	cam.Pos = spline.Point(b.before, b.from.Pos, b.to.Pos, b.after, t)
+}
+
+// glideTo starts moving the camera from one view to another in a straight
+// line, which is where the curve goes with evenly spaced points either side
+func (b *bookmarks) glideTo(from, to camera.Camera) {
+	step := to.Pos.Sub(from.Pos)
+	b.glideThrough(from, to, from.Pos.Sub(step), to.Pos.Add(step))
+}
+
+// glideThrough starts moving the camera from one view to another along the
+// curve of a camera path, with before and after the positions on the path
+// either side
+func (b *bookmarks) glideThrough(from, to camera.Camera, before, after pixel.Vec) {
+	b.from = from
+	b.to = to
+	b.before, b.after = before, after
+	b.elapsed = 0
+	b.moving = true
+}
+
+// cancel stops any transition in progress, leaving the camera where it is. It
+// is called when the user takes control of the camera themselves.
+func (b *bookmarks) cancel() {
+	b.moving = false
+}
+
+// ctrlPressed reports whether either Ctrl key is held, for shortcuts that
+// mustn't also do whatever the key does alone
+func ctrlPressed(win *pixelgl.Window) bool {
+	return win.Pressed(pixelgl.KeyLeftControl) || win.Pressed(pixelgl.KeyRightControl)
+}
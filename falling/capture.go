@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/faiface/pixel/pixelgl"
+	"github.com/scottyw/falling-trees/recording"
+)
+
+const (
+	// Time between frames of a capture
+	captureInterval = 40 * time.Millisecond
+
+	// GIF captures are this many times smaller than the window on each side
+	gifShrink = 2
+)
+
+// frameSink is where captured frames go, a GIFWriter or a VideoWriter
+type frameSink interface {
+	add(pix []uint8, width, height int) error
+	Dropped() int
+	Close() error
+}
+
+type gifSink struct{ *recording.GIFWriter }
+
+func (s gifSink) add(pix []uint8, width, height int) error {
+	s.AddFrame(pix, width, height)
+	return nil
+}
+
+type videoSink struct{ *recording.VideoWriter }
+
+func (s videoSink) add(pix []uint8, width, height int) error {
+	_, err := s.AddFrame(pix, width, height)
+	return err
+}
+
+// capture grabs the window every so often and hands it to a GIFWriter, or to
+// ffmpeg for any other kind of file such as an MP4, which do the slow work of
+// encoding it in the background
+type capture struct {
+	path  string
+	file  *os.File // Only for GIFs, since ffmpeg writes its own file
+	sink  frameSink
+	since time.Duration
+}
+
+func newCapture(path string) (*capture, error) {
+	c := &capture{path: path, since: captureInterval}
+	if !strings.EqualFold(filepath.Ext(path), ".gif") {
+		c.sink = videoSink{recording.NewVideoWriter(path, recording.VideoOptions{
+			Rate:     int(time.Second / captureInterval),
+			BottomUp: true,
+		})}
+		return c, nil
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	c.file = file
+	c.sink = gifSink{recording.NewGIFWriter(file, recording.GIFOptions{
+		Shrink:   gifShrink,
+		Delay:    captureInterval,
+		BottomUp: true,
+	})}
+	return c, nil
+}
+
+// nextCapture is the first of path, path-2, path-3 and so on that doesn't
+// exist yet, so every capture started from the keyboard gets its own file
+func nextCapture(path string) string {
+	ext := filepath.Ext(path)
+	stem := strings.TrimSuffix(path, ext)
+	for n := 1; ; n++ {
+		name := path
+		if n > 1 {
+			name = fmt.Sprintf("%s-%d%s", stem, n, ext)
+		}
+		if _, err := os.Stat(name); os.IsNotExist(err) {
+			return name
+		}
+	}
+}
+
+// grab reads back the finished frame from the window if it's time for the
+// next frame of the capture. Call it after everything has been drawn.
+func (c *capture) grab(win *pixelgl.Window, dt time.Duration) error {
+	c.since += dt
+	if c.since < captureInterval {
+		return nil
+	}
+	c.since -= captureInterval
+	if c.since >= captureInterval {
+		// Don't try to catch up after a long frame
+		c.since = 0
+	}
+	tex := win.Canvas().Texture()
+	return c.sink.add(win.Canvas().Pixels(), tex.Width(), tex.Height())
+}
+
+// close waits for the last frames to be encoded and finishes the file
+func (c *capture) close() error {
+	err := c.sink.Close()
+	if dropped := c.sink.Dropped(); dropped > 0 {
+		fmt.Fprintf(os.Stderr, "capture: dropped %d frames of %s to keep up\n", dropped, c.path)
+	}
+	if c.file == nil {
+		return err
+	}
+	if err != nil {
+		c.file.Close()
+		return err
+	}
+	return c.file.Close()
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"log"
+	"math"
+	"time"
+
+	"github.com/scottyw/falling-trees/trees"
+)
+
+// Time scales the clock steps through with the slower and faster keys
+var timeScales = []float64{0.25, 0.5, 1, 2}
+
+// clock keeps simulated time, which runs at some scale of wall time and stands
+// still while paused. Anything that animates the world rather than the UI,
+// such as the physics or effects on bodies, should move on by clock.dt so
+// that slow motion slows everything down together. Camera moves and other UI
+// stay on wall time.
+type clock struct {
+	scale    float64
+	paused   bool
+	step     time.Duration // Simulated time in one physics step
+	stepping bool          // Move on by one step on the next tick, even though paused
+	now      time.Duration // Simulated time since the start
+	dt       time.Duration // Simulated time since the previous frame
+}
+
+// stepDuration is a fixed step in seconds as a duration, rounded up so that
+// moving on by it always makes up a whole step
+func stepDuration(seconds float64) time.Duration {
+	return time.Duration(math.Ceil(seconds * float64(time.Second)))
+}
+
+// tick moves the clock on by the wall time taken by the last frame
+func (c *clock) tick(wall time.Duration) {
+	c.dt = 0
+	switch {
+	case !c.paused:
+		c.dt = time.Duration(float64(wall) * c.scale)
+	case c.stepping:
+		c.dt = c.step
+	}
+	c.stepping = false
+	c.now += c.dt
+}
+
+// stepOnce pauses the clock if it's running and moves it on by a single
+// physics step at the next tick
+func (c *clock) stepOnce() {
+	c.paused = true
+	c.stepping = true
+}
+
+// slower and faster move the scale to the next of the timeScales
+func (c *clock) slower() {
+	for i := len(timeScales) - 1; i >= 0; i-- {
+		if timeScales[i] < c.scale {
+			c.scale = timeScales[i]
+			return
+		}
+	}
+}
+
+func (c *clock) faster() {
+	for _, s := range timeScales {
+		if s > c.scale {
+			c.scale = s
+			return
+		}
+	}
+}
+
+// watchHitches logs every frame too long for the physics to take in one go
+func watchHitches(events *trees.Bus) {
+	events.Subscribe(func(e trees.Event) {
+		if h, ok := e.(trees.Hitch); ok {
+			log.Printf("hitch: a frame took %.0fms, so %.0fms of it was dropped", h.Elapsed*1000, h.Dropped*1000)
+		}
+	})
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"strings"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/pixelgl"
+	"github.com/scottyw/falling-trees/trees"
+)
+
+// config holds the settings read from the -config file. Anything left out of
+// the file keeps its default.
+type config struct {
+	Version int `json:"version"`
+
+	// Vertical gravity in metres per second squared
+	Gravity float64 `json:"gravity"`
+
+	// Number of trees to scatter, or null for as many as the level asks for
+	Trees *int `json:"trees"`
+
+	// Linear damping and bounciness of every tree
+	Damping     float64 `json:"damping"`
+	Restitution float64 `json:"restitution"`
+
+	// Solver iterations for each sub-step of the starting solver profile, or
+	// zero to keep the profile's own
+	VelocityIterations int `json:"velocityIterations"`
+	PositionIterations int `json:"positionIterations"`
+
+	Window windowConfig `json:"window"`
+
+	// What to do while the window is in the background: pause, run or slow
+	Unfocused string `json:"unfocused"`
+
+	// Starting zoom of the view
+	Zoom float64 `json:"zoom"`
+
+	// Post-processing effects the world is drawn through, in order, from
+	// vignette, bloom and crt
+	Post []string `json:"post,omitempty"`
+}
+
+type windowConfig struct {
+	Title  string  `json:"title"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+	VSync  bool    `json:"vsync"`
+}
+
+// configFormat has no migrations yet, so every config file is version 0.
+// Add one here whenever a setting is renamed or changes meaning.
+var configFormat = trees.Format{Name: "config"}
+
+func defaultConfig() config {
+	opts := trees.DefaultOptions()
+	return config{
+		Gravity:     opts.Gravity.Y,
+		Damping:     opts.TreeDamping,
+		Restitution: opts.TreeRestitution,
+		Window: windowConfig{
+			Title:  "Pixel Rocks!",
+			Width:  1024,
+			Height: 768,
+			VSync:  true,
+		},
+		Unfocused: "pause",
+		Zoom:      0.4,
+	}
+}
+
+func loadConfig(path string) (config, error) {
+	cfg := defaultConfig()
+	if path == "" {
+		return cfg, nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return cfg, err
+	}
+	defer file.Close()
+	err = configFormat.Decode(file, &cfg)
+	return cfg, err
+}
+
+// override replaces the settings given on the command line, which win over
+// the config file
+func (c *config) override() {
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "trees":
+			c.Trees = treeCount
+		case "gravity":
+			c.Gravity = *gravity
+		case "width":
+			c.Window.Width = *width
+		case "height":
+			c.Window.Height = *height
+		case "zoom":
+			c.Zoom = *zoom
+		case "unfocused":
+			c.Unfocused = *unfocused
+		case "post":
+			c.Post = nil
+			if *postNames != "" {
+				c.Post = strings.Split(*postNames, ",")
+			}
+		}
+	})
+}
+
+// apply copies the physics settings onto the options for a new world
+func (c config) apply(opts *trees.Options) {
+	opts.Gravity = pixel.V(0, c.Gravity)
+	if c.Trees != nil {
+		opts.Trees = *c.Trees
+	}
+	opts.TreeDamping = c.Damping
+	opts.TreeRestitution = c.Restitution
+	if c.VelocityIterations > 0 {
+		opts.Solver.VelocityIterations = c.VelocityIterations
+	}
+	if c.PositionIterations > 0 {
+		opts.Solver.PositionIterations = c.PositionIterations
+	}
+}
+
+func (c windowConfig) pixelgl() pixelgl.WindowConfig {
+	return pixelgl.WindowConfig{
+		Title:  c.Title,
+		Bounds: pixel.R(0, 0, c.Width, c.Height),
+		VSync:  c.VSync,
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"log"
+	"os"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/pixelgl"
+	"github.com/faiface/pixel/text"
+	"github.com/scottyw/falling-trees/storage"
+	"github.com/scottyw/falling-trees/trees"
+	"golang.org/x/image/colornames"
+	"golang.org/x/image/font/basicfont"
+)
+
+// contraption is a build and test loop for putting together machines out of
+// prefabs, stamps and ridges. While building, the world stands still so
+// things can be placed exactly. Press Enter to save what's been built as the
+// blueprint and set it going, and again to build the blueprint afresh and
+// carry on building.
+type contraption struct {
+	testing   bool
+	blueprint trees.Blueprint
+	path      string // Where the blueprint is kept between runs, if anywhere
+	label     *text.Text
+}
+
+// newContraption starts building, from the blueprint saved at path if there
+// is one
+func newContraption(world *trees.World, path string) (*contraption, error) {
+	label := text.New(pixel.ZV, text.NewAtlas(basicfont.Face7x13, text.ASCII))
+	label.Color = colornames.Black
+	c := &contraption{path: path, label: label}
+	if path == "" {
+		return c, nil
+	}
+	file, err := storage.Load(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	if c.blueprint, err = trees.ReadBlueprint(file); err != nil {
+		return nil, err
+	}
+	return c, world.Build(c.blueprint)
+}
+
+// save writes the blueprint to its file, if it has one
+func (c *contraption) save() error {
+	if c.path == "" {
+		return nil
+	}
+	return storage.Save(c.path, func(out io.Writer) error {
+		return trees.WriteBlueprint(out, c.blueprint)
+	})
+}
+
+// close saves what's been built, leaving out any changes made while testing
+func (c *contraption) close(world *trees.World) error {
+	if !c.testing {
+		c.blueprint = world.Blueprint()
+	}
+	return c.save()
+}
+
+// update switches between building and testing with Enter
+func (c *contraption) update(win *pixelgl.Window, world *trees.World) {
+	if !win.JustPressed(pixelgl.KeyEnter) {
+		return
+	}
+	if !c.testing {
+		// Test it even if it couldn't be saved, since it's still held here
+		c.blueprint = world.Blueprint()
+		if err := c.save(); err != nil {
+			log.Printf("can't save the blueprint to %s: %v", c.path, err)
+		}
+		c.testing = true
+		return
+	}
+	if err := world.Build(c.blueprint); err != nil {
+		log.Printf("can't build the blueprint: %v", err)
+		return
+	}
+	c.testing = false
+}
+
+// draw says which half of the loop we're in, in the bottom left corner
+func (c *contraption) draw(win *pixelgl.Window) {
+	c.label.Clear()
+	if c.testing {
+		c.label.WriteString("testing: press Enter to reset and carry on building")
+	} else {
+		c.label.WriteString("building: press Enter to test")
+	}
+	win.SetMatrix(pixel.IM)
+	c.label.Draw(win, pixel.IM.Moved(win.Bounds().Min.Add(pixel.V(8, 8+c.label.Atlas().Descent()))))
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"github.com/faiface/pixel/imdraw"
+	"github.com/faiface/pixel/pixelgl"
+	"github.com/scottyw/falling-trees/camera"
+	"github.com/scottyw/falling-trees/trees"
+)
+
+// debugOverlay draws what the physics is simulating over the scene, to see
+// where the bodies are beneath their sprites
+type debugOverlay struct {
+	shown  bool
+	shapes *imdraw.IMDraw
+}
+
+func newDebugOverlay() *debugOverlay {
+	return &debugOverlay{shapes: imdraw.New(nil)}
+}
+
+// draw shows the overlay over the scene drawn with the camera's matrix,
+// toggling it with B
+func (d *debugOverlay) draw(win *pixelgl.Window, cam camera.Camera, world *trees.World) {
+	if win.JustPressed(pixelgl.KeyB) {
+		d.shown = !d.shown
+	}
+	if !d.shown {
+		return
+	}
+	d.shapes.Clear()
+	world.DrawDebug(d.shapes)
+	win.SetMatrix(cam.Matrix())
+	d.shapes.Draw(win)
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"math"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"github.com/faiface/pixel/pixelgl"
+	"github.com/scottyw/falling-trees/camera"
+	"github.com/scottyw/falling-trees/trees"
+	"golang.org/x/image/colornames"
+)
+
+// Size in metres of the cells the density overlay counts bodies in
+const densityCell = 4
+
+// Trees that fit in a cell packed as tightly as circles go
+var densityCapacity = densityCell * densityCell / (2 * math.Sqrt(3) * trees.TreeRadius * trees.TreeRadius)
+
+// Colours for empty through to tightly packed cells
+var densityRamp = []pixel.RGBA{
+	pixel.ToRGBA(colornames.Royalblue),
+	pixel.ToRGBA(colornames.Limegreen),
+	pixel.ToRGBA(colornames.Gold),
+	pixel.ToRGBA(colornames.Red),
+}
+
+// densityOverlay washes a colour over every cell of the world with bodies in
+// it, running from blue for a few stragglers to red for a packed pile
+type densityOverlay struct {
+	shown bool
+	cells *imdraw.IMDraw
+}
+
+func newDensityOverlay() *densityOverlay {
+	return &densityOverlay{cells: imdraw.New(nil)}
+}
+
+// draw shows the overlay over the scene drawn with the camera's matrix,
+// toggling it with F7
+func (d *densityOverlay) draw(win *pixelgl.Window, cam camera.Camera, world *trees.World) {
+	if win.JustPressed(pixelgl.KeyF7) {
+		d.shown = !d.shown
+	}
+	if !d.shown {
+		return
+	}
+	grid := world.Density(trees.Bounds, densityCell)
+	d.cells.Clear()
+	for row := 0; row < grid.Rows; row++ {
+		for column := 0; column < grid.Columns; column++ {
+			count := grid.Count(column, row)
+			if count == 0 {
+				continue
+			}
+			d.cells.Color = densityColor(float64(count) / densityCapacity).Scaled(0.45)
+			cell := grid.CellRect(column, row)
+			d.cells.Push(cell.Min.Scaled(trees.PixelsPerMetre), cell.Max.Scaled(trees.PixelsPerMetre))
+			d.cells.Rectangle(0)
+		}
+	}
+	win.SetMatrix(cam.Matrix())
+	d.cells.Draw(win)
+}
+
+// densityColor picks a colour along the ramp for how full a cell is, from 0
+// for empty to 1 for packed
+func densityColor(fill float64) pixel.RGBA {
+	at := pixel.Clamp(fill, 0, 1) * float64(len(densityRamp)-1)
+	i := int(at)
+	if i == len(densityRamp)-1 {
+		return densityRamp[i]
+	}
+	t := at - float64(i)
+	return densityRamp[i].Scaled(1 - t).Add(densityRamp[i+1].Scaled(t))
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/pixelgl"
+	"github.com/faiface/pixel/text"
+	"github.com/scottyw/falling-trees/trees"
+	"golang.org/x/image/colornames"
+	"golang.org/x/image/font/basicfont"
+)
+
+// chainLabel shows how many dominoes have fallen and how fast the chain is
+// running along the top of the window, whenever there are dominoes standing
+type chainLabel struct {
+	label *text.Text
+}
+
+func newChainLabel() *chainLabel {
+	label := text.New(pixel.ZV, text.NewAtlas(basicfont.Face7x13, text.ASCII))
+	label.Color = colornames.Black
+	return &chainLabel{label: label}
+}
+
+func (c *chainLabel) draw(win *pixelgl.Window, world *trees.World) {
+	dominoes := 0
+	for _, e := range world.Entities() {
+		if e.Type.Name() == "domino" {
+			dominoes++
+		}
+	}
+	if dominoes == 0 {
+		return
+	}
+	c.label.Clear()
+	fmt.Fprintf(c.label, "%d of %d dominoes toppled", len(world.Topples()), dominoes)
+	if speed, ok := world.ChainSpeed(); ok {
+		fmt.Fprintf(c.label, ", chain running at %.1f m/s", speed)
+	}
+	win.SetMatrix(pixel.IM)
+	top := pixel.V(win.Bounds().Center().X-c.label.Bounds().W()/2, win.Bounds().Max.Y-8-c.label.LineHeight)
+	c.label.Draw(win, pixel.IM.Moved(top))
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/pixelgl"
+	"github.com/scottyw/falling-trees/camera"
+)
+
+// edgePan scrolls the view when the cursor is held within margin pixels of the
+// window edge. The closer the cursor is to the edge the faster the view moves,
+// up to speed pixels per second. It reports whether the camera moved.
+func edgePan(win *pixelgl.Window, cam *camera.Camera, speed, margin, dt float64) bool {
+	if speed <= 0 || margin <= 0 || !win.Focused() || !win.MouseInsideWindow() {
+		return false
+	}
+
+	// How far into the margin the cursor is, from 0 at its inner edge to 1 at the window edge
+	proximity := func(distance float64) float64 {
+		p := (margin - distance) / margin
+		return pixel.Clamp(p, 0, 1)
+	}
+
+	mouse := win.MousePosition()
+	bounds := win.Bounds()
+	direction := pixel.V(
+		proximity(bounds.Max.X-mouse.X)-proximity(mouse.X-bounds.Min.X),
+		proximity(bounds.Max.Y-mouse.Y)-proximity(mouse.Y-bounds.Min.Y),
+	)
+	if direction == pixel.ZV {
+		return false
+	}
+
+	// Moving the view right means moving the world left
+	cam.Pos = cam.Pos.Sub(direction.Scaled(speed * dt))
+	return true
+}
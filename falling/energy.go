@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"github.com/faiface/pixel/pixelgl"
+	"github.com/faiface/pixel/text"
+	"github.com/scottyw/falling-trees/trees"
+	"golang.org/x/image/colornames"
+	"golang.org/x/image/font/basicfont"
+)
+
+const (
+	// Steps of history in the energy graph, ten seconds at the usual step
+	energySteps = 600
+
+	// A step gains energy unexpectedly if the total goes up by more than this
+	// fraction of the kinetic energy, and by at least energyMinGain joules,
+	// which keeps the jitter of a pile at rest out of it
+	energyGainTolerance = 0.01
+	energyMinGain       = 1
+
+	// Size of the graph in pixels
+	energyWidth  = energySteps / 2
+	energyHeight = 100
+)
+
+// energySample is the energy after one step, and whether the step gained
+// energy from nowhere
+type energySample struct {
+	energy trees.Energy
+	gained bool
+}
+
+// energyMonitor adds up the energy of the world after every step while it's
+// shown with N, graphing the total over the last few seconds and flagging
+// steps that gained any without being given it. Spawning, removing, grabbing,
+// explosions and changes of gravity all give the world energy, as does the
+// tide while it's in, so steps with any of them aren't flagged, but the tools
+// and gusts can't be told apart from the solver going unstable, so leave the
+// world alone while watching for gains.
+type energyMonitor struct {
+	shown   bool
+	samples []energySample
+	outside bool // Energy was given to the world this step
+	gains   int
+	graph   *imdraw.IMDraw
+	label   *text.Text
+}
+
+func newEnergyMonitor() *energyMonitor {
+	label := text.New(pixel.ZV, text.NewAtlas(basicfont.Face7x13, text.ASCII))
+	label.Color = colornames.Black
+	return &energyMonitor{graph: imdraw.New(nil), label: label}
+}
+
+// watch measures the world after each of its steps
+func (m *energyMonitor) watch(world *trees.World) {
+	m.samples, m.gains = nil, 0
+	world.Events().Subscribe(func(e trees.Event) {
+		if !m.shown {
+			return
+		}
+		switch e.(type) {
+		case trees.BodySpawned, trees.BodyDestroyed, trees.Explosion, trees.GravityChanged:
+			m.outside = true
+		case trees.Stepped:
+			m.sample(world)
+		}
+	})
+}
+
+func (m *energyMonitor) sample(world *trees.World) {
+	s := energySample{energy: world.Energy()}
+	if world.Tide().Period > 0 {
+		m.outside = true
+	}
+	if n := len(m.samples); n > 0 && !m.outside && world.Grabbed() == nil {
+		last := m.samples[n-1]
+		gain := s.energy.Total() - last.energy.Total()
+		s.gained = gain > math.Max(energyMinGain, energyGainTolerance*last.energy.Kinetic)
+		if s.gained {
+			m.gains++
+			if !last.gained {
+				log.Printf("energy: a step gained %.0fJ from nowhere, is the solver unstable?", gain)
+			}
+		}
+	}
+	m.outside = false
+	m.samples = append(m.samples, s)
+	if len(m.samples) > energySteps {
+		m.samples = m.samples[len(m.samples)-energySteps:]
+	}
+}
+
+// draw toggles the graph with N and shows it in the bottom right corner, above
+// the solver and gravity
+func (m *energyMonitor) draw(win *pixelgl.Window) {
+	if win.JustPressed(pixelgl.KeyN) {
+		m.shown = !m.shown
+		m.samples, m.gains, m.outside = nil, 0, false
+	}
+	if !m.shown {
+		return
+	}
+	bottom := win.Bounds().Min.Y + 64
+	panel := pixel.R(win.Bounds().Max.X-8-energyWidth, bottom, win.Bounds().Max.X-8, bottom+energyHeight)
+
+	// Fit the graph to the range of the totals
+	low, high := math.Inf(1), math.Inf(-1)
+	for _, s := range m.samples {
+		low = math.Min(low, s.energy.Total())
+		high = math.Max(high, s.energy.Total())
+	}
+	if high-low < energyMinGain {
+		high = low + energyMinGain
+	}
+	m.graph.Clear()
+	m.graph.Color = pixel.RGBA{R: 1, G: 1, B: 1, A: 0.8}
+	m.graph.Push(panel.Min, panel.Max)
+	m.graph.Rectangle(0)
+	m.graph.Color = colornames.Orangered
+	for i, s := range m.samples {
+		if s.gained {
+			x := panel.Min.X + float64(i)*energyWidth/energySteps
+			m.graph.Push(pixel.V(x, panel.Min.Y), pixel.V(x, panel.Max.Y))
+			m.graph.Line(1)
+		}
+	}
+	m.graph.Color = colornames.Steelblue
+	for i, s := range m.samples {
+		x := panel.Min.X + float64(i)*energyWidth/energySteps
+		m.graph.Push(pixel.V(x, panel.Min.Y+(s.energy.Total()-low)/(high-low)*energyHeight))
+	}
+	if len(m.samples) > 1 {
+		m.graph.Line(2)
+	}
+	win.SetMatrix(pixel.IM)
+	m.graph.Draw(win)
+
+	m.label.Clear()
+	if n := len(m.samples); n > 0 {
+		e := m.samples[n-1].energy
+		fmt.Fprintf(m.label, "energy %s, kinetic %s\n", joules(e.Total()), joules(e.Kinetic))
+	}
+	fmt.Fprintf(m.label, "%d unexpected gains", m.gains)
+	m.label.Draw(win, pixel.IM.Moved(pixel.V(panel.Min.X+4, panel.Max.Y-m.label.LineHeight)))
+}
+
+// joules writes an amount of energy with a sensible prefix
+func joules(j float64) string {
+	switch a := math.Abs(j); {
+	case a >= 1e6:
+		return fmt.Sprintf("%.2fMJ", j/1e6)
+	case a >= 1e3:
+		return fmt.Sprintf("%.2fkJ", j/1e3)
+	}
+	return fmt.Sprintf("%.0fJ", j)
+}
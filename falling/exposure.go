@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/pixelgl"
+	"github.com/faiface/pixel/text"
+	"golang.org/x/image/colornames"
+	"golang.org/x/image/font/basicfont"
+)
+
+const (
+	// How far each press of a bracket moves the exposure in stops, the
+	// brightness and the contrast
+	exposureStep   = 0.25
+	brightnessStep = 0.05
+	contrastStep   = 0.1
+
+	// How long the settings stay on screen after they're changed
+	exposureShown = 2 * time.Second
+)
+
+// exposureShader scales the colour of each pixel by the exposure, then
+// stretches it about mid grey by the contrast and lifts it by the brightness
+var exposureShader = `
+#version 330 core
+
+in vec2 vTexCoords;
+
+out vec4 fragColor;
+
+uniform vec4 uTexBounds;
+uniform sampler2D uTexture;
+uniform float uGain;
+uniform float uBrightness;
+uniform float uContrast;
+
+void main() {
+	vec2 t = (vTexCoords - uTexBounds.xy) / uTexBounds.zw;
+	vec4 c = texture(uTexture, t);
+	vec3 rgb = (c.rgb * uGain - 0.5) * uContrast + 0.5 + uBrightness;
+	fragColor = vec4(clamp(rgb, 0.0, 1.0), c.a);
+}
+`
+
+// exposure adjusts the colours of the whole finished frame, for recording or
+// for projectors that wash everything out. The frame is post-processed
+// through the shader, which only happens while the settings are anything
+// other than neutral. Exposure is in photographic stops, each one doubling or
+// halving the light.
+type exposure struct {
+	stops      float64
+	brightness float64
+	contrast   float64
+
+	stage   *postStage
+	gain    float32 // Uniforms the shader reads
+	bright  float32
+	stretch float32
+	label   *text.Text
+	shown   time.Duration // Left to show the settings for
+}
+
+func newExposure(stops, brightness, contrast float64) *exposure {
+	label := text.New(pixel.ZV, text.NewAtlas(basicfont.Face7x13, text.ASCII))
+	label.Color = colornames.Black
+	e := &exposure{stops: stops, brightness: brightness, contrast: contrast, label: label}
+	e.stage = &postStage{shader: exposureShader, uniforms: map[string]interface{}{
+		"uGain":       &e.gain,
+		"uBrightness": &e.bright,
+		"uContrast":   &e.stretch,
+	}}
+	return e
+}
+
+// neutral reports whether the settings leave the frame as it is
+func (e *exposure) neutral() bool {
+	return e.stops == 0 && e.brightness == 0 && e.contrast == 1
+}
+
+// adjust changes the settings with the brackets, the brightness with Shift
+// held and the contrast with Ctrl held, and resets them all with backslash
+func (e *exposure) adjust(win *pixelgl.Window) {
+	step := 0.0
+	switch {
+	case win.JustPressed(pixelgl.KeyLeftBracket) || win.Repeated(pixelgl.KeyLeftBracket):
+		step = -1
+	case win.JustPressed(pixelgl.KeyRightBracket) || win.Repeated(pixelgl.KeyRightBracket):
+		step = 1
+	case win.JustPressed(pixelgl.KeyBackslash):
+		e.stops, e.brightness, e.contrast = 0, 0, 1
+		e.shown = exposureShown
+		return
+	default:
+		return
+	}
+	switch {
+	case ctrlPressed(win):
+		e.contrast = math.Max(0, e.contrast+step*contrastStep)
+	case win.Pressed(pixelgl.KeyLeftShift) || win.Pressed(pixelgl.KeyRightShift):
+		e.brightness += step * brightnessStep
+	default:
+		e.stops += step * exposureStep
+	}
+	e.shown = exposureShown
+}
+
+// draw adjusts the frame drawn into the window so far, and shows the settings
+// for a moment after they change
+func (e *exposure) draw(win *pixelgl.Window, dt time.Duration) {
+	e.adjust(win)
+	if !e.neutral() {
+		e.gain = float32(math.Exp2(e.stops))
+		e.bright = float32(e.brightness)
+		e.stretch = float32(e.contrast)
+		postProcess(win, []*postStage{e.stage})
+	}
+	win.SetMatrix(pixel.IM)
+
+	if e.shown <= 0 {
+		return
+	}
+	e.shown -= dt
+	e.label.Clear()
+	fmt.Fprintf(e.label, "exposure %+.2f  brightness %+.2f  contrast %.1f", e.stops, e.brightness, e.contrast)
+	at := pixel.V(win.Bounds().Center().X-e.label.Bounds().W()/2, win.Bounds().Min.Y+8)
+	e.label.Draw(win, pixel.IM.Moved(at))
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/faiface/pixel/pixelgl"
+)
+
+const (
+	// Frames a second drawn while the window is in the background in slow mode
+	backgroundRate = 10
+
+	// Share of full speed the world runs at in the background in slow mode,
+	// so it takes fewer steps as well as drawing fewer frames
+	backgroundSpeed = 0.25
+
+	// Longest a frame in the background is taken to last, since a minimised
+	// window can go without frames for as long as it stays minimised
+	backgroundHitch = time.Second / 4
+)
+
+// background decides what happens while the window doesn't have the focus:
+// "pause" stands the world still, "run" carries on as if nothing happened,
+// and "slow" carries on at a quarter of the speed, drawing only a few frames
+// a second, to leave the machine to whatever has the focus instead.
+type background struct {
+	mode  string
+	away  bool      // The window didn't have the focus last frame
+	frame time.Time // When the last frame in the background started
+}
+
+func newBackground(mode string) (*background, error) {
+	switch mode {
+	case "pause", "run", "slow":
+		return &background{mode: mode}, nil
+	}
+	return nil, fmt.Errorf("unfocused must be pause, run or slow, not %q", mode)
+}
+
+// elapsed turns the time taken by the last frame into the time to move on by.
+// While paused in the background, and on the first frame back, that's none at
+// all. Otherwise frames in the background and the first one back are cut
+// short, so time that passed while the window was hidden, which can be
+// minutes if it was minimised, never reaches the world in one go, and in slow
+// mode frames in the background move on by only part of the time they took.
+func (b *background) elapsed(win *pixelgl.Window, dt time.Duration) time.Duration {
+	focused := win.Focused()
+	wasAway := b.away
+	b.away = !focused
+	switch {
+	case focused && !wasAway:
+		return dt
+	case b.mode == "pause":
+		return 0
+	case dt > backgroundHitch:
+		dt = backgroundHitch
+	}
+	if b.mode == "slow" && !focused {
+		dt = time.Duration(float64(dt) * backgroundSpeed)
+	}
+	return dt
+}
+
+// wait holds up the next frame in slow mode until it's due
+func (b *background) wait(win *pixelgl.Window) {
+	if b.mode != "slow" || win.Focused() {
+		return
+	}
+	if next := b.frame.Add(time.Second / backgroundRate); time.Now().Before(next) {
+		time.Sleep(time.Until(next))
+	}
+	b.frame = time.Now()
+}
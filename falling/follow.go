@@ -0,0 +1,49 @@
+package main
+
+import (
+	"math"
+
+	"github.com/faiface/pixel"
+	"github.com/scottyw/falling-trees/camera"
+	"github.com/scottyw/falling-trees/trees"
+)
+
+// followRate is how quickly the view catches up with the tree it follows.
+// Roughly this fraction of the distance is closed every second.
+const followRate = 6.0
+
+// follower keeps the main view centred on one tree as it falls and rolls,
+// gliding after it rather than jumping so the view doesn't shake as the tree
+// jostles about in the pile
+type follower struct {
+	target *trees.Entity
+	id     uint64 // The target's, since the entities of removed trees are reused
+}
+
+// toggle starts following whatever is nearest a point in metres, or stops
+// following if something already is
+func (f *follower) toggle(world *trees.World, point pixel.Vec) {
+	if f.target != nil {
+		f.target = nil
+		return
+	}
+	f.target = world.Pick(point, pipPickRadius)
+	if f.target != nil {
+		f.id = f.target.ID
+	}
+}
+
+// update moves the view towards the followed tree, giving up on it if it's
+// gone from the world
+func (f *follower) update(cam *camera.Camera, world *trees.World, centre pixel.Vec, dt float64) {
+	if f.target == nil {
+		return
+	}
+	if e, ok := world.Lookup(f.target.Body); !ok || e.ID != f.id {
+		f.target = nil
+		return
+	}
+	p := f.target.Body.GetWorldCenter()
+	offset := centre.Sub(cam.ToScreen(pixel.V(p.X, p.Y)))
+	cam.Pos = cam.Pos.Add(offset.Scaled(1 - math.Exp(-followRate*dt)))
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/ByteArena/box2d"
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"github.com/faiface/pixel/pixelgl"
+	"github.com/faiface/pixel/text"
+	"github.com/scottyw/falling-trees/camera"
+	"github.com/scottyw/falling-trees/trees"
+	"golang.org/x/image/colornames"
+	"golang.org/x/image/font/basicfont"
+)
+
+const (
+	// Length of a force arrow in metres per newton
+	forceScale = 0.08
+
+	// Forces weaker than this in newtons aren't worth an arrow
+	forceMin = 0.5
+
+	// How far from the cursor in metres to look for a body to annotate
+	forcePickRadius = 3.0
+)
+
+var forceColours = map[string]color.Color{
+	trees.ForceGravity:  colornames.Purple,
+	trees.ForceNormal:   colornames.Royalblue,
+	trees.ForceFriction: colornames.Darkorange,
+}
+
+// forceOverlay is a teaching aid that draws the forces acting on a single
+// body as labelled arrows
+type forceOverlay struct {
+	shown  bool
+	arrows *imdraw.IMDraw
+	labels *text.Text
+}
+
+func newForceOverlay() *forceOverlay {
+	return &forceOverlay{
+		arrows: imdraw.New(nil),
+		labels: text.New(pixel.ZV, text.NewAtlas(basicfont.Face7x13, text.ASCII)),
+	}
+}
+
+// draw annotates the body being followed by the picture-in-picture view, or
+// else whatever is nearest the cursor, toggling the overlay with F5
+func (f *forceOverlay) draw(win *pixelgl.Window, cam camera.Camera, world *trees.World, followed *box2d.B2Body) {
+	if win.JustPressed(pixelgl.KeyF5) {
+		f.shown = !f.shown
+	}
+	if !f.shown {
+		return
+	}
+	body := followed
+	if body == nil {
+		e := world.Pick(cam.ToWorld(win.MousePosition()), forcePickRadius)
+		if e == nil {
+			return
+		}
+		body = e.Body
+	}
+	forces, torque := world.Forces(body)
+
+	f.arrows.Clear()
+	f.labels.Clear()
+	m := cam.Matrix()
+	label := func(at pixel.Vec, c color.Color, format string, args ...interface{}) {
+		f.labels.Color = c
+		f.labels.Dot = cam.ToScreen(at).Add(pixel.V(6, 6))
+		fmt.Fprintf(f.labels, format, args...)
+	}
+	for _, force := range forces {
+		size := force.Vector.Len()
+		if size < forceMin {
+			continue
+		}
+		tip := force.Point.Add(force.Vector.Scaled(forceScale))
+		f.arrows.Color = forceColours[force.Kind]
+		arrow(f.arrows, force.Point.Scaled(trees.PixelsPerMetre), tip.Scaled(trees.PixelsPerMetre))
+		label(tip, forceColours[force.Kind], "%s %.1f N", force.Kind, size)
+	}
+	centre := body.GetWorldCenter()
+	label(pixel.V(centre.X, centre.Y-trees.TreeRadius), colornames.Black, "torque %.2f N m", torque)
+
+	win.SetMatrix(m)
+	f.arrows.Draw(win)
+	win.SetMatrix(pixel.IM)
+	f.labels.Draw(win, pixel.IM)
+	win.SetMatrix(m)
+}
+
+// arrow pushes a line with a head at the to end
+func arrow(imd *imdraw.IMDraw, from, to pixel.Vec) {
+	imd.Push(from, to)
+	imd.Line(3)
+	back := from.Sub(to).Unit().Scaled(10)
+	for _, angle := range []float64{0.5, -0.5} {
+		imd.Push(to, to.Add(back.Rotated(angle)))
+		imd.Line(3)
+	}
+}
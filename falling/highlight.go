@@ -0,0 +1,101 @@
+package main
+
+import (
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"github.com/faiface/pixel/pixelgl"
+	"github.com/scottyw/falling-trees/camera"
+	"github.com/scottyw/falling-trees/trees"
+	"golang.org/x/image/colornames"
+)
+
+// Colours of the outlines around whatever is under the cursor and whatever
+// is picked, followed or held
+var (
+	hoverColour    = pixel.ToRGBA(colornames.Dodgerblue).Scaled(0.8)
+	selectedColour = pixel.ToRGBA(colornames.Orange)
+)
+
+// outlineShader draws an outline around the shapes in a mask, in the colour
+// of whichever shape is nearest, and nothing inside them so what's outlined
+// shows through
+var outlineShader = `
+#version 330 core
+
+in vec2 vTexCoords;
+
+out vec4 fragColor;
+
+uniform vec4 uTexBounds;
+uniform sampler2D uTexture;
+
+void main() {
+	vec2 t = (vTexCoords - uTexBounds.xy) / uTexBounds.zw;
+	vec2 px = 1.0 / uTexBounds.zw;
+	if (texture(uTexture, t).a > 0.0) {
+		fragColor = vec4(0.0);
+		return;
+	}
+	vec4 nearest = vec4(0.0);
+	float best = 100.0;
+	for (int x = -3; x <= 3; x++) {
+		for (int y = -3; y <= 3; y++) {
+			float d = float(x * x + y * y);
+			vec4 s = texture(uTexture, t + vec2(x, y) * px);
+			if (d <= 10.0 && d < best && s.a > 0.0) {
+				nearest = s;
+				best = d;
+			}
+		}
+	}
+	fragColor = nearest;
+}
+`
+
+// highlighter outlines bodies in screen space, so the outline is the same
+// few pixels wide however far the view is zoomed. Their silhouettes are drawn
+// into a mask, which is drawn through the outline shader over the window.
+type highlighter struct {
+	shapes  *imdraw.IMDraw
+	mask    *pixelgl.Canvas
+	outline *postStage
+}
+
+func newHighlighter() *highlighter {
+	return &highlighter{shapes: imdraw.New(nil), outline: &postStage{shader: outlineShader}}
+}
+
+// draw outlines whatever the cursor is over, which is what a click would
+// grab, and each of the selected entities
+func (h *highlighter) draw(win *pixelgl.Window, cam camera.Camera, world *trees.World, selected []*trees.Entity) {
+	h.shapes.Clear()
+	outlined := false
+	if e := world.At(cam.ToWorld(win.MousePosition())); e != nil {
+		h.shapes.Color = hoverColour
+		world.DrawSilhouette(h.shapes, e)
+		outlined = true
+	}
+	// Drawn after the hovered body so they win where they overlap
+	h.shapes.Color = selectedColour
+	for _, e := range selected {
+		if e != nil {
+			world.DrawSilhouette(h.shapes, e)
+			outlined = true
+		}
+	}
+	if !outlined {
+		return
+	}
+
+	if h.mask == nil {
+		h.mask = pixelgl.NewCanvas(win.Bounds())
+	}
+	if h.mask.Bounds() != win.Bounds() {
+		h.mask.SetBounds(win.Bounds())
+	}
+	h.mask.Clear(pixel.Alpha(0))
+	h.mask.SetMatrix(cam.Matrix())
+	h.shapes.Draw(h.mask)
+	win.SetMatrix(pixel.IM)
+	h.outline.draw(win, h.mask).Draw(win, pixel.IM.Moved(win.Bounds().Center()))
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/pixelgl"
+	"github.com/faiface/pixel/text"
+	"github.com/scottyw/falling-trees/camera"
+	"github.com/scottyw/falling-trees/trees"
+	"golang.org/x/image/colornames"
+	"golang.org/x/image/font/basicfont"
+)
+
+// Weight given to the latest frame when smoothing the frame rate
+const fpsSmoothing = 0.05
+
+// statsHUD lists the numbers that matter when tuning performance in the top
+// right corner of the window while it's shown with H, below the picture in
+// picture when that's showing
+type statsHUD struct {
+	shown bool
+	frame time.Duration // Smoothed time between frames
+	drawn int           // Trees drawn in the window this frame
+	label *text.Text
+}
+
+func newStatsHUD() *statsHUD {
+	label := text.New(pixel.ZV, text.NewAtlas(basicfont.Face7x13, text.ASCII))
+	label.Color = colornames.Black
+	return &statsHUD{label: label}
+}
+
+// draw shows the stats for a frame that took dt, with physics the time the
+// physics systems took to run this frame
+func (h *statsHUD) draw(win *pixelgl.Window, dt, physics time.Duration, world *trees.World, cam camera.Camera, belowPip bool) {
+	if win.JustPressed(pixelgl.KeyH) {
+		h.shown = !h.shown
+	}
+	h.frame += time.Duration(float64(dt-h.frame) * fpsSmoothing)
+	if !h.shown {
+		return
+	}
+
+	var fps float64
+	if h.frame > 0 {
+		fps = float64(time.Second) / float64(h.frame)
+	}
+	touching := 0
+	for contact := world.Physics().GetContactList(); contact != nil; contact = contact.GetNext() {
+		if contact.IsTouching() {
+			touching++
+		}
+	}
+	h.label.Clear()
+	fmt.Fprintf(h.label, "fps      %6.1f (%.1fms)\n", fps, ms(h.frame))
+	fmt.Fprintf(h.label, "physics  %6.2fms\n", ms(physics))
+	fmt.Fprintf(h.label, "bodies   %6d (%d trees, %d pooled)\n", world.Physics().GetBodyCount(), len(world.Trees()), world.Pooled())
+	fmt.Fprintf(h.label, "drawn    %6d trees\n", h.drawn)
+	fmt.Fprintf(h.label, "asleep   %6d (%d fallen off)\n", world.Asleep(), world.Fallen())
+	fmt.Fprintf(h.label, "contacts %6d (%d touching)\n", world.Physics().GetContactCount(), touching)
+	fmt.Fprintf(h.label, "zoom     %6.2fx\n", cam.Zoom)
+
+	top := win.Bounds().Max.Y - 8
+	if belowPip {
+		top -= pipBounds.H() + pipMargin
+	}
+	// Right align against the widest line
+	at := pixel.V(win.Bounds().Max.X-8-h.label.Bounds().W(), top-h.label.LineHeight)
+	win.SetMatrix(pixel.IM)
+	h.label.Draw(win, pixel.IM.Moved(at))
+}
+
+// ms is a duration in milliseconds
+func ms(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
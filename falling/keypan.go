@@ -0,0 +1,33 @@
+package main
+
+import (
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/pixelgl"
+	"github.com/scottyw/falling-trees/camera"
+)
+
+// keyPan scrolls the view with WASD or the arrow keys at speed pixels per
+// second on screen, so the view covers more of the world a second the
+// further out it's zoomed. Holding Ctrl leaves the view alone for shortcuts
+// such as Ctrl+S. It reports whether the camera moved.
+func keyPan(win *pixelgl.Window, cam *camera.Camera, speed, dt float64) bool {
+	held := func(keys ...pixelgl.Button) float64 {
+		for _, k := range keys {
+			if win.Pressed(k) {
+				return 1
+			}
+		}
+		return 0
+	}
+	direction := pixel.V(
+		held(pixelgl.KeyD, pixelgl.KeyRight)-held(pixelgl.KeyA, pixelgl.KeyLeft),
+		held(pixelgl.KeyW, pixelgl.KeyUp)-held(pixelgl.KeyS, pixelgl.KeyDown),
+	)
+	if direction == pixel.ZV || speed <= 0 || ctrlPressed(win) {
+		return false
+	}
+
+	// Moving the view right means moving the world left
+	cam.Pos = cam.Pos.Sub(direction.Unit().Scaled(speed * dt))
+	return true
+}
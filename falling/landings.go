@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"github.com/faiface/pixel/pixelgl"
+	"github.com/faiface/pixel/text"
+	"github.com/scottyw/falling-trees/trees"
+	"golang.org/x/image/colornames"
+	"golang.org/x/image/font/basicfont"
+)
+
+const (
+	// Range in metres the landing histogram covers, which is the width of the
+	// base, and how wide each bar is
+	landingFrom  = -50
+	landingTo    = 50
+	landingWidth = 2
+
+	// Height in pixels of the tallest bar
+	landingHeight = 120
+)
+
+// landingHistogram counts where the trees came to rest and draws the counts
+// as bars along the bottom of the window, like the bins of a Galton board
+type landingHistogram struct {
+	shown bool
+	bars  *imdraw.IMDraw
+	label *text.Text
+}
+
+func newLandingHistogram() *landingHistogram {
+	label := text.New(pixel.ZV, text.NewAtlas(basicfont.Face7x13, text.ASCII))
+	label.Color = colornames.Black
+	return &landingHistogram{bars: imdraw.New(nil), label: label}
+}
+
+// draw shows the histogram in screen space, toggling it with F8
+func (h *landingHistogram) draw(win *pixelgl.Window, world *trees.World) {
+	if win.JustPressed(pixelgl.KeyF8) {
+		h.shown = !h.shown
+	}
+	if !h.shown {
+		return
+	}
+	counts := make([]int, (landingTo-landingFrom)/landingWidth)
+	most := 1
+	var sum, squares float64
+	landings := world.Landings()
+	for _, l := range landings {
+		sum += l.X
+		squares += l.X * l.X
+		bin := int(math.Floor((l.X - landingFrom) / landingWidth))
+		if bin < 0 || bin >= len(counts) {
+			continue
+		}
+		counts[bin]++
+		if counts[bin] > most {
+			most = counts[bin]
+		}
+	}
+
+	bounds := win.Bounds()
+	width := bounds.W() / float64(len(counts))
+	h.bars.Clear()
+	h.bars.Color = pixel.ToRGBA(colornames.Steelblue).Scaled(0.7)
+	for i, n := range counts {
+		if n == 0 {
+			continue
+		}
+		x := bounds.Min.X + float64(i)*width
+		h.bars.Push(pixel.V(x+1, bounds.Min.Y), pixel.V(x+width-1, bounds.Min.Y+landingHeight*float64(n)/float64(most)))
+		h.bars.Rectangle(0)
+	}
+	win.SetMatrix(pixel.IM)
+	h.bars.Draw(win)
+
+	h.label.Clear()
+	fmt.Fprintf(h.label, "%d landed", len(landings))
+	if n := float64(len(landings)); n > 0 {
+		mean := sum / n
+		fmt.Fprintf(h.label, ", mean x %.1fm, sd %.1fm", mean, math.Sqrt(math.Max(squares/n-mean*mean, 0)))
+	}
+	h.label.Draw(win, pixel.IM.Moved(pixel.V(bounds.Min.X+8, bounds.Min.Y+landingHeight+8)))
+}
+
+// writeLandings saves where every tree landed as CSV
+func writeLandings(world *trees.World, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := world.WriteLandings(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
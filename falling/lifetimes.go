@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/scottyw/falling-trees/trees"
+)
+
+// parseLifetimes reads the -lifetime flag, a comma separated list of entity
+// types and the seconds they last, such as "tree=60,rock=30"
+func parseLifetimes(s string) (map[string]float64, error) {
+	return parseByType(s, "lifetime", "seconds", "a number of seconds")
+}
+
+// parseMaxSpeeds reads the -max-speed flag, a comma separated list of entity
+// types and the fastest they move in metres per second, such as "tree=40"
+func parseMaxSpeeds(s string) (map[string]float64, error) {
+	return parseByType(s, "max speed", "speed", "a speed in metres per second")
+}
+
+// parseByType reads a comma separated list of type=value pairs, where every
+// type is a tree or a registered entity type and every value is a number that
+// isn't negative
+func parseByType(s, what, value, number string) (map[string]float64, error) {
+	values := map[string]float64{}
+	if s == "" {
+		return values, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%s %q isn't a type=%s pair", what, pair, value)
+		}
+		name := strings.TrimSpace(parts[0])
+		if _, ok := trees.LookupEntityType(name); !ok && name != "tree" {
+			return nil, fmt.Errorf("%s for unknown type %q", what, name)
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		if err != nil || v < 0 {
+			return nil, fmt.Errorf("%s %q isn't %s", what, pair, number)
+		}
+		values[name] = v
+	}
+	return values, nil
+}
@@ -1,182 +1,411 @@
 package main
 
 import (
-	"image"
-	_ "image/png"
+	"context"
+	"flag"
+	"fmt"
+	"log"
 	"math"
 	"math/rand"
-	"os"
 	"time"
 
-	"github.com/ByteArena/box2d"
 	"github.com/faiface/pixel"
-	"github.com/faiface/pixel/imdraw"
 	"github.com/faiface/pixel/pixelgl"
-	"golang.org/x/image/colornames"
+	"github.com/scottyw/falling-trees/camera"
+	"github.com/scottyw/falling-trees/trees"
 )
 
 const (
-	// Prepare for simulation. Typically we use a time step of 1/60 of a
-	// second (60Hz) and 10 iterations. This provides a high quality simulation
-	// in most game scenarios.
-	velocityIterations = 8
-	positionIterations = 3
-
 	camZoomSpeed = 1.2
+
+	// Camera rotation speed in radians per second
+	camRotateSpeed = 1.0
+)
+
+var (
+	configPath    = flag.String("config", "", "JSON file of settings for the physics and window")
+	seed          = flag.Int64("seed", 0, "seed for scattering the trees, so a run can be repeated exactly; 0 picks a new one and logs it")
+	treeCount     = flag.Int("trees", 0, "number of trees to scatter, instead of as many as the level asks for")
+	gravity       = flag.Float64("gravity", trees.DefaultOptions().Gravity.Y, "vertical gravity in metres per second squared")
+	width         = flag.Float64("width", 1024, "width of the window in pixels")
+	height        = flag.Float64("height", 768, "height of the window in pixels")
+	presetName    = flag.String("gravity-preset", "", "start with the gravity and air of zero-g, the moon, mars, earth or jupiter")
+	tidePeriod    = flag.Float64("tide", 0, "seconds for gravity to ebb and flow once, 0 for steady gravity")
+	tideStrength  = flag.Float64("tide-strength", 1.5, "how far gravity ebbs and flows as a multiple of its usual strength, above 1 to lift trees off the pile")
+	snowfall      = flag.Float64("snow", 0, "let it snow, capping trees that rest with nothing above them over this many seconds, 0 for no snow")
+	seeding       = flag.Float64("seeds", 0, "let landed trees drop seeds this many seconds apart on average, which grow into trees where they land on soil, 0 for none")
+	softGround    = flag.Bool("soft-ground", false, "lay soil over the terrain that slowly sinks under the weight of the pile")
+	tideSwing     = flag.Float64("tide-swing", 0, "degrees the direction of gravity swings each way with the tide")
+	zoom          = flag.Float64("zoom", 0.4, "starting zoom of the view")
+	waveSize      = flag.Int("wave", 100, "number of trees G drops as a new wave")
+	lifetimes     = flag.String("lifetime", "", "seconds each type lasts before fading away, such as tree=60,rock=30")
+	maxSpeeds     = flag.String("max-speed", "", "fastest each type moves in metres per second, such as tree=40,rock=60, to keep explosions from flinging things through the ground")
+	tutorialMode  = flag.Bool("tutorial", false, "start with a short tour of the controls, which F1 starts again at any time")
+	attractAfter  = flag.Float64("attract", 0, "seconds without input before the attract mode takes over, 0 to disable")
+	clampCamera   = flag.Bool("clamp-camera", false, "stop the centre of the view leaving the world bounds")
+	pairHost      = flag.String("pair-host", "", "listen on this address for a guest to drive the camera and tools, e.g. :7777")
+	pairConnect   = flag.String("pair-connect", "", "connect to a host as its guest, mirroring the camera and tools onto it")
+	pairICE       = flag.String("pair-ice", "", "STUN or TURN servers for pairing across networks, separated by commas, such as stun:stun.l.google.com:19302")
+	inputsPath    = flag.String("inputs", "", "record everything done to the world, with the seed, to a replay file")
+	replayPath    = flag.String("replay", "", "re-simulate a replay file recorded with -inputs, exactly as it happened")
+	playPath      = flag.String("play", "", "play back a recording instead of running the simulation")
+	recordPath    = flag.String("record", "", "record the position of every tree to a file")
+	gifPath       = flag.String("gif", "", "capture the window from the start to an animated GIF, or to a video such as an MP4 with ffmpeg")
+	capturePath   = flag.String("capture", "capture.gif", "file Ctrl+R captures the window to, numbered so each capture gets its own; anything but a GIF is encoded with ffmpeg")
+	shotsPath     = flag.String("screenshots", "", "directory F12 saves screenshots to, instead of next to the executable")
+	landingsPath  = flag.String("landings", "", "save where every tree came to rest to a CSV file on exit")
+	prefsPath     = flag.String("preferences", defaultPreferencesPath(), "file to keep preferences such as which mods are switched on in")
+	assetsName    = flag.String("assets", defaultPack, "asset pack to draw the trees with, from falling/packs or a mod")
+	defaultAssets = flag.String("default-assets", "", "directory to read the default asset pack from instead of the copy built into the demo, to try out new sprites without rebuilding")
+	watchPack     = flag.Bool("watch-assets", false, "reload the sprites whenever the asset pack's files change, without checking them against its manifest, to see art as it's drawn")
+	seasonName    = flag.String("season", "summer", "colour the trees for summer, autumn or winter")
+	contraptions  = flag.Bool("contraption", false, "build contraptions, with the world standing still until Enter sets it going")
+	showMenu      = flag.Bool("menu", true, "start at the menu of levels, modes and recent saves, unless other flags already say what to play")
+	gameMode      = flag.Bool("game", false, "play the level with a few trees to drop by hand, scored by its bins or dominoes")
+	gameTrees     = flag.Int("game-trees", 20, "number of trees to drop in a game")
+	blueprintPath = flag.String("blueprint", "", "in contraption mode, build from this blueprint file or URL and save what's built back to it")
+	levelFile     = flag.String("level-file", "", "play the level in this JSON file, Tiled .tmx map or URL, such as a scene saved with F11")
+	scenePath     = flag.String("scene", "scene.json", "file or URL that F11 saves a freeze frame of the world to, as a level")
+	savePath      = flag.String("save", "world.json", "file or URL that Ctrl+S saves the world to and Ctrl+L loads it from")
+	loadPath      = flag.String("load", "", "carry on with a world saved with Ctrl+S, in its level and with its trees")
+	levelName     = flag.String("level", trees.DefaultOptions().Level, "level to play, such as mountain, galton or dominoes")
+	terrainSeed   = flag.Int64("terrain-seed", 0, "play hills generated from this seed, each seed with hills and valleys of its own")
+	smooth        = flag.Bool("smooth", false, "sample sprites with linear filtering instead of keeping their pixels sharp")
+	pixelSnap     = flag.Bool("pixel-snap", false, "snap the zoom to whole multiples of the sprite pixels so the pixel art stays crisp")
+	postNames     = flag.String("post", "", "draw the world through these post-processing effects in order, separated by commas, from vignette, bloom and crt")
+	unfocused     = flag.String("unfocused", "pause", "while the window is in the background, pause the world, run it as usual, or run it slow, at a quarter of the speed and a few frames a second")
+	snapFrames    = flag.Bool("snap-frames", true, "under vsync, time frames as whole refreshes of the monitor so motion doesn't stutter")
+	timeScale     = flag.Float64("time-scale", 1, "how fast simulated time runs compared to real time")
+	inertia       = flag.Bool("inertia", true, "keep the view gliding after a drag is released")
+	pprofAddr     = flag.String("pprof", "", "serve profiles from net/http/pprof on this address, such as :6060, labelling each part of the frame")
+	exposureStops = flag.Float64("exposure", 0, "brighten or darken the whole window by this many photographic stops")
+	brightness    = flag.Float64("brightness", 0, "lift or lower every colour in the window by this much, from -1 to 1")
+	contrast      = flag.Float64("contrast", 1, "stretch the colours in the window about mid grey by this much, 1 leaving them be")
+	keyPanSpeed   = flag.Float64("key-pan", 600, "pan the view at this many pixels per second while WASD or an arrow key is held")
+	edgePanSpeed  = flag.Float64("edge-pan", 0, "pan the view at up to this many pixels per second when the cursor is near the window edge, 0 to disable")
+	edgePanMargin = flag.Float64("edge-pan-margin", 32, "width in pixels of the band around the window edge that triggers edge panning")
+	solverName    = flag.String("solver", trees.DefaultOptions().Solver.Name, "solver profile to start with: fast, balanced or accurate")
+	subdivisions  = flag.Int("subdivisions", trees.DefaultOptions().Subdivisions, "straight pieces per span of the curves drawn by the path and ridge tools")
 )
 
-func loadPicture(path string) (pixel.Picture, error) {
-	file, err := os.Open(path)
+func sim() {
+
+	settings, err := loadConfig(*configPath)
 	if err != nil {
-		return nil, err
+		panic(err)
 	}
-	defer file.Close()
-	img, _, err := image.Decode(file)
+	settings.override()
+	post, err := postStages(settings.Post)
 	if err != nil {
-		return nil, err
+		panic(err)
 	}
-	return pixel.PictureDataFromImage(img), nil
-}
+	cfg := settings.Window.pixelgl()
+	win, err := pixelgl.NewWindow(cfg)
+	if err != nil {
+		panic(err)
+	}
+	win.SetSmooth(*smooth)
+
+	// Clean up properly however we exit
+	down := &shutdown{}
+	defer down.run()
+	closeOnInterrupt(func() { win.SetClosed(true) })
 
-func loadSprites() []*pixel.Sprite {
-	spritesheet, err := loadPicture("falling/trees.png")
+	// Create a world, using the middle tree sprite since it's big and fills
+	// the circular physics body nicely
+	prefs, err := loadPreferences(*prefsPath)
+	if err != nil {
+		panic(err)
+	}
+	mods, err := findMods(modsDir)
 	if err != nil {
 		panic(err)
 	}
-	var sprites []*pixel.Sprite
-	for x := spritesheet.Bounds().Min.X; x < spritesheet.Bounds().Max.X; x += 32 {
-		for y := spritesheet.Bounds().Min.Y; y < spritesheet.Bounds().Max.Y; y += 32 {
-			sprites = append(sprites, pixel.NewSprite(spritesheet, pixel.R(x, y, x+32, y+32)))
+	install(mods, prefs.disabled())
+	var saved *savedWorld
+	if *loadPath != "" {
+		s, err := loadSave(*loadPath)
+		if err != nil {
+			panic(err)
 		}
+		s.startFrom()
+		saved = &s
+	}
+	pack, err := loadPack(*assetsName)
+	if err != nil {
+		panic(err)
+	}
+	var watcher *assetWatcher
+	if *watchPack {
+		pack.Unchecked = true
+		watcher = watchAssets(pack)
+	}
+	sprites, err := treeSprites(pack, *seasonName)
+	if err != nil {
+		panic(err)
+	}
+	if *terrainSeed != 0 {
+		*levelName = trees.Hills(*terrainSeed).Name
+	}
+	if *levelFile != "" {
+		l, err := loadLevelFile(*levelFile)
+		if err != nil {
+			panic(err)
+		}
+		*levelName = l.Name
+	}
+	level, ok := trees.LookupLevel(*levelName)
+	if !ok {
+		panic(fmt.Errorf("unknown level %q", *levelName))
+	}
+	opts := trees.DefaultOptions()
+	opts.Level = level.Name
+	opts.Trees = level.Trees
+	opts.Sprites = sprites
+	opts.Subdivisions = *subdivisions
+	solver, ok := trees.LookupSolverProfile(*solverName)
+	if !ok {
+		panic(fmt.Errorf("unknown solver profile %q", *solverName))
+	}
+	opts.Solver = solver
+	settings.apply(&opts)
+	if *presetName != "" {
+		preset, ok := trees.LookupGravityPreset(*presetName)
+		if !ok {
+			panic(fmt.Errorf("unknown gravity preset %q", *presetName))
+		}
+		opts.Gravity, opts.TreeDamping = preset.Gravity, preset.Drag
+	}
+	// Re-simulate a replay if asked, starting the world exactly as the
+	// recorded one started
+	var again *rerun
+	if *replayPath != "" {
+		if *inputsPath != "" || *playPath != "" || saved != nil || *contraptions || *gameMode {
+			panic(fmt.Errorf("-replay can't be combined with -inputs, -play, -load, -contraption or -game"))
+		}
+		again, err = loadReplay(*replayPath)
+		if err != nil {
+			panic(err)
+		}
+		*seed = again.header.Seed
+	}
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+	log.Printf("scattering trees with -seed %d", *seed)
+	opts.Rand = rand.New(rand.NewSource(*seed))
+	opts.Lifetimes, err = parseLifetimes(*lifetimes)
+	if err != nil {
+		panic(err)
+	}
+	opts.MaxSpeeds, err = parseMaxSpeeds(*maxSpeeds)
+	if err != nil {
+		panic(err)
+	}
+	if *softGround {
+		opts.SoftGround = trees.SoftSoil
+	}
+	opts.Snowfall = *snowfall
+	opts.Seeding = *seeding
+	opts.Tide = trees.Tide{Period: *tidePeriod, Strength: *tideStrength, Swing: *tideSwing * math.Pi / 180}
+	if *contraptions && *gameMode {
+		panic(fmt.Errorf("-contraption can't be combined with -game"))
+	}
+	if *contraptions || *gameMode || saved != nil {
+		opts.Trees = 0
+	}
+	if again != nil {
+		again.header.apply(&opts)
+		again.limit = stepDuration(opts.MaxAdvance)
 	}
-	return sprites
-}
 
-func generateTree(world *box2d.B2World) *box2d.B2Body {
-	x := rand.Float64()*200 - 100
-	y := rand.Float64()*80 + 8
-	bodyDef := box2d.MakeB2BodyDef()
-	bodyDef.Type = box2d.B2BodyType.B2_dynamicBody
-	bodyDef.Position.Set(x, y)
-	bodyDef.LinearDamping = 0.02
-	body := world.CreateBody(&bodyDef)
-	dynamicBox := box2d.MakeB2CircleShape()
-	dynamicBox.SetRadius(1)
-	fixtureDef := box2d.MakeB2FixtureDef()
-	fixtureDef.Shape = &dynamicBox
-	fixtureDef.Density = 1
-	fixtureDef.Friction = 1
-	fixtureDef.Restitution = 0.4
-	body.CreateFixtureFromDef(&fixtureDef)
-	return body
-}
+	// Play back a recording instead of generating random trees if asked
+	var play *player
+	if *playPath != "" {
+		play, err = newPlayer(*playPath)
+		if err != nil {
+			panic(err)
+		}
+		opts.Trees = 0
+	}
+	world := trees.NewWorld(opts)
+	if saved != nil {
+		if err := saved.restore(world); err != nil {
+			panic(err)
+		}
+	}
 
-func createWorld() (*box2d.B2World, *imdraw.IMDraw) {
-	// Define the gravity vector.
-	gravity := box2d.MakeB2Vec2(0.0, -10.0)
-
-	// Construct a world object, which will hold and simulate the rigid bodies.
-	world := box2d.MakeB2World(gravity)
-
-	// Create the ground in the physics model
-	groundBodyDef := box2d.MakeB2BodyDef()
-	groundBodyDef.Position.Set(0, 0)
-	groundBody := world.CreateBody(&groundBodyDef)
-
-	groundTriangle := box2d.MakeB2PolygonShape()
-	vertices := []box2d.B2Vec2{
-		box2d.MakeB2Vec2(10, 1),
-		box2d.MakeB2Vec2(0, 10),
-		box2d.MakeB2Vec2(-10, 1),
-	}
-	groundTriangle.Set(vertices, len(vertices))
-	groundBody.CreateFixture(&groundTriangle, 0.0)
-
-	groundBase := box2d.MakeB2PolygonShape()
-	groundBase.SetAsBox(50, 1)
-	groundBody.CreateFixture(&groundBase, 0.0)
-
-	// Draw the ground directly
-	imd := imdraw.New(nil)
-	imd.Color = colornames.Sandybrown
-	for _, v := range vertices {
-		imd.Push(pixel.V(v.X, v.Y).Scaled(32))
-	}
-	imd.Polygon(0)
-	imd.Push(
-		pixel.V(50, 1).Scaled(32),
-		pixel.V(-50, -1).Scaled(32),
-	)
-	imd.Rectangle(0)
-
-	return &world, imd
-}
+	// Record what's done to the world for a replay if asked
+	var inputs *inputRecorder
+	if *inputsPath != "" {
+		if *playPath != "" || saved != nil || *contraptions {
+			panic(fmt.Errorf("-inputs can't be combined with -play, -load or -contraption"))
+		}
+		inputs, err = newInputRecorder(*inputsPath, newReplayHeader(*seed, opts))
+		if err != nil {
+			panic(err)
+		}
+		down.register("inputs", inputs.close)
+	}
 
-func sim() {
+	// Record the session if asked
+	var rec *recorder
+	if *recordPath != "" {
+		rec, err = newRecorder(*recordPath)
+		if err != nil {
+			panic(err)
+		}
+		down.register("recorder", rec.close)
+	}
+	var clip *capture
+	if *gifPath != "" {
+		clip, err = newCapture(*gifPath)
+		if err != nil {
+			panic(err)
+		}
+	}
 
-	cfg := pixelgl.WindowConfig{
-		Title:  "Pixel Rocks!",
-		Bounds: pixel.R(0, 0, 1024, 768),
-		VSync:  true,
+	// Pair up with another instance if asked
+	var guest, host *remote
+	if *pairHost != "" {
+		guest, err = hostRemote(*pairHost, iceServers(*pairICE))
+		if err != nil {
+			panic(err)
+		}
+		down.register("pairing", guest.close)
 	}
-	win, err := pixelgl.NewWindow(cfg)
-	if err != nil {
-		panic(err)
+	if *pairConnect != "" {
+		host, err = connectRemote(*pairConnect, iceServers(*pairICE))
+		if err != nil {
+			panic(err)
+		}
+		down.register("pairing", host.close)
 	}
 
-	// Create a world
-	sprites := loadSprites()
-	world, drawableWorld := createWorld()
+	a := &app{
+		title:     cfg.Title,
+		win:       win,
+		world:     world,
+		clock:     &clock{scale: *timeScale, step: stepDuration(opts.FixedStep)},
+		cam:       camera.Camera{Pos: pixel.V(1024/2, 0), Zoom: settings.Zoom},
+		marks:     &bookmarks{},
+		pan:       &panner{inertia: *inertia},
+		demo:      &attract{idleAfter: *attractAfter},
+		pip:       newPictureInPicture(),
+		track:     &follower{},
+		repose:    newReposeOverlay(),
+		density:   newDensityOverlay(),
+		debug:     newDebugOverlay(),
+		outline:   newHighlighter(),
+		landed:    newLandingHistogram(),
+		waves:     newWaveTable(*waveSize),
+		tutor:     newTutorial(*tutorialMode),
+		energy:    newEnergyMonitor(),
+		minimap:   newMinimap(),
+		stats:     newStatsHUD(),
+		expose:    newExposure(*exposureStops, *brightness, *contrast),
+		post:      post,
+		scene:     *scenePath,
+		save:      *savePath,
+		inputs:    inputs,
+		assets:    *assetsName,
+		season:    *seasonName,
+		bins:      newBinOverlay(),
+		chain:     newChainLabel(),
+		forces:    newForceOverlay(),
+		mods:      newModBrowser(mods, &prefs, *prefsPath),
+		prefs:     &prefs,
+		saved:     *prefsPath,
+		opts:      opts,
+		seed:      *seed,
+		treeCount: settings.Trees,
+		rec:       rec,
+		clip:      clip,
+		clips:     *capturePath,
+		shots:     *shotsPath,
+		watcher:   watcher,
+		guest:     guest,
+		host:      host,
+		down:      down,
+		tools:     trees.Tools(),
+	}
+	down.register("capture", a.stopCapture)
+	if *landingsPath != "" {
+		down.register("landings", func() error { return writeLandings(a.world, *landingsPath) })
+	}
+	if *pixelSnap {
+		a.texel = 2 * trees.TreeRadius * trees.PixelsPerMetre / sprites[0].Sprite.Frame().W()
+	}
+	a.pip.canvas.SetSmooth(*smooth)
+	a.pip.watch(world.Events())
+	a.tutor.watch(world.Events())
+	a.energy.watch(world)
+	watchHitches(world.Events())
+	// Start out spawning trees, or pushing things over on levels without trees
+	first := "spawn tree"
+	if level.Trees == 0 && !*contraptions {
+		first = "push"
+	}
+	for i, t := range a.tools {
+		if t.Name() == first {
+			a.tool = i
+		}
+	}
+	win.SetTitle(cfg.Title + " | " + a.tools[a.tool].Name())
 
-	// Generate random trees
-	trees := []*box2d.B2Body{}
-	for i := 0; i < 800; i++ {
-		// Use the middle tree sprite since it's big and fills circular physics body nicely
-		trees = append(trees, generateTree(world))
+	// Start at the menu, or on whichever screen the flags ask for, leaving it
+	// cleanly on exit
+	a.menu = newMenuScreen(level.Name, *blueprintPath, *gameTrees)
+	var start screen = &sandboxScreen{}
+	switch {
+	case !skipMenu():
+		start = a.menu
+	case play != nil || again != nil:
+		start = &replayScreen{play: play, rerun: again}
+	case *contraptions:
+		start = &editorScreen{path: *blueprintPath}
+	case *gameMode:
+		start = newGameScreen(*gameTrees)
+	}
+	a.switchTo(start)
+	if err := a.changeScreen(); err != nil {
+		panic(err)
 	}
+	down.register("screen", a.leave)
 
-	camZoom := 0.4
-	camPos := pixel.V(1024/2, 0)
+	pace := &pacer{on: *snapFrames}
+	away, err := newBackground(settings.Unfocused)
+	if err != nil {
+		panic(err)
+	}
 	lastTime := time.Now()
-	treeSprite := sprites[4] // Big tree that fills the physics body nicely
 	for !win.Closed() {
 
-		// We're v-synced so calculate the time elapsed since the last frame and step the simulation that far
+		// Calculate the time elapsed since the last frame, evened out to the
+		// display's refreshes, and run everything that makes up a frame
 		currentTime := time.Now()
-		dt := currentTime.Sub(lastTime)
+		dt := away.elapsed(win, pace.pace(win, currentTime.Sub(lastTime)))
 		lastTime = currentTime
-		world.Step(dt.Seconds(), velocityIterations, positionIterations)
-
-		// Check the mouse wheel to determine camera position
-		camZoom *= math.Pow(camZoomSpeed, win.MouseScroll().Y)
-		cam := pixel.IM.Scaled(pixel.ZV, camZoom).Moved(camPos)
-		if win.Pressed(pixelgl.MouseButtonLeft) {
-			camPos = win.MousePosition()
+		frame, endFrame := context.Background(), func() {}
+		if a.sched.profiled {
+			frame, endFrame = frameTask()
 		}
-		win.SetMatrix(cam)
-
-		// Draw the world and trees
-		win.Clear(colornames.Whitesmoke)
-		drawableWorld.Draw(win)
-		for _, tree := range trees {
-
-			// Physics X and Y which are in metres
-			x := tree.GetPosition().X
-			y := tree.GetPosition().Y
-
-			// Determine the position on screen by scaling so that we get 32 pixels to the metre
-			pos := pixel.V(x, y).Scaled(32)
-
-			// Draw a tree sprite for this physics body
-			treeSprite.Draw(win, pixel.IM.Scaled(pixel.ZV, 2).Moved(pos))
-
+		a.sched.runFrame(frame, dt)
+		a.sched.within(frame, "window", "update", win.Update)
+		a.sched.within(frame, "window", "wait", func() { away.wait(win) })
+		endFrame()
+		if err := a.changeScreen(); err != nil {
+			panic(err)
 		}
-		win.Update()
 
 	}
 
 }
 
 func main() {
+	flag.Parse()
+	if *pprofAddr != "" {
+		servePprof(*pprofAddr)
+	}
 	pixelgl.Run(sim)
 }
@@ -0,0 +1,340 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"github.com/faiface/pixel/pixelgl"
+	"github.com/faiface/pixel/text"
+	"github.com/scottyw/falling-trees/trees"
+	"golang.org/x/image/colornames"
+	"golang.org/x/image/font/basicfont"
+)
+
+// Layout of the menu in pixels
+const (
+	menuRowHeight    = 24
+	menuPadding      = 16
+	menuColumnWidth  = 220
+	menuSavesWidth   = 300
+	menuHeaderHeight = 56
+)
+
+// Columns of the menu
+const (
+	menuLevels = iota
+	menuModes
+	menuSaves
+	menuColumns
+)
+
+var menuModeNames = []string{"sandbox", "game", "editor"}
+
+// menuScreen is where the demo starts, with the world it would otherwise
+// have dropped straight into running behind it. Pick a level and a mode and
+// start, or carry on from one of the recent saves. Arrow keys move around the
+// columns and Enter starts whatever is chosen, or click the level and mode
+// and then Start, or click a save to load it.
+type menuScreen struct {
+	levels    []trees.Level
+	chosen    [menuColumns]int
+	focus     int
+	err       error // Why the last choice couldn't be started, if it couldn't
+	label     *text.Text
+	ui        *imdraw.IMDraw
+	blueprint string
+	game      int
+}
+
+func newMenuScreen(level string, blueprint string, game int) *menuScreen {
+	label := text.New(pixel.ZV, text.NewAtlas(basicfont.Face7x13, text.ASCII))
+	m := &menuScreen{label: label, ui: imdraw.New(nil), blueprint: blueprint, game: game}
+	for i, l := range trees.Levels() {
+		m.levels = append(m.levels, l)
+		if l.Name == level {
+			m.chosen[menuLevels] = i
+		}
+	}
+	return m
+}
+
+// skipMenu reports whether the command line already says what to play, in
+// which case the demo starts playing it straight away
+func skipMenu() bool {
+	if !*showMenu {
+		return true
+	}
+	skip := false
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "level", "level-file", "terrain-seed", "load", "play", "replay", "inputs", "contraption", "game", "blueprint":
+			skip = true
+		}
+	})
+	return skip
+}
+
+func (m *menuScreen) name() string { return "menu" }
+
+// systems keep the world running and drawn behind the menu, but none of the
+// controls for it
+func (m *menuScreen) systems(a *app) {
+	a.sched.add(phaseInput, "menu", func(dt time.Duration) { m.update(a) })
+	a.sched.add(phaseInput, "clock", a.tick)
+	a.sched.add(phasePhysics, "step", a.advance)
+	a.sched.add(phasePostPhysics, "animate", a.animate)
+	a.sched.add(phaseRender, "scene", a.drawScene)
+	a.sched.add(phaseRender, "post", a.postProcess)
+	a.sched.add(phaseUI, "menu", func(dt time.Duration) { m.draw(a.win, a.prefs.RecentSaves) })
+	a.sched.add(phaseUI, "assets", a.reloadAssets)
+	a.sched.add(phaseUI, "exposure", a.adjustExposure)
+	a.sched.add(phaseUI, "capture", a.capture)
+	a.sched.add(phaseUI, "screenshot", a.screenshot)
+}
+
+// enter looks for levels again, since mods may have been switched on or off
+func (m *menuScreen) enter(a *app) error {
+	var chosen string
+	if len(m.levels) > 0 {
+		chosen = m.levels[m.chosen[menuLevels]].Name
+	}
+	m.levels = trees.Levels()
+	m.chosen[menuLevels] = 0
+	for i, l := range m.levels {
+		if l.Name == chosen {
+			m.chosen[menuLevels] = i
+		}
+	}
+	// Saving puts the save at the top of the list
+	m.chosen[menuSaves] = 0
+	m.err = nil
+	return nil
+}
+
+func (m *menuScreen) exit(a *app) error { return nil }
+
+func (m *menuScreen) allow(c command) bool { return false }
+
+// rows is how many rows there are in a column
+func (m *menuScreen) rows(column int, saves []string) int {
+	switch column {
+	case menuLevels:
+		return len(m.levels)
+	case menuModes:
+		return len(menuModeNames)
+	}
+	return len(saves)
+}
+
+// panel is where the menu is drawn in window coordinates
+func (m *menuScreen) panel(bounds pixel.Rect, saves []string) pixel.Rect {
+	rows := len(m.levels)
+	if len(saves) > rows {
+		rows = len(saves)
+	}
+	// Leave room for the start button under the modes
+	if len(menuModeNames)+2 > rows {
+		rows = len(menuModeNames) + 2
+	}
+	w := float64(2*menuColumnWidth + menuSavesWidth + 2*menuPadding)
+	h := float64(rows)*menuRowHeight + 2*menuPadding + menuHeaderHeight
+	c := bounds.Center()
+	return pixel.R(c.X-w/2, c.Y-h/2, c.X+w/2, c.Y+h/2)
+}
+
+// row is the area of the i'th row of a column
+func (m *menuScreen) row(bounds pixel.Rect, saves []string, column, i int) pixel.Rect {
+	p := m.panel(bounds, saves)
+	left := p.Min.X + menuPadding + float64(column)*menuColumnWidth
+	right := left + menuColumnWidth
+	if column == menuSaves {
+		right = left + menuSavesWidth
+	}
+	top := p.Max.Y - menuPadding - menuHeaderHeight - float64(i)*menuRowHeight
+	return pixel.R(left, top-menuRowHeight, right-menuPadding, top)
+}
+
+// start is the area of the start button, under the modes
+func (m *menuScreen) start(bounds pixel.Rect, saves []string) pixel.Rect {
+	return m.row(bounds, saves, menuModes, len(menuModeNames)+1)
+}
+
+func (m *menuScreen) update(a *app) {
+	saves := a.prefs.RecentSaves
+	win := a.win
+	switch {
+	case win.JustPressed(pixelgl.KeyLeft):
+		m.focus = (m.focus + menuColumns - 1) % menuColumns
+	case win.JustPressed(pixelgl.KeyRight):
+		m.focus = (m.focus + 1) % menuColumns
+	case win.JustPressed(pixelgl.KeyUp) || win.Repeated(pixelgl.KeyUp):
+		if rows := m.rows(m.focus, saves); rows > 0 {
+			m.chosen[m.focus] = (m.chosen[m.focus] + rows - 1) % rows
+		}
+	case win.JustPressed(pixelgl.KeyDown) || win.Repeated(pixelgl.KeyDown):
+		if rows := m.rows(m.focus, saves); rows > 0 {
+			m.chosen[m.focus] = (m.chosen[m.focus] + 1) % rows
+		}
+	case win.JustPressed(pixelgl.KeyEnter):
+		if m.focus == menuSaves && len(saves) > 0 {
+			m.err = m.load(a, saves[m.chosen[menuSaves]])
+		} else {
+			m.err = m.play(a)
+		}
+	case win.JustPressed(pixelgl.MouseButtonLeft):
+		mouse := win.MousePosition()
+		if m.start(win.Bounds(), saves).Contains(mouse) {
+			m.err = m.play(a)
+		}
+		for column := 0; column < menuColumns; column++ {
+			for i := 0; i < m.rows(column, saves); i++ {
+				if !m.row(win.Bounds(), saves, column, i).Contains(mouse) {
+					continue
+				}
+				m.focus, m.chosen[column] = column, i
+				if column == menuSaves {
+					m.err = m.load(a, saves[i])
+				}
+			}
+		}
+	}
+}
+
+// play builds a new world of the chosen level and switches to the chosen mode
+func (m *menuScreen) play(a *app) error {
+	if len(m.levels) == 0 {
+		return fmt.Errorf("there are no levels to play")
+	}
+	level := m.levels[m.chosen[menuLevels]]
+	var next screen = &sandboxScreen{}
+	switch menuModeNames[m.chosen[menuModes]] {
+	case "game":
+		next = newGameScreen(m.game)
+	case "editor":
+		next = &editorScreen{path: m.blueprint}
+	}
+	// Games and contraptions start without any trees of their own
+	a.setWorld(a.newWorld(level, m.chosen[menuModes] == 0))
+	a.switchTo(next)
+	return nil
+}
+
+// load carries on with a save in the sandbox
+func (m *menuScreen) load(a *app, location string) error {
+	saved, err := loadSave(location)
+	if err != nil {
+		return err
+	}
+	level, ok := trees.LookupLevel(saved.snapshot.Level)
+	if !ok {
+		return fmt.Errorf("%s is of unknown level %q", location, saved.snapshot.Level)
+	}
+	world := a.newWorld(level, false)
+	if err := saved.restore(world); err != nil {
+		return err
+	}
+	a.setWorld(world)
+	a.switchTo(&sandboxScreen{})
+	return nil
+}
+
+func (m *menuScreen) draw(win *pixelgl.Window, saves []string) {
+	bounds := win.Bounds()
+	panel := m.panel(bounds, saves)
+	m.ui.Clear()
+	m.ui.Color = pixel.RGBA{R: 1, G: 1, B: 1, A: 0.9}
+	m.ui.Push(panel.Min, panel.Max)
+	m.ui.Rectangle(0)
+	for column := 0; column < menuColumns; column++ {
+		if m.rows(column, saves) == 0 {
+			continue
+		}
+		r := m.row(bounds, saves, column, m.chosen[column])
+		m.ui.Color = pixel.ToRGBA(colornames.Lightsteelblue)
+		if column == m.focus {
+			m.ui.Color = pixel.ToRGBA(colornames.Cornflowerblue)
+		}
+		m.ui.Push(r.Min, r.Max)
+		m.ui.Rectangle(0)
+	}
+	m.ui.Color = colornames.Black
+	start := m.start(bounds, saves)
+	m.ui.Push(start.Min, start.Max)
+	m.ui.Rectangle(2)
+	win.SetMatrix(pixel.IM)
+	m.ui.Draw(win)
+
+	m.label.Clear()
+	m.label.Color = colornames.Black
+	fmt.Fprintln(m.label, "Falling trees")
+	if m.err != nil {
+		fmt.Fprintf(m.label, "%v", m.err)
+	} else {
+		fmt.Fprint(m.label, "Arrow keys to choose and Enter to start, or click. Escape comes back here.")
+	}
+	m.label.Draw(win, pixel.IM.Moved(pixel.V(panel.Min.X+menuPadding, panel.Max.Y-menuPadding-m.label.LineHeight)))
+
+	texts := [menuColumns][]string{menuLevels: nil, menuModes: menuModeNames, menuSaves: saves}
+	for _, l := range m.levels {
+		texts[menuLevels] = append(texts[menuLevels], l.Name)
+	}
+	headings := [menuColumns]string{"Level", "Mode", "Recent saves"}
+	for column, rows := range texts {
+		heading := m.row(bounds, saves, column, -1)
+		m.label.Clear()
+		fmt.Fprint(m.label, headings[column])
+		m.label.Draw(win, pixel.IM.Moved(pixel.V(heading.Min.X+4, heading.Min.Y+6)))
+		if column == menuSaves && len(rows) == 0 {
+			rows = []string{"none yet, Ctrl+S saves"}
+		}
+		for i, s := range rows {
+			r := m.row(bounds, saves, column, i)
+			m.label.Clear()
+			fmt.Fprint(m.label, s)
+			m.label.Draw(win, pixel.IM.Moved(pixel.V(r.Min.X+4, r.Min.Y+6)))
+		}
+	}
+	m.label.Clear()
+	fmt.Fprint(m.label, "Start")
+	m.label.Draw(win, pixel.IM.Moved(pixel.V(start.Center().X-m.label.Bounds().W()/2, start.Min.Y+6)))
+}
+
+// newWorld builds a world of a level with the same options as the world the
+// demo started with, scattering the level's trees over it or not
+func (a *app) newWorld(level trees.Level, scatter bool) *trees.World {
+	opts := a.opts
+	opts.Level = level.Name
+	opts.Trees = 0
+	if scatter {
+		opts.Trees = level.Trees
+		if a.treeCount != nil {
+			opts.Trees = *a.treeCount
+		}
+	}
+	opts.Rand = rand.New(rand.NewSource(a.seed))
+	return trees.NewWorld(opts)
+}
+
+// setWorld swaps in a new world and lets go of anything tied to the old one
+func (a *app) setWorld(world *trees.World) {
+	a.world = world
+	a.steps = 0
+	a.pip.target = nil
+	a.track.target = nil
+	a.pip.watch(world.Events())
+	a.tutor.watch(world.Events())
+	a.energy.watch(world)
+	watchHitches(world.Events())
+}
+
+// Go back to the menu with Escape, unless the world is being recorded for a
+// replay, which has to stay in the world it started with, or isn't live
+func (a *app) backToMenu(dt time.Duration) {
+	if a.win.JustPressed(pixelgl.KeyEscape) && a.inputs == nil && a.live() {
+		a.switchTo(a.menu)
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"github.com/faiface/pixel/pixelgl"
+	"github.com/scottyw/falling-trees/camera"
+	"github.com/scottyw/falling-trees/trees"
+	"golang.org/x/image/colornames"
+)
+
+const (
+	// Cells across and up the minimap's grid of the world
+	minimapCells = 84
+
+	// Width of the minimap in pixels
+	minimapWidth = 168
+)
+
+// Colours of what fills each cell, by trees.Occupant
+var minimapColours = []pixel.RGBA{
+	trees.OccupantGround: pixel.ToRGBA(colornames.Sandybrown),
+	trees.OccupantTree:   pixel.ToRGBA(colornames.Forestgreen),
+	trees.OccupantEntity: pixel.ToRGBA(colornames.Royalblue),
+}
+
+// minimap shows the whole world in the bottom left corner as the occupancy
+// grid the world rasterizes every step, with the part of it on screen
+// outlined
+type minimap struct {
+	shown bool
+	grid  *trees.Occupancy
+	cells *imdraw.IMDraw
+}
+
+func newMinimap() *minimap {
+	return &minimap{
+		grid:  trees.NewOccupancy(trees.Bounds, minimapCells, minimapCells),
+		cells: imdraw.New(nil),
+	}
+}
+
+// draw toggles the minimap with M, having the world rasterize its grid only
+// while it's shown
+func (m *minimap) draw(win *pixelgl.Window, cam camera.Camera, world *trees.World) {
+	if win.JustPressed(pixelgl.KeyM) {
+		m.shown = !m.shown
+	}
+	if !m.shown {
+		if world.Occupancy() == m.grid {
+			world.SetOccupancy(nil)
+		}
+		return
+	}
+	if world.Occupancy() != m.grid {
+		world.SetOccupancy(m.grid)
+	}
+
+	// Map metres onto the panel, keeping the world's shape
+	bottom := win.Bounds().Min.Y + 64
+	scale := minimapWidth / m.grid.Area.W()
+	panel := pixel.R(8, bottom, 8+minimapWidth, bottom+m.grid.Area.H()*scale)
+	onPanel := func(p pixel.Vec) pixel.Vec {
+		return panel.Min.Add(p.Sub(m.grid.Area.Min).Scaled(scale))
+	}
+
+	m.cells.Clear()
+	m.cells.Color = pixel.RGBA{R: 1, G: 1, B: 1, A: 0.8}
+	m.cells.Push(panel.Min, panel.Max)
+	m.cells.Rectangle(0)
+	size := m.grid.CellSize()
+	for row := 0; row < m.grid.Rows; row++ {
+		for column := 0; column < m.grid.Columns; column++ {
+			kind := m.grid.At(column, row)
+			if kind == trees.OccupantNone {
+				continue
+			}
+			min := m.grid.Area.Min.Add(pixel.V(float64(column)*size.X, float64(row)*size.Y))
+			m.cells.Color = minimapColours[kind]
+			m.cells.Push(onPanel(min), onPanel(min.Add(size)))
+			m.cells.Rectangle(0)
+		}
+	}
+
+	// Outline what's on screen, cut down to the world
+	view := cam.View(win.Bounds())
+	if shown := view.Intersect(m.grid.Area); shown.Area() > 0 {
+		m.cells.Color = colornames.Dimgray
+		m.cells.Push(onPanel(shown.Min), onPanel(shown.Max))
+		m.cells.Rectangle(1)
+	}
+	m.cells.Color = colornames.Dimgray
+	m.cells.Push(panel.Min, panel.Max)
+	m.cells.Rectangle(1)
+
+	win.SetMatrix(pixel.IM)
+	m.cells.Draw(win)
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"github.com/faiface/pixel/pixelgl"
+	"github.com/faiface/pixel/text"
+	"golang.org/x/image/colornames"
+	"golang.org/x/image/font/basicfont"
+)
+
+// Layout of the mod browser in pixels
+const (
+	modRowHeight = 36
+	modPadding   = 16
+	modWidth     = 560
+)
+
+// modBrowser lists the mods in modsDir over the middle of the window, with a
+// box to tick for each one to switch it on or off. Opening the browser looks
+// for mods again, so mods dropped in while the demo is running show up, and
+// closing it installs whichever are switched on and saves the choice in the
+// preferences. The world carries on with the level it was built on, and a
+// different asset pack takes effect next time the demo starts.
+type modBrowser struct {
+	open     bool
+	mods     []*mod
+	disabled map[string]bool
+	changed  bool
+	err      error // Why the mods couldn't be found again, if they couldn't
+	prefs    *preferences
+	path     string // Where the preferences are saved
+	label    *text.Text
+	ui       *imdraw.IMDraw
+}
+
+func newModBrowser(mods []*mod, prefs *preferences, path string) *modBrowser {
+	label := text.New(pixel.ZV, text.NewAtlas(basicfont.Face7x13, text.ASCII))
+	return &modBrowser{
+		mods:     mods,
+		disabled: prefs.disabled(),
+		prefs:    prefs,
+		path:     path,
+		label:    label,
+		ui:       imdraw.New(nil),
+	}
+}
+
+// panel is where the browser is drawn in window coordinates
+func (b *modBrowser) panel(bounds pixel.Rect) pixel.Rect {
+	rows := len(b.mods)
+	if rows == 0 {
+		rows = 1
+	}
+	h := float64(rows)*modRowHeight + 2*modPadding + modRowHeight
+	c := bounds.Center()
+	return pixel.R(c.X-modWidth/2, c.Y-h/2, c.X+modWidth/2, c.Y+h/2)
+}
+
+// row is the area of the i'th mod in the list
+func (b *modBrowser) row(bounds pixel.Rect, i int) pixel.Rect {
+	p := b.panel(bounds)
+	top := p.Max.Y - modPadding - modRowHeight - float64(i)*modRowHeight
+	return pixel.R(p.Min.X+modPadding, top-modRowHeight, p.Max.X-modPadding, top)
+}
+
+// update opens and closes the browser with F9 and switches mods on and off
+// with clicks. It reports whether the browser is taking the mouse.
+func (b *modBrowser) update(win *pixelgl.Window) bool {
+	if win.JustPressed(pixelgl.KeyF9) {
+		if b.open {
+			b.close()
+		} else {
+			b.rescan()
+		}
+		b.open = !b.open
+	}
+	if !b.open {
+		return false
+	}
+	if win.JustPressed(pixelgl.MouseButtonLeft) {
+		for i, m := range b.mods {
+			if b.row(win.Bounds(), i).Contains(win.MousePosition()) {
+				b.disabled[m.name] = !b.disabled[m.name]
+				b.changed = true
+			}
+		}
+	}
+	return true
+}
+
+// rescan looks for mods again, keeping the ones already loaded if any of
+// them are broken
+func (b *modBrowser) rescan() {
+	mods, err := findMods(modsDir)
+	b.err = err
+	if err == nil {
+		b.mods = mods
+	}
+}
+
+// close installs the mods that are switched on and saves the choice, if it
+// changed
+func (b *modBrowser) close() {
+	install(b.mods, b.disabled)
+	if !b.changed {
+		return
+	}
+	b.prefs.DisabledMods = nil
+	for name, off := range b.disabled {
+		if off {
+			b.prefs.DisabledMods = append(b.prefs.DisabledMods, name)
+		}
+	}
+	sort.Strings(b.prefs.DisabledMods)
+	if err := b.prefs.save(b.path); err != nil {
+		b.err = err
+		return
+	}
+	b.changed = false
+}
+
+func (b *modBrowser) draw(win *pixelgl.Window) {
+	if !b.open {
+		return
+	}
+	bounds := win.Bounds()
+	panel := b.panel(bounds)
+	b.ui.Clear()
+	b.ui.Color = pixel.RGBA{R: 1, G: 1, B: 1, A: 0.9}
+	b.ui.Push(panel.Min, panel.Max)
+	b.ui.Rectangle(0)
+	b.ui.Color = colornames.Black
+	for i, m := range b.mods {
+		box := b.row(bounds, i)
+		tick := pixel.R(box.Min.X, box.Center().Y-8, box.Min.X+16, box.Center().Y+8)
+		b.ui.Push(tick.Min, tick.Max)
+		b.ui.Rectangle(2)
+		if !b.disabled[m.name] {
+			b.ui.Push(tick.Min.Add(pixel.V(4, 4)), tick.Max.Sub(pixel.V(4, 4)))
+			b.ui.Rectangle(0)
+		}
+	}
+	win.SetMatrix(pixel.IM)
+	b.ui.Draw(win)
+
+	b.label.Clear()
+	b.label.Color = colornames.Black
+	title := panel.Max.Sub(pixel.V(modWidth-modPadding, modPadding+b.label.LineHeight))
+	switch {
+	case b.err != nil:
+		fmt.Fprintf(b.label, "%v", b.err)
+	case len(b.mods) == 0:
+		fmt.Fprintf(b.label, "No mods in %s/, F9 to close", modsDir)
+	default:
+		fmt.Fprint(b.label, "Mods, click to switch on or off, F9 to close")
+	}
+	b.label.Draw(win, pixel.IM.Moved(title))
+	for i, m := range b.mods {
+		box := b.row(bounds, i)
+		b.label.Clear()
+		fmt.Fprintf(b.label, "%s, %d levels", m.info.Title, len(m.levels))
+		if m.pack != nil {
+			fmt.Fprint(b.label, " and an asset pack")
+		}
+		if m.info.Description != "" {
+			fmt.Fprintf(b.label, "\n%s", m.info.Description)
+		}
+		b.label.Draw(win, pixel.IM.Moved(pixel.V(box.Min.X+28, box.Max.Y-b.label.LineHeight-2)))
+	}
+}
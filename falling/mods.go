@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/scottyw/falling-trees/assets"
+	"github.com/scottyw/falling-trees/trees"
+)
+
+// modsDir is where mods are dropped, each as a directory or a zip archive
+const modsDir = "mods"
+
+// mod is a bundle of extra content found in modsDir. Levels are JSON files
+// in its levels directory, and a mod with a manifest.json is an asset pack
+// too, chosen with -assets and the mod's name.
+type mod struct {
+	name   string
+	info   modInfo
+	levels []trees.Level
+	pack   *assets.Pack
+}
+
+// modInfo is the optional mod.json describing a mod
+type modInfo struct {
+	Version     int    `json:"version"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+var modFormat = trees.Format{Name: "mod"}
+
+// findMods loads every mod in a directory, in order of name. A mod that
+// can't be loaded stops the demo rather than leaving it half there.
+func findMods(dir string) ([]*mod, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var mods []*mod
+	for _, entry := range entries {
+		p := filepath.Join(dir, entry.Name())
+		var m *mod
+		switch {
+		case entry.IsDir():
+			m, err = loadMod(entry.Name(), assets.Dir(p))
+		case strings.HasSuffix(entry.Name(), ".zip"):
+			var z *assets.Zip
+			if z, err = assets.OpenZip(p); err == nil {
+				m, err = loadMod(strings.TrimSuffix(entry.Name(), ".zip"), z)
+			}
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("mod %s: %v", entry.Name(), err)
+		}
+		mods = append(mods, m)
+	}
+	sort.Slice(mods, func(i, j int) bool { return mods[i].name < mods[j].name })
+	return mods, nil
+}
+
+func loadMod(name string, files assets.Files) (*mod, error) {
+	m := &mod{name: name, info: modInfo{Title: name}}
+	names, err := files.Names()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+	for _, file := range names {
+		switch {
+		case file == "mod.json":
+			data, err := files.Read(file)
+			if err != nil {
+				return nil, err
+			}
+			if err := modFormat.Decode(bytes.NewReader(data), &m.info); err != nil {
+				return nil, fmt.Errorf("%s: %v", file, err)
+			}
+		case file == "manifest.json":
+			if m.pack, err = assets.Open(name, files); err != nil {
+				return nil, err
+			}
+		case path.Dir(file) == "levels" && path.Ext(file) == ".json":
+			data, err := files.Read(file)
+			if err != nil {
+				return nil, err
+			}
+			level, err := trees.ReadLevel(bytes.NewReader(data))
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", file, err)
+			}
+			m.levels = append(m.levels, level)
+		}
+	}
+	return m, nil
+}
+
+// installed are the mods whose levels and asset packs are in the registries,
+// and builtin are the levels there were before any mods were installed, for
+// putting back any that a mod replaced
+var (
+	installed []*mod
+	builtin   map[string]trees.Level
+)
+
+// install replaces whatever mods are installed with the mods that aren't
+// disabled, adding their levels and asset packs to the registries
+func install(mods []*mod, disabled map[string]bool) {
+	if builtin == nil {
+		builtin = map[string]trees.Level{}
+		for _, l := range trees.Levels() {
+			builtin[l.Name] = l
+		}
+	}
+	uninstall()
+	for _, m := range mods {
+		if disabled[m.name] {
+			continue
+		}
+		for _, l := range m.levels {
+			trees.RegisterLevel(l)
+		}
+		if m.pack != nil {
+			packs[m.name] = m.pack
+		}
+		installed = append(installed, m)
+		log.Printf("loaded mod %s: %s, with %d levels", m.name, m.info.Title, len(m.levels))
+	}
+}
+
+func uninstall() {
+	for _, m := range installed {
+		for _, l := range m.levels {
+			trees.UnregisterLevel(l.Name)
+			if b, ok := builtin[l.Name]; ok {
+				trees.RegisterLevel(b)
+			}
+		}
+		delete(packs, m.name)
+	}
+	installed = nil
+}
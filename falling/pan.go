@@ -0,0 +1,64 @@
+package main
+
+import (
+	"math"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/pixelgl"
+	"github.com/scottyw/falling-trees/camera"
+)
+
+const (
+	// How quickly the camera glide dies away after a drag is released. The
+	// velocity falls by a factor of e every 1/panFriction seconds.
+	panFriction = 4.0
+
+	// Glides slower than this, in pixels per second, are stopped outright
+	panMinSpeed = 5.0
+)
+
+// panner drags the camera around with the left mouse button and, if inertia
+// is enabled, lets it keep gliding for a while once the button is released
+type panner struct {
+	inertia  bool
+	dragging bool
+	last     pixel.Vec
+	velocity pixel.Vec
+}
+
+// update moves the camera and reports whether the user is dragging it
+func (p *panner) update(win *pixelgl.Window, cam *camera.Camera, dt float64) bool {
+	mouse := win.MousePosition()
+	switch {
+	case win.JustPressed(pixelgl.MouseButtonLeft):
+		p.dragging = true
+		p.last = mouse
+		p.velocity = pixel.ZV
+	case win.Pressed(pixelgl.MouseButtonLeft) && p.dragging:
+		delta := mouse.Sub(p.last)
+		p.last = mouse
+		cam.Pos = cam.Pos.Add(delta)
+		// Average the velocity over a few frames so that a single
+		// stationary frame before letting go doesn't kill the flick
+		if dt > 0 {
+			p.velocity = pixel.Lerp(p.velocity, delta.Scaled(1/dt), 0.5)
+		}
+	case p.dragging:
+		p.dragging = false
+		if !p.inertia {
+			p.velocity = pixel.ZV
+		}
+	default:
+		cam.Pos = cam.Pos.Add(p.velocity.Scaled(dt))
+		p.velocity = p.velocity.Scaled(math.Exp(-panFriction * dt))
+		if p.velocity.Len() < panMinSpeed {
+			p.velocity = pixel.ZV
+		}
+	}
+	return p.dragging
+}
+
+// stop kills any glide in progress
+func (p *panner) stop() {
+	p.velocity = pixel.ZV
+}
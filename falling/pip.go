@@ -0,0 +1,92 @@
+package main
+
+import (
+	"github.com/ByteArena/box2d"
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"github.com/faiface/pixel/pixelgl"
+	"github.com/scottyw/falling-trees/camera"
+	"github.com/scottyw/falling-trees/trees"
+	"golang.org/x/image/colornames"
+)
+
+const (
+	// Zoom used inside the picture-in-picture view
+	pipZoom = 1.0
+
+	// Gap between the picture-in-picture view and the edge of the window
+	pipMargin = 16
+
+	// How far from the cursor in metres to look for something to follow
+	pipPickRadius = 5.0
+)
+
+// Size of the picture-in-picture view
+var pipBounds = pixel.R(0, 0, 256, 192)
+
+// pictureInPicture renders a small view locked onto a single tree in the
+// corner of the window, independent of the main camera
+type pictureInPicture struct {
+	canvas *pixelgl.Canvas
+	frame  *imdraw.IMDraw
+	target *box2d.B2Body
+}
+
+func newPictureInPicture() *pictureInPicture {
+	frame := imdraw.New(nil)
+	frame.Color = colornames.Dimgray
+	frame.Push(pipBounds.Min, pipBounds.Max)
+	frame.Rectangle(4)
+	return &pictureInPicture{
+		canvas: pixelgl.NewCanvas(pipBounds),
+		frame:  frame,
+	}
+}
+
+// watch stops tracking a tree once it's removed from the world
+func (p *pictureInPicture) watch(events *trees.Bus) {
+	events.Subscribe(func(e trees.Event) {
+		if destroyed, ok := e.(trees.BodyDestroyed); ok && destroyed.Body == p.target {
+			p.target = nil
+		}
+	})
+}
+
+// toggle starts tracking whatever is nearest to a point in metres, or stops
+// tracking if something is already being followed
+func (p *pictureInPicture) toggle(world *trees.World, point pixel.Vec) {
+	if p.target != nil {
+		p.target = nil
+		return
+	}
+	if e := world.Pick(point, pipPickRadius); e != nil {
+		p.target = e.Body
+	}
+}
+
+// draw renders the tracked view into the top right corner of the window. The
+// window matrix is reset so this should be the last thing drawn in a frame.
+func (p *pictureInPicture) draw(win *pixelgl.Window, world *trees.World) {
+	if p.target == nil {
+		return
+	}
+
+	// Centre the view on the tracked tree
+	pos := p.target.GetPosition()
+	centre := pixel.V(pos.X, pos.Y).Scaled(trees.PixelsPerMetre * pipZoom)
+	view := camera.Camera{Pos: pipBounds.Center().Sub(centre), Zoom: pipZoom}
+
+	p.canvas.SetMatrix(view.Matrix())
+	p.canvas.Clear(colornames.Whitesmoke)
+	shown := world.View()
+	world.SetView(view.View(pipBounds))
+	world.Render(p.canvas)
+	world.SetView(shown)
+	p.canvas.SetMatrix(pixel.IM)
+	p.frame.Draw(p.canvas)
+
+	// Canvases are drawn about their centre
+	corner := win.Bounds().Max.Sub(pipBounds.Size().Scaled(0.5)).Sub(pixel.V(pipMargin, pipMargin))
+	win.SetMatrix(pixel.IM)
+	p.canvas.Draw(win, pixel.IM.Moved(corner))
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"time"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"github.com/faiface/pixel/pixelgl"
+	"github.com/scottyw/falling-trees/recording"
+	"golang.org/x/image/colornames"
+)
+
+// player plays a recording back with a timeline bar along the bottom of the
+// window. Click the button or press space to play and pause, click or drag on
+// the bar to scrub, and use comma and full stop to step a frame at a time.
+// Playback runs on its own clock and never touches the physics.
+type player struct {
+	timeline  *recording.Timeline
+	pos       time.Duration
+	playing   bool
+	index     int
+	frame     recording.Frame
+	frames    int // Frames that can be shown, up to the first that couldn't be read
+	scrubbing bool
+	ui        *imdraw.IMDraw
+}
+
+func newPlayer(path string) (*player, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	timeline, err := recording.NewTimeline(data)
+	if err != nil {
+		return nil, err
+	}
+	if timeline.Len() == 0 {
+		return nil, errors.New("recording has no frames")
+	}
+	return &player{
+		timeline: timeline,
+		playing:  true,
+		index:    -1,
+		frames:   timeline.Len(),
+		ui:       imdraw.New(nil),
+	}, nil
+}
+
+// playButton and track lay out the playback controls in window coordinates
+func playButton(bounds pixel.Rect) pixel.Rect {
+	return pixel.R(bounds.Min.X+16, bounds.Min.Y+12, bounds.Min.X+40, bounds.Min.Y+36)
+}
+
+func track(bounds pixel.Rect) pixel.Rect {
+	return pixel.R(bounds.Min.X+56, bounds.Min.Y+20, bounds.Max.X-24, bounds.Min.Y+28)
+}
+
+// update handles the playback controls and moves playback on by dt. It
+// reports whether a different frame is now showing. If a frame can't be
+// read, playback pauses on the last good one and goes no further than it.
+func (p *player) update(win *pixelgl.Window, dt time.Duration) (bool, error) {
+	bounds := win.Bounds()
+	mouse := win.MousePosition()
+	last := p.frames - 1
+
+	if win.JustPressed(pixelgl.MouseButtonLeft) {
+		if playButton(bounds).Contains(mouse) {
+			p.toggle()
+		} else if t := track(bounds); mouse.X >= t.Min.X && mouse.X <= t.Max.X && mouse.Y < t.Max.Y+12 {
+			// Be generous vertically since the bar itself is thin
+			p.scrubbing = true
+		}
+	}
+	if !win.Pressed(pixelgl.MouseButtonLeft) {
+		p.scrubbing = false
+	}
+	if win.JustPressed(pixelgl.KeySpace) {
+		p.toggle()
+	}
+
+	// Work out which frame to show
+	index := p.index
+	switch {
+	case p.scrubbing:
+		t := track(bounds)
+		fraction := pixel.Clamp((mouse.X-t.Min.X)/t.W(), 0, 1)
+		p.pos = time.Duration(fraction * float64(p.timeline.Duration()))
+		index = p.timeline.Index(p.pos)
+	case win.Repeated(pixelgl.KeyComma) || win.JustPressed(pixelgl.KeyComma):
+		p.playing = false
+		if index > 0 {
+			index--
+		} else {
+			index = 0
+		}
+		p.pos = p.timeline.Time(index)
+	case win.Repeated(pixelgl.KeyPeriod) || win.JustPressed(pixelgl.KeyPeriod):
+		p.playing = false
+		if index < last {
+			index++
+		}
+		p.pos = p.timeline.Time(index)
+	case p.playing:
+		p.pos += dt
+		if p.pos >= p.end() {
+			p.pos = p.end()
+			p.playing = false
+		}
+		index = p.timeline.Index(p.pos)
+	}
+	if index > last {
+		index = last
+		p.pos = p.end()
+	}
+
+	if index == p.index || index < 0 {
+		return false, nil
+	}
+	frame, err := p.timeline.Frame(index)
+	if err != nil {
+		p.frames, p.playing = index, false
+		p.pos = p.end()
+		return false, err
+	}
+	p.index = index
+	p.frame = frame
+	return true, nil
+}
+
+// end is the time of the last frame that can be shown
+func (p *player) end() time.Duration {
+	if p.frames == 0 {
+		return 0
+	}
+	return p.timeline.Time(p.frames - 1)
+}
+
+// toggle plays or pauses, starting again from the top if playback has finished
+func (p *player) toggle() {
+	if !p.playing && p.pos >= p.end() {
+		p.pos = 0
+	}
+	p.playing = !p.playing
+}
+
+// draw renders the playback controls. The window matrix is reset so this
+// should be drawn after the scene.
+func (p *player) draw(win *pixelgl.Window) {
+	bounds := win.Bounds()
+	t := track(bounds)
+	fraction := 1.0
+	if d := p.timeline.Duration(); d > 0 {
+		fraction = float64(p.pos) / float64(d)
+	}
+	handle := pixel.V(t.Min.X+t.W()*fraction, t.Center().Y)
+
+	p.ui.Clear()
+	p.ui.Color = colornames.Lightgray
+	p.ui.Push(t.Min, t.Max)
+	p.ui.Rectangle(0)
+	p.ui.Color = colornames.Dimgray
+	p.ui.Push(t.Min, pixel.V(handle.X, t.Max.Y))
+	p.ui.Rectangle(0)
+	p.ui.Push(handle)
+	p.ui.Circle(8, 0)
+
+	// Show pause bars while playing and a play triangle otherwise
+	b := playButton(bounds)
+	if p.playing {
+		p.ui.Push(b.Min, pixel.V(b.Min.X+b.W()/3, b.Max.Y))
+		p.ui.Rectangle(0)
+		p.ui.Push(pixel.V(b.Max.X-b.W()/3, b.Min.Y), b.Max)
+		p.ui.Rectangle(0)
+	} else {
+		p.ui.Push(b.Min, pixel.V(b.Min.X, b.Max.Y), pixel.V(b.Max.X, b.Center().Y))
+		p.ui.Polygon(0)
+	}
+
+	win.SetMatrix(pixel.IM)
+	p.ui.Draw(win)
+}
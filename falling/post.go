@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/pixelgl"
+)
+
+// postStage draws the frame through a fragment shader onto a canvas of its
+// own. A canvas's shader is used for everything drawn onto it, so each stage
+// needs its own.
+type postStage struct {
+	shader   string
+	uniforms map[string]interface{} // Set on the canvas as it's made, pointers to change them later
+	canvas   *pixelgl.Canvas
+}
+
+// draw draws a frame through the stage's shader, making its canvas the first
+// time and resizing it along with the window
+func (s *postStage) draw(win *pixelgl.Window, frame *pixelgl.Canvas) *pixelgl.Canvas {
+	if s.canvas == nil {
+		s.canvas = pixelgl.NewCanvas(win.Bounds())
+		for name, value := range s.uniforms {
+			s.canvas.SetUniform(name, value)
+		}
+		s.canvas.SetFragmentShader(s.shader)
+	}
+	if s.canvas.Bounds() != win.Bounds() {
+		s.canvas.SetBounds(win.Bounds())
+	}
+	s.canvas.Clear(pixel.Alpha(0))
+	// Canvases are drawn about their centre
+	frame.Draw(s.canvas, pixel.IM.Moved(win.Bounds().Center()))
+	return s.canvas
+}
+
+// postProcess draws what's been drawn into the window so far through each
+// stage in turn and back into the window. Everything is drawn onto the
+// window's own canvas, which is only shown once the window updates, so that
+// is the offscreen frame the stages start from.
+func postProcess(win *pixelgl.Window, stages []*postStage) {
+	if len(stages) == 0 {
+		return
+	}
+	win.SetMatrix(pixel.IM)
+	frame := win.Canvas()
+	for _, s := range stages {
+		frame = s.draw(win, frame)
+	}
+	frame.Draw(win, pixel.IM.Moved(win.Bounds().Center()))
+}
+
+// postEffects are the shaders that can be chosen with "post" in the config
+// file, or -post, by name. Each one finds where it is in the frame as t, from
+// 0 to 1 across it, and the size of one of the frame's pixels as px in the
+// same units.
+var postEffects = map[string]string{
+	"vignette": vignetteShader,
+	"bloom":    bloomShader,
+	"crt":      crtShader,
+}
+
+// postStages makes a stage for each effect named, in order
+func postStages(names []string) ([]*postStage, error) {
+	var stages []*postStage
+	for _, name := range names {
+		shader, ok := postEffects[name]
+		if !ok {
+			var known []string
+			for n := range postEffects {
+				known = append(known, n)
+			}
+			sort.Strings(known)
+			return nil, fmt.Errorf("no post-processing effect %q, only %s", name, strings.Join(known, ", "))
+		}
+		stages = append(stages, &postStage{shader: shader})
+	}
+	return stages, nil
+}
+
+// vignetteShader darkens the frame towards its corners
+var vignetteShader = `
+#version 330 core
+
+in vec2 vTexCoords;
+
+out vec4 fragColor;
+
+uniform vec4 uTexBounds;
+uniform sampler2D uTexture;
+
+void main() {
+	vec2 t = (vTexCoords - uTexBounds.xy) / uTexBounds.zw;
+	vec4 c = texture(uTexture, t);
+	float edge = smoothstep(0.3, 0.8, distance(t, vec2(0.5)));
+	fragColor = vec4(c.rgb * (1.0 - 0.6 * edge), c.a);
+}
+`
+
+// bloomShader spreads a glow from the bright parts of the frame onto anything
+// darker around them, by adding a blur of how much brighter each neighbour is,
+// so light wraps around the trees against the pale sky
+var bloomShader = `
+#version 330 core
+
+in vec2 vTexCoords;
+
+out vec4 fragColor;
+
+uniform vec4 uTexBounds;
+uniform sampler2D uTexture;
+
+void main() {
+	vec2 t = (vTexCoords - uTexBounds.xy) / uTexBounds.zw;
+	vec2 px = 1.0 / uTexBounds.zw;
+	vec4 c = texture(uTexture, t);
+	vec3 glow = vec3(0.0);
+	float total = 0.0;
+	for (int x = -4; x <= 4; x++) {
+		for (int y = -4; y <= 4; y++) {
+			float weight = exp(-float(x * x + y * y) / 8.0);
+			vec3 s = texture(uTexture, t + vec2(x, y) * px * 2.0).rgb;
+			glow += max(s - max(c.rgb, 0.6), 0.0) * weight;
+			total += weight;
+		}
+	}
+	fragColor = vec4(min(c.rgb + 1.5 * glow / total, 1.0), c.a);
+}
+`
+
+// crtShader makes the frame look like an old television, bulging out in the
+// middle, split into scanlines and with its colours fringed at the edges of
+// things
+var crtShader = `
+#version 330 core
+
+in vec2 vTexCoords;
+
+out vec4 fragColor;
+
+uniform vec4 uTexBounds;
+uniform sampler2D uTexture;
+
+void main() {
+	vec2 t = (vTexCoords - uTexBounds.xy) / uTexBounds.zw;
+	vec2 px = 1.0 / uTexBounds.zw;
+	vec2 centred = t * 2.0 - 1.0;
+	centred *= 1.0 + 0.06 * dot(centred, centred);
+	t = centred * 0.5 + 0.5;
+	if (t.x < 0.0 || t.x > 1.0 || t.y < 0.0 || t.y > 1.0) {
+		fragColor = vec4(0.0, 0.0, 0.0, 1.0);
+		return;
+	}
+	vec3 c = vec3(
+		texture(uTexture, t + vec2(px.x, 0.0)).r,
+		texture(uTexture, t).g,
+		texture(uTexture, t - vec2(px.x, 0.0)).b
+	);
+	float scanline = 0.8 + 0.2 * sin(gl_FragCoord.y * 3.14159);
+	fragColor = vec4(c * scanline, 1.0);
+}
+`
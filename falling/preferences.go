@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/scottyw/falling-trees/storage"
+	"github.com/scottyw/falling-trees/trees"
+)
+
+// preferences are the choices made in the demo itself that are kept from one
+// run to the next, unlike the config file which is only ever read
+type preferences struct {
+	Version int `json:"version"`
+
+	// Mods that have been switched off in the mod browser. Mods that aren't
+	// listed are on, so newly added mods load straight away.
+	DisabledMods []string `json:"disabledMods,omitempty"`
+
+	// Where the world was last saved with Ctrl+S, most recent first, for the
+	// menu to offer
+	RecentSaves []string `json:"recentSaves,omitempty"`
+}
+
+// How many recent saves the preferences keep
+const recentSaves = 8
+
+var preferencesFormat = trees.Format{Name: "preferences"}
+
+// defaultPreferencesPath is preferences.json in the user's config directory
+func defaultPreferencesPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "falling-trees", "preferences.json")
+}
+
+// loadPreferences reads the preferences, which are empty if they've never
+// been saved
+func loadPreferences(path string) (preferences, error) {
+	var p preferences
+	if path == "" {
+		return p, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return p, nil
+	}
+	if err != nil {
+		return p, err
+	}
+	err = preferencesFormat.Decode(bytes.NewReader(data), &p)
+	return p, err
+}
+
+func (p preferences) save(path string) error {
+	if path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	p.Version = preferencesFormat.Version()
+	return storage.Save(path, func(out io.Writer) error {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "\t")
+		return enc.Encode(p)
+	})
+}
+
+// disabled is the set of disabled mods
+func (p preferences) disabled() map[string]bool {
+	set := map[string]bool{}
+	for _, name := range p.DisabledMods {
+		set[name] = true
+	}
+	return set
+}
+
+// remember puts a save at the top of the recent saves
+func (p *preferences) remember(save string) {
+	recent := []string{save}
+	for _, s := range p.RecentSaves {
+		if s != save && len(recent) < recentSaves {
+			recent = append(recent, s)
+		}
+	}
+	p.RecentSaves = recent
+}
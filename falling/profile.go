@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	_ "net/http/pprof" // Serves the profiles on http.DefaultServeMux
+	"runtime/pprof"
+	"runtime/trace"
+)
+
+// servePprof serves the runtime's profiles from /debug/pprof/ on an address,
+// in the background so a failure to listen is logged rather than stopping the
+// demo
+func servePprof(addr string) {
+	log.Printf("serving profiles on http://%s/debug/pprof/", addr)
+	go func() {
+		if err := http.ListenAndServe(addr, nil); err != nil {
+			log.Printf("stopped serving profiles: %v", err)
+		}
+	}()
+}
+
+// profiled runs part of a frame labelled for CPU profiles with its phase and
+// name, and within a region of the same name for execution traces, so both
+// split the time up the way the F3 timings do
+func profiled(ctx context.Context, phase, name string, run func()) {
+	pprof.Do(ctx, pprof.Labels("phase", phase, "system", name), func(ctx context.Context) {
+		trace.WithRegion(ctx, phase+" "+name, run)
+	})
+}
+
+// frameTask starts the task an execution trace groups a frame's regions
+// under, returning the context to run them in and a function to end it
+func frameTask() (context.Context, func()) {
+	ctx, task := trace.NewTask(context.Background(), "frame")
+	return ctx, task.End
+}
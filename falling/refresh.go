@@ -0,0 +1,93 @@
+package main
+
+import (
+	"log"
+	"math"
+	"time"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/pixelgl"
+)
+
+const (
+	// How often to look up which monitor the window is on, in case it's been
+	// dragged onto another with a different refresh rate
+	refreshCheck = time.Second
+
+	// Frames within this fraction of a refresh of a whole number of refreshes
+	// are taken to have lasted exactly that many
+	refreshTolerance = 0.1
+)
+
+// pacer evens out frame times under vsync, which are always a whole number of
+// refreshes of the monitor but are measured with a little jitter either side.
+// Left alone, the jitter has the fixed-step accumulator take two steps one
+// frame and none the next on a display refreshing at the step rate, and the
+// trees stutter. Snapped, a 60 Hz display takes exactly one 60 Hz step a
+// frame and a 120 Hz one takes one every other frame. Whatever is shaved off
+// or added to a frame is owed to the next, so simulated time keeps up with
+// real time exactly on every display.
+type pacer struct {
+	on      bool
+	rate    float64 // Refresh rate of the window's monitor in hertz, or 0 if unknown
+	monitor string
+	checked time.Duration // Since the monitor was last looked up
+	owed    time.Duration
+}
+
+// pace turns the measured time of a frame into the time to simulate
+func (p *pacer) pace(win *pixelgl.Window, dt time.Duration) time.Duration {
+	p.checked += dt
+	if p.monitor == "" || p.checked >= refreshCheck {
+		p.detect(win)
+	}
+	if !p.on || !win.VSync() {
+		dt += p.owed
+		p.owed = 0
+		return dt
+	}
+	return p.snap(dt)
+}
+
+// snap rounds a frame, along with any time owed from the last, to a whole
+// number of refreshes if it's close enough
+func (p *pacer) snap(dt time.Duration) time.Duration {
+	p.owed += dt
+	if p.rate <= 0 {
+		dt = p.owed
+		p.owed = 0
+		return dt
+	}
+	refresh := float64(time.Second) / p.rate
+	n := math.Round(float64(p.owed) / refresh)
+	snapped := time.Duration(n * refresh)
+	if n >= 1 && math.Abs(float64(p.owed-snapped)) <= refreshTolerance*refresh {
+		p.owed -= snapped
+		return snapped
+	}
+
+	// A frame that missed a refresh, or a hitch, is taken as it came
+	dt = p.owed
+	p.owed = 0
+	return dt
+}
+
+// detect looks up the refresh rate of the monitor under the middle of the
+// window, or of the primary monitor if the window is somehow on none of them
+func (p *pacer) detect(win *pixelgl.Window) {
+	p.checked = 0
+	centre := win.GetPos().Add(win.Bounds().Size().Scaled(0.5))
+	monitor := pixelgl.PrimaryMonitor()
+	for _, m := range pixelgl.Monitors() {
+		x, y := m.Position()
+		w, h := m.Size()
+		if pixel.R(x, y, x+w, y+h).Contains(centre) {
+			monitor = m
+		}
+	}
+	if monitor == nil || monitor.Name() == p.monitor {
+		return
+	}
+	p.monitor, p.rate = monitor.Name(), monitor.RefreshRate()
+	log.Printf("window is on %s, refreshing at %g Hz", p.monitor, p.rate)
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/scottyw/falling-trees/assets"
+	"github.com/scottyw/falling-trees/trees"
+)
+
+// How often to look for changes to the asset pack being watched
+const assetCheck = 500 * time.Millisecond
+
+// assetWatcher notices when the files of an asset pack in a directory change
+// on disk, so an artist can see a spritesheet they're working on in the world
+// as soon as it's saved. It compares the size and modification time of every
+// file every so often, which is cheap for a pack of a few files and needs
+// nothing from the operating system.
+type assetWatcher struct {
+	pack    *assets.Pack
+	dir     assets.Dir
+	checked time.Duration
+	stamp   string
+}
+
+// watchAssets watches a pack, or returns nil if it isn't in a directory and
+// can't change
+func watchAssets(pack *assets.Pack) *assetWatcher {
+	dir, ok := pack.Files().(assets.Dir)
+	if !ok {
+		log.Printf("asset pack %q is built in or zipped and can't change, so it isn't watched", pack.Name)
+		return nil
+	}
+	w := &assetWatcher{pack: pack, dir: dir}
+	w.stamp, _ = w.look()
+	log.Printf("watching %s for changes to asset pack %q", dir, pack.Name)
+	return w
+}
+
+// look sums up the size and modification time of every file in the pack
+func (w *assetWatcher) look() (string, error) {
+	names, err := w.dir.Names()
+	if err != nil {
+		return "", err
+	}
+	var stamp string
+	for _, name := range names {
+		info, err := os.Stat(filepath.Join(string(w.dir), filepath.FromSlash(name)))
+		if err != nil {
+			return "", err
+		}
+		stamp += fmt.Sprintf("%s %d %d\n", name, info.Size(), info.ModTime().UnixNano())
+	}
+	return stamp, nil
+}
+
+// changed reports whether any file has changed since it last said so, and
+// opens the pack again if it has so the manifest is read afresh too
+func (w *assetWatcher) changed(dt time.Duration) (*assets.Pack, bool) {
+	w.checked += dt
+	if w.checked < assetCheck {
+		return nil, false
+	}
+	w.checked = 0
+	stamp, err := w.look()
+	if err != nil || stamp == w.stamp {
+		return nil, false
+	}
+	w.stamp = stamp
+	pack, err := assets.Open(w.pack.Name, w.dir)
+	if err != nil {
+		log.Printf("kept the old sprites: %v", err)
+		return nil, false
+	}
+	pack.Unchecked = w.pack.Unchecked
+	return pack, true
+}
+
+// treeSprites are the sprites trees are drawn with from a pack, in a season,
+// every one of them so the forest is a mix
+func treeSprites(pack *assets.Pack, season string) ([]trees.TreeSprite, error) {
+	seasons, err := pack.Sprites()
+	if err != nil {
+		return nil, err
+	}
+	sprites, ok := seasons[season]
+	if !ok || len(sprites) == 0 {
+		return nil, fmt.Errorf("no sprites for season %q", season)
+	}
+	var forest []trees.TreeSprite
+	for _, s := range sprites {
+		forest = append(forest, trees.TreeSprite{Sprite: s.Sprite, Pivot: s.Pivot, Frames: s.Frames, FrameRate: s.FrameRate})
+	}
+	return forest, nil
+}
+
+// Reload the sprites whenever the asset pack changes, with -watch-assets. A
+// spritesheet caught half written, or cut up wrongly, is logged and the old
+// sprites kept until it's saved again.
+func (a *app) reloadAssets(dt time.Duration) {
+	if a.watcher == nil {
+		return
+	}
+	pack, ok := a.watcher.changed(dt)
+	if !ok {
+		return
+	}
+	sprites, err := treeSprites(pack, a.season)
+	if err != nil {
+		log.Printf("kept the old sprites: %v", err)
+		return
+	}
+	a.opts.Sprites = sprites
+	a.world.SetSprites(sprites)
+	if a.texel > 0 {
+		a.texel = 2 * trees.TreeRadius * trees.PixelsPerMetre / sprites[0].Sprite.Frame().W()
+	}
+	log.Printf("reloaded the sprites of asset pack %q", pack.Name)
+}
@@ -0,0 +1,310 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/faiface/pixel"
+	"github.com/pion/webrtc/v4"
+	"github.com/scottyw/falling-trees/camera"
+)
+
+const (
+	// Messages either side can queue up before new ones are dropped, so a slow
+	// connection never holds up the frame
+	remoteBacklog = 64
+
+	// Longest a guest waits for the host to answer its offer
+	remoteTimeout = 15 * time.Second
+)
+
+// remoteInput is the part of one instance's input that is mirrored onto
+// another. Pick is set to the point in metres under the cursor when P is
+// pressed, to choose the tree followed by the picture-in-picture view. Tool
+// is the name of the tool chosen with T, and Commands are what the guest did
+// to its world since the last message, tool use included, for the host to do
+// to its own.
+type remoteInput struct {
+	CamX, CamY float64
+	Zoom       float64
+	Angle      float64
+	Pick       *pixel.Vec `json:",omitempty"`
+	Tool       string     `json:",omitempty"`
+	Commands   []command  `json:",omitempty"`
+}
+
+func (in remoteInput) camera() camera.Camera {
+	return camera.Camera{Pos: pixel.V(in.CamX, in.CamY), Zoom: in.Zoom, Angle: in.Angle}
+}
+
+// remote pairs two instances so that a guest's camera and tools drive the
+// host's simulation. Input travels as one JSON message at a time over a
+// WebRTC data channel. The guest sends its offer to the host over HTTP and
+// gets the host's answer back, with every ICE candidate gathered up front, so
+// the STUN or TURN servers given to both sides are all it takes to get
+// through NAT. There's no authentication, so anyone who can reach the host's
+// address can pair with it.
+type remote struct {
+	ice   []webrtc.ICEServer
+	inbox chan remoteInput // Only on a host
+	last  remoteInput
+
+	// The connection to the other instance, once there is one, and on a host
+	// the server guests pair through
+	mu     sync.Mutex
+	peer   *webrtc.PeerConnection
+	server *http.Server
+
+	// Only on a guest, where a goroutine of its own writes the outbox to the
+	// data channel once it opens
+	outbox chan remoteInput
+
+	// Closed once the pairing is over
+	lost chan struct{}
+	once sync.Once
+}
+
+// iceServers turns a list of STUN and TURN URLs separated by commas into
+// servers for ICE, which are needed to pair across networks
+func iceServers(urls string) []webrtc.ICEServer {
+	var servers []webrtc.ICEServer
+	for _, url := range strings.Split(urls, ",") {
+		if url = strings.TrimSpace(url); url != "" {
+			servers = append(servers, webrtc.ICEServer{URLs: []string{url}})
+		}
+	}
+	return servers
+}
+
+// hostRemote listens for guests, one at a time, and queues up their input. A
+// new guest takes over from the last.
+func hostRemote(addr string, ice []webrtc.ICEServer) (*remote, error) {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	r := &remote{ice: ice, inbox: make(chan remoteInput, remoteBacklog), lost: make(chan struct{})}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/pair", r.pair)
+	r.server = &http.Server{Handler: mux}
+	go func() {
+		if err := r.server.Serve(l); err != http.ErrServerClosed {
+			log.Printf("pairing: %v", err)
+		}
+	}()
+	return r, nil
+}
+
+// pair answers a guest's offer and takes its input from the data channel it
+// opens
+func (r *remote) pair(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "pair by posting an offer", http.StatusMethodNotAllowed)
+		return
+	}
+	var offer webrtc.SessionDescription
+	if err := json.NewDecoder(req.Body).Decode(&offer); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	peer, err := webrtc.NewPeerConnection(webrtc.Configuration{ICEServers: r.ice})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	peer.OnDataChannel(func(dc *webrtc.DataChannel) {
+		dc.OnMessage(func(msg webrtc.DataChannelMessage) {
+			var in remoteInput
+			if err := json.Unmarshal(msg.Data, &in); err != nil {
+				log.Printf("pairing: bad message from the guest: %v", err)
+				return
+			}
+			select {
+			case r.inbox <- in:
+			case <-r.lost:
+			}
+		})
+	})
+	reply, err := answer(peer, offer)
+	if err != nil {
+		peer.Close()
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	r.mu.Lock()
+	old := r.peer
+	r.peer = peer
+	r.mu.Unlock()
+	if old != nil {
+		old.Close()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(reply)
+}
+
+// answer accepts an offer, returning the answer once every ICE candidate is
+// in it
+func answer(peer *webrtc.PeerConnection, offer webrtc.SessionDescription) (*webrtc.SessionDescription, error) {
+	if err := peer.SetRemoteDescription(offer); err != nil {
+		return nil, err
+	}
+	desc, err := peer.CreateAnswer(nil)
+	if err != nil {
+		return nil, err
+	}
+	gathered := webrtc.GatheringCompletePromise(peer)
+	if err := peer.SetLocalDescription(desc); err != nil {
+		return nil, err
+	}
+	<-gathered
+	return peer.LocalDescription(), nil
+}
+
+// connectRemote joins a host as its guest, offering it a data channel
+func connectRemote(addr string, ice []webrtc.ICEServer) (*remote, error) {
+	peer, err := webrtc.NewPeerConnection(webrtc.Configuration{ICEServers: ice})
+	if err != nil {
+		return nil, err
+	}
+	r := &remote{ice: ice, peer: peer, outbox: make(chan remoteInput, remoteBacklog), lost: make(chan struct{})}
+	dc, err := peer.CreateDataChannel("pairing", nil)
+	if err != nil {
+		peer.Close()
+		return nil, err
+	}
+	dc.OnOpen(func() { go r.write(dc) })
+	dc.OnClose(func() {
+		select {
+		case <-r.lost:
+			return // Closed from this end
+		default:
+		}
+		log.Printf("pairing: the host closed the connection")
+		go r.close()
+	})
+	peer.OnConnectionStateChange(func(state webrtc.PeerConnectionState) {
+		if state == webrtc.PeerConnectionStateFailed {
+			log.Printf("pairing: lost the host")
+			go r.close()
+		}
+	})
+	if err := r.offer(addr); err != nil {
+		peer.Close()
+		return nil, fmt.Errorf("pairing with %s: %v", addr, err)
+	}
+	return r, nil
+}
+
+// offer sends the host an offer with every ICE candidate in it and takes
+// its answer
+func (r *remote) offer(addr string) error {
+	desc, err := r.peer.CreateOffer(nil)
+	if err != nil {
+		return err
+	}
+	gathered := webrtc.GatheringCompletePromise(r.peer)
+	if err := r.peer.SetLocalDescription(desc); err != nil {
+		return err
+	}
+	<-gathered
+	body, err := json.Marshal(r.peer.LocalDescription())
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: remoteTimeout}
+	resp, err := client.Post("http://"+addr+"/pair", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("host said %s", resp.Status)
+	}
+	var answer webrtc.SessionDescription
+	if err := json.NewDecoder(resp.Body).Decode(&answer); err != nil {
+		return err
+	}
+	return r.peer.SetRemoteDescription(answer)
+}
+
+// write sends the outbox to the host until the channel fails or the pairing
+// is closed
+func (r *remote) write(dc *webrtc.DataChannel) {
+	for {
+		select {
+		case in := <-r.outbox:
+			data, err := json.Marshal(in)
+			if err == nil {
+				err = dc.Send(data)
+			}
+			if err != nil {
+				log.Printf("pairing: lost the host: %v", err)
+				r.close()
+				return
+			}
+		case <-r.lost:
+			return
+		}
+	}
+}
+
+// send queues input for the host without waiting for it to be written,
+// skipping it if nothing has changed. If the connection has fallen too far
+// behind the input is dropped. It reports false once the host is gone.
+func (r *remote) send(in remoteInput) bool {
+	select {
+	case <-r.lost:
+		return false
+	default:
+	}
+	if in.Pick == nil && len(in.Commands) == 0 && in.camera() == r.last.camera() && in.Tool == r.last.Tool {
+		return true
+	}
+	select {
+	case r.outbox <- in:
+		r.last = remoteInput{CamX: in.CamX, CamY: in.CamY, Zoom: in.Zoom, Angle: in.Angle, Tool: in.Tool}
+	default:
+		log.Printf("pairing: the host is falling behind, dropping input")
+	}
+	return true
+}
+
+// close disconnects from the host or stops listening for guests
+func (r *remote) close() error {
+	var err error
+	r.once.Do(func() {
+		close(r.lost)
+		if r.server != nil {
+			err = r.server.Close()
+		}
+		r.mu.Lock()
+		peer := r.peer
+		r.mu.Unlock()
+		if peer != nil {
+			if perr := peer.Close(); err == nil {
+				err = perr
+			}
+		}
+	})
+	return err
+}
+
+// receive returns whatever input has arrived from the guest since last time
+func (r *remote) receive() []remoteInput {
+	var received []remoteInput
+	for {
+		select {
+		case in := <-r.inbox:
+			received = append(received, in)
+		default:
+			return received
+		}
+	}
+}
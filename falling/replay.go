@@ -0,0 +1,256 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/faiface/pixel"
+	"github.com/scottyw/falling-trees/trees"
+)
+
+// replayVersion is the version of the replay files written by this build
+const replayVersion = 1
+
+// replayHeader is everything that decides how a world starts out, written at
+// the top of a replay so the same world can be built again
+type replayHeader struct {
+	Version         int                 `json:"version"`
+	Seed            int64               `json:"seed"`
+	Level           string              `json:"level"`
+	Trees           int                 `json:"trees"`
+	Gravity         pixel.Vec           `json:"gravity"`
+	TreeDamping     float64             `json:"damping"`
+	TreeRestitution float64             `json:"restitution"`
+	Solver          trees.SolverProfile `json:"solver"`
+	Subdivisions    int                 `json:"subdivisions"`
+	FixedStep       float64             `json:"fixedStep"`
+	Tide            trees.Tide          `json:"tide"`
+	SoftGround      trees.SoftGround    `json:"softGround"`
+	Seeding         float64             `json:"seeding,omitempty"`
+	Lifetimes       map[string]float64  `json:"lifetimes,omitempty"`
+	MaxSpeeds       map[string]float64  `json:"maxSpeeds,omitempty"`
+}
+
+func newReplayHeader(seed int64, opts trees.Options) replayHeader {
+	return replayHeader{
+		Version:         replayVersion,
+		Seed:            seed,
+		Level:           opts.Level,
+		Trees:           opts.Trees,
+		Gravity:         opts.Gravity,
+		TreeDamping:     opts.TreeDamping,
+		TreeRestitution: opts.TreeRestitution,
+		Solver:          opts.Solver,
+		Subdivisions:    opts.Subdivisions,
+		FixedStep:       opts.FixedStep,
+		Tide:            opts.Tide,
+		SoftGround:      opts.SoftGround,
+		Seeding:         opts.Seeding,
+		Lifetimes:       opts.Lifetimes,
+		MaxSpeeds:       opts.MaxSpeeds,
+	}
+}
+
+// apply sets up the options for a new world the way the recorded one was
+func (h replayHeader) apply(opts *trees.Options) {
+	opts.Level = h.Level
+	opts.Trees = h.Trees
+	opts.Gravity = h.Gravity
+	opts.TreeDamping = h.TreeDamping
+	opts.TreeRestitution = h.TreeRestitution
+	opts.Solver = h.Solver
+	opts.Subdivisions = h.Subdivisions
+	opts.FixedStep = h.FixedStep
+	opts.Tide = h.Tide
+	opts.SoftGround = h.SoftGround
+	opts.Seeding = h.Seeding
+	opts.Lifetimes = h.Lifetimes
+	opts.MaxSpeeds = h.MaxSpeeds
+}
+
+// command is something done to the world from outside the physics, tagged
+// with how many fixed steps the world had taken when it was done so that a
+// replay can do it again at exactly the same moment
+type command struct {
+	Step int     `json:"step"`
+	Do   string  `json:"do"`
+	X    float64 `json:"x,omitempty"`
+	Y    float64 `json:"y,omitempty"`
+	Name string  `json:"name,omitempty"` // Tool, solver profile or gravity preset
+	N    float64 `json:"n,omitempty"`    // Trees in a wave or strength of a gust
+}
+
+func (c command) at() pixel.Vec {
+	return pixel.V(c.X, c.Y)
+}
+
+// apply does the command to the world, using the tools by name
+func (c command) apply(world *trees.World, tools []trees.Tool) error {
+	switch c.Do {
+	case "press", "drag", "release":
+		for _, t := range tools {
+			if t.Name() != c.Name {
+				continue
+			}
+			switch c.Do {
+			case "press":
+				t.Press(world, c.at())
+			case "drag":
+				t.Drag(world, c.at())
+			case "release":
+				t.Release(world, c.at())
+			}
+			return nil
+		}
+		return fmt.Errorf("replay uses unknown tool %q", c.Name)
+	case "spawn":
+		world.SpawnTree(c.at())
+	case "grab":
+		world.Grab(c.at())
+	case "move":
+		world.MoveGrab(c.at())
+	case "drop":
+		world.ReleaseGrab()
+	case "wave":
+		world.DropWave(int(c.N))
+	case "gust":
+		world.Gust(c.N)
+	case "explode":
+		world.Explode(c.at(), attractBlastRadius, attractBlastImpulse)
+	case "solver":
+		p, ok := trees.LookupSolverProfile(c.Name)
+		if !ok {
+			return fmt.Errorf("replay uses unknown solver profile %q", c.Name)
+		}
+		world.SetSolver(p)
+	case "gravity":
+		p, ok := trees.LookupGravityPreset(c.Name)
+		if !ok {
+			return fmt.Errorf("replay uses unknown gravity preset %q", c.Name)
+		}
+		world.SetGravityPreset(p)
+	default:
+		return fmt.Errorf("replay has unknown command %q", c.Do)
+	}
+	return nil
+}
+
+// inputRecorder writes the header and then every command to a replay file,
+// one JSON document to a line
+type inputRecorder struct {
+	file   *os.File
+	out    *bufio.Writer
+	enc    *json.Encoder
+	closed bool
+}
+
+func newInputRecorder(path string, header replayHeader) (*inputRecorder, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	out := bufio.NewWriter(file)
+	r := &inputRecorder{file: file, out: out, enc: json.NewEncoder(out)}
+	if err := r.enc.Encode(header); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *inputRecorder) record(c command) error {
+	return r.enc.Encode(c)
+}
+
+// close finishes the replay file, doing nothing if it's already closed
+func (r *inputRecorder) close() error {
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	if err := r.out.Flush(); err != nil {
+		r.file.Close()
+		return err
+	}
+	return r.file.Close()
+}
+
+// rerun re-simulates a replay, doing each recorded command just before the
+// step it was done before
+type rerun struct {
+	header   replayHeader
+	commands []command
+	next     int
+	pending  time.Duration // Simulated time that doesn't yet make up a whole step
+	limit    time.Duration // Most time one frame moves on by, from MaxAdvance, or 0 for any
+	stopped  bool          // A recorded command failed, so the world stays where it got to
+}
+
+func loadReplay(path string) (*rerun, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	dec := json.NewDecoder(bufio.NewReader(file))
+	r := &rerun{}
+	if err := dec.Decode(&r.header); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if r.header.Version > replayVersion {
+		return nil, fmt.Errorf("%s: replay version %d is newer than this build understands", path, r.header.Version)
+	}
+	if r.header.FixedStep <= 0 {
+		return nil, fmt.Errorf("%s: replay has no fixed step", path)
+	}
+	for {
+		var c command
+		err := dec.Decode(&c)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// A replay cut short by a crash still plays up to where it stopped
+			if err == io.ErrUnexpectedEOF {
+				break
+			}
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		r.commands = append(r.commands, c)
+	}
+	return r, nil
+}
+
+// advance moves the world on one fixed step at a time by the simulated time
+// elapsed, doing the recorded commands as it goes. It returns the number of
+// steps taken. Once a command fails the replay stops, leaving the world as it
+// was just before, and advance does nothing from then on.
+func (r *rerun) advance(world *trees.World, tools []trees.Tool, dt time.Duration, steps int) (int, error) {
+	if r.stopped {
+		return 0, nil
+	}
+	step := stepDuration(r.header.FixedStep)
+	if r.limit > 0 && dt > r.limit {
+		world.Events().Publish(trees.Hitch{Elapsed: dt.Seconds(), Dropped: (dt - r.limit).Seconds()})
+		dt = r.limit
+	}
+	r.pending += dt
+	taken := 0
+	for r.pending >= step {
+		for r.next < len(r.commands) && r.commands[r.next].Step <= steps+taken {
+			if err := r.commands[r.next].apply(world, tools); err != nil {
+				r.stopped = true
+				return taken, err
+			}
+			r.next++
+		}
+		world.Advance(r.header.FixedStep)
+		r.pending -= step
+		taken++
+	}
+	return taken, nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"github.com/faiface/pixel/pixelgl"
+	"github.com/faiface/pixel/text"
+	"github.com/scottyw/falling-trees/camera"
+	"github.com/scottyw/falling-trees/trees"
+	"golang.org/x/image/colornames"
+	"golang.org/x/image/font/basicfont"
+)
+
+// reposeOverlay draws the slopes fitted to each side of the pile of trees and
+// labels them with the angle of repose
+type reposeOverlay struct {
+	shown bool
+	lines *imdraw.IMDraw
+	label *text.Text
+}
+
+func newReposeOverlay() *reposeOverlay {
+	label := text.New(pixel.ZV, text.NewAtlas(basicfont.Face7x13, text.ASCII))
+	label.Color = colornames.Firebrick
+	return &reposeOverlay{lines: imdraw.New(nil), label: label}
+}
+
+// draw shows the overlay over the scene drawn with the camera's matrix,
+// toggling it with F4
+func (r *reposeOverlay) draw(win *pixelgl.Window, cam camera.Camera, world *trees.World) {
+	if win.JustPressed(pixelgl.KeyF4) {
+		r.shown = !r.shown
+	}
+	if !r.shown {
+		return
+	}
+	repose, ok := world.AngleOfRepose()
+	if !ok {
+		return
+	}
+	r.lines.Clear()
+	r.lines.Color = colornames.Firebrick
+	for _, s := range []trees.Slope{repose.Left, repose.Right} {
+		r.lines.Push(s.From.Scaled(trees.PixelsPerMetre), s.To.Scaled(trees.PixelsPerMetre))
+		r.lines.Line(3)
+	}
+	win.SetMatrix(cam.Matrix())
+	r.lines.Draw(win)
+
+	// Label the peak in screen space so the text stays readable at any zoom
+	peak := repose.Left.To
+	if repose.Right.To.Y > peak.Y {
+		peak = repose.Right.To
+	}
+	r.label.Clear()
+	fmt.Fprintf(r.label, "angle of repose %.1f deg (left %.1f, right %.1f)",
+		degrees(repose.Angle), degrees(repose.Left.Angle), degrees(repose.Right.Angle))
+	at := cam.ToScreen(peak).Add(pixel.V(-r.label.Bounds().W()/2, 16))
+	win.SetMatrix(pixel.IM)
+	r.label.Draw(win, pixel.IM.Moved(at))
+	win.SetMatrix(cam.Matrix())
+}
+
+func degrees(radians float64) float64 {
+	return radians * 180 / math.Pi
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/faiface/pixel"
+	"github.com/scottyw/falling-trees/storage"
+	"github.com/scottyw/falling-trees/trees"
+)
+
+// savedWorld is everything needed to carry on with a world later: the
+// snapshot of every body in it, the physics it was running with and how the
+// trees were drawn
+type savedWorld struct {
+	Version int `json:"version"`
+
+	// Asset pack and season the trees were drawn with
+	Assets string `json:"assets"`
+	Season string `json:"season"`
+
+	// Gravity before any tide, and the preset it came from if it did, which
+	// brings the preset's drag along with it
+	Gravity       pixel.Vec `json:"gravity"`
+	GravityPreset string    `json:"gravityPreset,omitempty"`
+	Solver        string    `json:"solver"`
+
+	// The snapshot, kept as it is so it can be migrated along with every other
+	// snapshot
+	World json.RawMessage `json:"world"`
+
+	snapshot trees.Snapshot
+}
+
+// saveFormat has no migrations yet
+var saveFormat = trees.Format{Name: "save"}
+
+// saveWorld writes the world and how it's drawn to a file or URL
+func saveWorld(world *trees.World, location, assets, season string) error {
+	saved := savedWorld{
+		Version: saveFormat.Version(),
+		Assets:  assets,
+		Season:  season,
+		Gravity: world.Gravity(),
+		Solver:  world.Solver().Name,
+	}
+	if p, ok := world.GravityPreset(); ok {
+		saved.GravityPreset = p.Name
+	}
+	var snapshot bytes.Buffer
+	if err := world.WriteSnapshot(&snapshot); err != nil {
+		return err
+	}
+	saved.World = snapshot.Bytes()
+	err := storage.Save(location, func(out io.Writer) error {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "\t")
+		return enc.Encode(saved)
+	})
+	if err == nil {
+		log.Printf("saved %d trees and %d entities to %s", len(world.Trees()), len(world.Entities()), location)
+	}
+	return err
+}
+
+func loadSave(location string) (savedWorld, error) {
+	var saved savedWorld
+	file, err := storage.Load(location)
+	if err != nil {
+		return saved, err
+	}
+	defer file.Close()
+	if err := saveFormat.Decode(file, &saved); err != nil {
+		return saved, err
+	}
+	err = trees.SnapshotFormat.Decode(bytes.NewReader(saved.World), &saved.snapshot)
+	return saved, err
+}
+
+// restore puts the world back as it was saved. The world must be of the same
+// level, and the sprites are left alone since they're only chosen when the
+// demo starts.
+func (s savedWorld) restore(world *trees.World) error {
+	solver, ok := trees.LookupSolverProfile(s.Solver)
+	if !ok {
+		return fmt.Errorf("unknown solver profile %q", s.Solver)
+	}
+	if err := world.Restore(s.snapshot); err != nil {
+		return err
+	}
+	world.SetSolver(solver)
+	if p, ok := trees.LookupGravityPreset(s.GravityPreset); ok {
+		world.SetGravityPreset(p)
+	} else {
+		world.SetGravity(s.Gravity)
+	}
+	return nil
+}
+
+// startFrom makes the -load save choose the level and how the trees are drawn,
+// unless they were given on the command line too
+func (s savedWorld) startFrom() {
+	given := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) { given[f.Name] = true })
+	if !given["level"] && s.snapshot.Level != "" {
+		*levelName = s.snapshot.Level
+	}
+	if !given["assets"] && s.Assets != "" {
+		*assetsName = s.Assets
+	}
+	if !given["season"] && s.Season != "" {
+		*seasonName = s.Season
+	}
+}
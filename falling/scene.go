@@ -0,0 +1,47 @@
+package main
+
+import (
+	"io"
+	"log"
+	"path"
+	"strings"
+
+	"github.com/scottyw/falling-trees/storage"
+	"github.com/scottyw/falling-trees/trees"
+)
+
+// loadLevelFile reads a level file, such as a scene saved with F11, or a map
+// made in Tiled, and registers its level so it can be played. A map without a
+// name of its own is named after its file.
+func loadLevelFile(location string) (trees.Level, error) {
+	file, err := storage.Load(location)
+	if err != nil {
+		return trees.Level{}, err
+	}
+	defer file.Close()
+	var l trees.Level
+	name := path.Base(strings.SplitN(location, "?", 2)[0])
+	if ext := path.Ext(name); strings.EqualFold(ext, ".tmx") {
+		l, err = trees.ReadTMX(file, storage.Dir(location), strings.TrimSuffix(name, ext))
+	} else {
+		l, err = trees.ReadLevel(file)
+	}
+	if err != nil {
+		return trees.Level{}, err
+	}
+	trees.RegisterLevel(l)
+	return l, nil
+}
+
+// saveScene freezes the world as it is into a level file, which starts from
+// this exact moment when it's loaded with -level-file
+func saveScene(world *trees.World, location string) error {
+	scene := world.Scene(world.Level().Name + " scene")
+	err := storage.Save(location, func(out io.Writer) error {
+		return trees.WriteLevel(out, scene)
+	})
+	if err == nil {
+		log.Printf("saved a scene of %d trees and %d entities to %s", len(world.Trees()), len(world.Entities()), location)
+	}
+	return err
+}
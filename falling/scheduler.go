@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"time"
+)
+
+// phase orders the systems that make up a frame
+type phase int
+
+const (
+	phaseInput phase = iota
+	phaseScript
+	phasePhysics
+	phasePostPhysics
+	phaseRender
+	phaseUI
+)
+
+var phaseNames = [...]string{
+	phaseInput:       "input",
+	phaseScript:      "script",
+	phasePhysics:     "physics",
+	phasePostPhysics: "post-physics",
+	phaseRender:      "render",
+	phaseUI:          "ui",
+}
+
+func (p phase) String() string {
+	return phaseNames[p]
+}
+
+// Weight given to the latest frame when smoothing system timings
+const timingSmoothing = 0.05
+
+// system is one step of the frame
+type system struct {
+	name  string
+	phase phase
+	run   func(dt time.Duration)
+	took  time.Duration // Smoothed time the system takes to run
+}
+
+// scheduler runs every system once a frame, phase by phase, and keeps track
+// of how long each one takes
+type scheduler struct {
+	systems  []*system
+	profiled bool // Label each system for profiling, with -pprof
+}
+
+// add appends a system to the end of its phase
+func (s *scheduler) add(p phase, name string, run func(dt time.Duration)) {
+	i := len(s.systems)
+	for i > 0 && s.systems[i-1].phase > p {
+		i--
+	}
+	s.systems = append(s.systems, nil)
+	copy(s.systems[i+1:], s.systems[i:])
+	s.systems[i] = &system{name: name, phase: p, run: run}
+}
+
+// runFrame runs every system in order, within a frame's context
+func (s *scheduler) runFrame(ctx context.Context, dt time.Duration) {
+	for _, sys := range s.systems {
+		start := time.Now()
+		s.within(ctx, sys.phase.String(), sys.name, func() { sys.run(dt) })
+		took := time.Since(start)
+		sys.took += time.Duration(float64(took-sys.took) * timingSmoothing)
+	}
+}
+
+// within runs part of a frame, labelled for profiling if the scheduler is
+// profiled
+func (s *scheduler) within(ctx context.Context, phase, name string, run func()) {
+	if !s.profiled {
+		run()
+		return
+	}
+	profiled(ctx, phase, name, run)
+}
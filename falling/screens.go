@@ -0,0 +1,250 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/text"
+	"github.com/scottyw/falling-trees/trees"
+	"golang.org/x/image/colornames"
+	"golang.org/x/image/font/basicfont"
+)
+
+// screen is one of the things the demo can be doing, such as running the
+// sandbox or playing back a replay. Each screen builds the frame out of its
+// own systems and, apart from the menu, the ones the others share, and is
+// told when the demo switches to it and away from it so it can set itself up
+// and tidy up.
+type screen interface {
+	name() string
+
+	// systems adds the screen's systems, its own ahead of any shared ones
+	// in each phase
+	systems(a *app)
+
+	enter(a *app) error
+	exit(a *app) error
+
+	// allow reports whether something may be done to the world on this
+	// screen, counting it if the screen keeps count
+	allow(c command) bool
+}
+
+// Advance the simulation in fixed steps by the simulated time elapsed since
+// the last frame
+func (a *app) advance(dt time.Duration) {
+	if a.clock.dt > 0 {
+		a.steps += a.world.Advance(a.clock.dt.Seconds())
+	}
+}
+
+// switchTo leaves the current screen for another once the frame is over
+func (a *app) switchTo(s screen) {
+	a.next = s
+}
+
+// changeScreen makes the switch asked for with switchTo, if there is one, and
+// builds the frame again around the new screen
+func (a *app) changeScreen() error {
+	if a.next == nil {
+		return nil
+	}
+	next := a.next
+	a.next = nil
+	if err := a.leave(); err != nil {
+		return err
+	}
+	if err := next.enter(a); err != nil {
+		return err
+	}
+	a.screen = next
+	a.addSystems()
+	a.world.Events().Publish(trees.ModeChanged{Mode: next.name()})
+	return nil
+}
+
+// leave exits the current screen, if there is one
+func (a *app) leave() error {
+	if a.screen == nil {
+		return nil
+	}
+	s := a.screen
+	a.screen = nil
+	return s.exit(a)
+}
+
+// sandboxScreen lets the user do anything to the world
+type sandboxScreen struct{}
+
+func (s *sandboxScreen) name() string { return "sandbox" }
+
+func (s *sandboxScreen) systems(a *app) {
+	a.sched.add(phasePhysics, "step", a.advance)
+	a.sharedSystems()
+}
+
+func (s *sandboxScreen) enter(a *app) error { return nil }
+func (s *sandboxScreen) exit(a *app) error  { return nil }
+
+func (s *sandboxScreen) allow(c command) bool { return true }
+
+// gameScreen plays the level with a handful of trees to drop by hand, scored
+// by its bins or dominoes. Trees can be dragged around once they're down,
+// but the tools and everything else that changes the world are off limits.
+type gameScreen struct {
+	budget int
+	left   int
+	label  *text.Text
+}
+
+func newGameScreen(budget int) *gameScreen {
+	label := text.New(pixel.ZV, text.NewAtlas(basicfont.Face7x13, text.ASCII))
+	label.Color = colornames.Black
+	return &gameScreen{budget: budget, label: label}
+}
+
+func (s *gameScreen) name() string { return "game" }
+
+func (s *gameScreen) systems(a *app) {
+	a.sched.add(phasePhysics, "step", a.advance)
+	a.sched.add(phaseUI, "game", func(dt time.Duration) { s.draw(a) })
+	a.sharedSystems()
+}
+
+func (s *gameScreen) enter(a *app) error {
+	s.left = s.budget
+	return nil
+}
+
+func (s *gameScreen) exit(a *app) error { return nil }
+
+func (s *gameScreen) allow(c command) bool {
+	switch c.Do {
+	case "spawn":
+		if s.left == 0 {
+			return false
+		}
+		s.left--
+		return true
+	case "grab", "move", "drop":
+		return true
+	}
+	return false
+}
+
+// draw shows how many trees are left to drop in the bottom left corner, out
+// of the way of the dominoes' score
+func (s *gameScreen) draw(a *app) {
+	s.label.Clear()
+	if s.left > 0 {
+		fmt.Fprintf(s.label, "%d of %d trees left, Shift-click to drop one", s.left, s.budget)
+	} else {
+		fmt.Fprintf(s.label, "all %d trees dropped", s.budget)
+	}
+	fmt.Fprintf(s.label, ", %d landed", len(a.world.Landings()))
+	a.win.SetMatrix(pixel.IM)
+	bottomLeft := a.win.Bounds().Min.Add(pixel.V(8, 8+s.label.Atlas().Descent()))
+	s.label.Draw(a.win, pixel.IM.Moved(bottomLeft))
+}
+
+// editorScreen builds contraptions, with the world standing still until
+// Enter sets it going. Nothing can be dragged about until then.
+type editorScreen struct {
+	path  string // Blueprint to build from and save to, if any
+	build *contraption
+}
+
+func (s *editorScreen) name() string { return "editor" }
+
+func (s *editorScreen) systems(a *app) {
+	a.sched.add(phaseInput, "contraption", func(dt time.Duration) { s.build.update(a.win, a.world) })
+	a.sched.add(phasePhysics, "step", func(dt time.Duration) {
+		if s.build.testing {
+			a.advance(dt)
+		}
+	})
+	a.sched.add(phaseUI, "contraption", func(dt time.Duration) { s.build.draw(a.win) })
+	a.sharedSystems()
+}
+
+func (s *editorScreen) enter(a *app) error {
+	build, err := newContraption(a.world, s.path)
+	if err != nil {
+		return err
+	}
+	s.build = build
+	return nil
+}
+
+// exit saves what's been built, logging rather than failing if it can't so
+// that leaving the editor always works
+func (s *editorScreen) exit(a *app) error {
+	if err := s.build.close(a.world); err != nil {
+		log.Printf("can't save the blueprint to %s: %v", s.build.path, err)
+	}
+	return nil
+}
+
+func (s *editorScreen) allow(c command) bool {
+	switch c.Do {
+	case "grab", "move", "drop":
+		return s.build.testing
+	}
+	return true
+}
+
+// replayScreen plays back a recording made with -record, or re-simulates a
+// replay made with -inputs. Either way, the world can only be watched.
+type replayScreen struct {
+	play  *player
+	rerun *rerun
+	fresh bool // A new frame of the recording needs showing
+}
+
+func (s *replayScreen) name() string { return "replay" }
+
+func (s *replayScreen) systems(a *app) {
+	if s.rerun != nil {
+		a.sched.add(phasePhysics, "step", func(dt time.Duration) {
+			steps, err := s.rerun.advance(a.world, a.tools, a.clock.dt, a.steps)
+			if err != nil {
+				log.Printf("stopped the replay: %v", err)
+			}
+			a.steps += steps
+		})
+		a.sharedSystems()
+		return
+	}
+	a.sched.add(phaseInput, "playback", func(dt time.Duration) {
+		changed, err := s.play.update(a.win, dt)
+		if err != nil {
+			log.Printf("stopped playback on the last good frame: %v", err)
+		}
+		s.fresh = s.fresh || changed
+	})
+	a.sched.add(phasePhysics, "step", func(dt time.Duration) {
+		if s.fresh {
+			puppet(a.world, s.play.frame)
+			s.fresh = false
+		}
+	})
+	a.sched.add(phaseUI, "timeline", func(dt time.Duration) { s.play.draw(a.win) })
+	a.sharedSystems()
+}
+
+// enter hands the recording or replay to the shared systems, which leave
+// their controls out while it runs
+func (s *replayScreen) enter(a *app) error {
+	a.play, a.rerun = s.play, s.rerun
+	s.fresh = false
+	return nil
+}
+
+func (s *replayScreen) exit(a *app) error {
+	a.play, a.rerun = nil, nil
+	return nil
+}
+
+func (s *replayScreen) allow(c command) bool { return false }
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/pixelgl"
+	"github.com/scottyw/falling-trees/camera"
+)
+
+// screenshotDir is where screenshots go when -screenshots doesn't say: next
+// to the executable, or the working directory if it can't be found
+func screenshotDir() string {
+	exe, err := os.Executable()
+	if err != nil {
+		return "."
+	}
+	return filepath.Dir(exe)
+}
+
+// screenshot saves the finished frame to a PNG named after the time it was
+// taken, exactly as it's shown in the window, and returns where it went. Call
+// it after everything has been drawn.
+func screenshot(win *pixelgl.Window, dir string, now time.Time) (string, error) {
+	tex := win.Canvas().Texture()
+	w, h := tex.Width(), tex.Height()
+	pix := win.Canvas().Pixels()
+
+	// The window is read from the bottom row up
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		copy(img.Pix[y*img.Stride:y*img.Stride+4*w], pix[(h-1-y)*4*w:(h-y)*4*w])
+	}
+
+	path := nextCapture(filepath.Join(dir, "screenshot-"+now.Format("20060102-150405")+".png"))
+	file, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	if err := png.Encode(file, img); err != nil {
+		file.Close()
+		return "", err
+	}
+	return path, file.Close()
+}
+
+// Save a screenshot with F12, logging the camera it was seen through so the
+// same view can be found again
+func (a *app) screenshot(dt time.Duration) {
+	if !a.win.JustPressed(pixelgl.KeyF12) {
+		return
+	}
+	dir := a.shots
+	if dir == "" {
+		dir = screenshotDir()
+	}
+	path, err := screenshot(a.win, dir, time.Now())
+	if err != nil {
+		log.Printf("can't save a screenshot: %v", err)
+		return
+	}
+	log.Printf("saved screenshot to %s, %s", path, describeView(a.view(), a.win.Bounds().Center()))
+}
+
+// describeView puts the view through a camera into words for the log
+func describeView(cam camera.Camera, centre pixel.Vec) string {
+	at := cam.ToWorld(centre)
+	return fmt.Sprintf("centred on %.1f,%.1f metres at zoom %.3g, turned %.1f degrees", at.X, at.Y, cam.Zoom, cam.Angle*180/math.Pi)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ByteArena/box2d"
+	"github.com/faiface/pixel"
+	"github.com/scottyw/falling-trees/recording"
+	"github.com/scottyw/falling-trees/trees"
+)
+
+// recorder writes the position of every tree to a file each frame
+type recorder struct {
+	file   *os.File
+	zip    *gzip.Writer // Compresses the recording when the file name ends in .gz
+	w      *recording.Writer
+	frame  recording.Frame
+	closed bool
+}
+
+func newRecorder(path string) (*recorder, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	r := &recorder{file: file}
+	var out io.Writer = file
+	if strings.HasSuffix(path, ".gz") {
+		r.zip = gzip.NewWriter(file)
+		out = r.zip
+	}
+	if r.w, err = recording.NewWriter(out); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *recorder) record(trees []*box2d.B2Body, elapsed time.Duration) error {
+	r.frame.Elapsed = elapsed
+	r.frame.Bodies = r.frame.Bodies[:0]
+	for _, tree := range trees {
+		p := tree.GetPosition()
+		r.frame.Bodies = append(r.frame.Bodies, recording.Transform{X: p.X, Y: p.Y, Angle: tree.GetAngle()})
+	}
+	return r.w.WriteFrame(r.frame)
+}
+
+// close finishes the recording, doing nothing if it's already closed
+func (r *recorder) close() error {
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	if err := r.w.Flush(); err != nil {
+		r.file.Close()
+		return err
+	}
+	if r.zip != nil {
+		if err := r.zip.Close(); err != nil {
+			r.file.Close()
+			return err
+		}
+	}
+	return r.file.Close()
+}
+
+// puppet moves trees to match a recorded frame, creating extra trees or
+// throwing away spare ones so there is exactly one per recorded body. The
+// trees are taken out of the simulation so the physics leaves them alone.
+func puppet(world *trees.World, frame recording.Frame) {
+	for len(world.Trees()) < len(frame.Bodies) {
+		world.SpawnTree(pixel.ZV).SetActive(false)
+	}
+	for len(world.Trees()) > len(frame.Bodies) {
+		spare := world.Trees()
+		world.RemoveTree(spare[len(spare)-1])
+	}
+	for i, b := range frame.Bodies {
+		world.Trees()[i].SetTransform(box2d.MakeB2Vec2(b.X, b.Y), b.Angle)
+	}
+}
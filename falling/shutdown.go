@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+)
+
+// shutdown runs cleanup hooks registered by subsystems when the demo exits,
+// whether the window was closed or the process was interrupted
+type shutdown struct {
+	mu    sync.Mutex
+	hooks []shutdownHook
+	done  bool
+}
+
+type shutdownHook struct {
+	name string
+	fn   func() error
+}
+
+// register adds a hook. Hooks run in reverse order of registration so that
+// subsystems are torn down the opposite way to how they were set up.
+func (s *shutdown) register(name string, fn func() error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hooks = append(s.hooks, shutdownHook{name: name, fn: fn})
+}
+
+// run calls every hook once, carrying on past any that fail so that one
+// broken subsystem doesn't stop the others cleaning up
+func (s *shutdown) run() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.done {
+		return
+	}
+	s.done = true
+	for i := len(s.hooks) - 1; i >= 0; i-- {
+		hook := s.hooks[i]
+		if err := hook.fn(); err != nil {
+			fmt.Fprintf(os.Stderr, "shutdown: %s: %v\n", hook.name, err)
+		}
+	}
+}
+
+// closeOnInterrupt asks the window to close on the first interrupt so that
+// the main loop ends and shutdown runs as normal. A second interrupt gives up
+// waiting and exits straight away.
+func closeOnInterrupt(close func()) {
+	interrupts := make(chan os.Signal, 2)
+	signal.Notify(interrupts, os.Interrupt)
+	go func() {
+		<-interrupts
+		close()
+		<-interrupts
+		os.Exit(1)
+	}()
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"github.com/faiface/pixel/pixelgl"
+	"github.com/faiface/pixel/text"
+	"github.com/scottyw/falling-trees/trees"
+	"golang.org/x/image/colornames"
+	"golang.org/x/image/font/basicfont"
+)
+
+// Events the demo publishes on the world's bus for the tutorial to wait for,
+// alongside the world's own
+type (
+	viewZoomed    struct{}
+	treeDropped   struct{}
+	toolChosen    struct{ Name string }
+	solverChanged struct{ Name string }
+)
+
+// Seconds the last step of the tutorial stays up before it closes itself
+const tutorialLinger = 6
+
+// tutorialStep asks the user to do something and waits for the event that
+// shows they've done it. Region, if set, picks out the part of the window the
+// step is about.
+type tutorialStep struct {
+	say    string
+	region func(bounds pixel.Rect) pixel.Rect
+	done   func(e trees.Event) bool
+}
+
+// bottomRight is the corner the physics and gravity are shown in
+func bottomRight(bounds pixel.Rect) pixel.Rect {
+	return pixel.R(bounds.Max.X-280, bounds.Min.Y+2, bounds.Max.X-2, bounds.Min.Y+60)
+}
+
+var tutorialSteps = []tutorialStep{
+	{
+		say: "Scroll the mouse wheel to zoom in on the mountain",
+		done: func(e trees.Event) bool {
+			_, ok := e.(viewZoomed)
+			return ok
+		},
+	},
+	{
+		say: "Hold Shift and click to drop a tree of your own",
+		done: func(e trees.Event) bool {
+			_, ok := e.(treeDropped)
+			return ok
+		},
+	},
+	{
+		say: "Press T until the title bar says explode",
+		done: func(e trees.Event) bool {
+			t, ok := e.(toolChosen)
+			return ok && t.Name == "explode"
+		},
+	},
+	{
+		say: "Right-click on the pile to blow it apart",
+		done: func(e trees.Event) bool {
+			_, ok := e.(trees.Explosion)
+			return ok
+		},
+	},
+	{
+		say:    "Press F6 to try different physics, shown down here",
+		region: bottomRight,
+		done: func(e trees.Event) bool {
+			_, ok := e.(solverChanged)
+			return ok
+		},
+	},
+	{
+		say: "That's the tour! The README has the rest, and F1 starts it again",
+	},
+}
+
+// tutorial walks through the demo one step at a time, moving on as soon as
+// the event each step waits for is published on the world's bus
+type tutorial struct {
+	step    int // Index into tutorialSteps, or -1 when the tutorial isn't running
+	elapsed float64
+	label   *text.Text
+	marks   *imdraw.IMDraw
+}
+
+func newTutorial(running bool) *tutorial {
+	label := text.New(pixel.ZV, text.NewAtlas(basicfont.Face7x13, text.ASCII))
+	label.Color = colornames.Black
+	t := &tutorial{step: -1, label: label, marks: imdraw.New(nil)}
+	if running {
+		t.step = 0
+	}
+	return t
+}
+
+// watch moves the tutorial on as the world's events come in
+func (t *tutorial) watch(events *trees.Bus) {
+	events.Subscribe(func(e trees.Event) {
+		if t.step < 0 {
+			return
+		}
+		if done := tutorialSteps[t.step].done; done != nil && done(e) {
+			t.step++
+			t.elapsed = 0
+		}
+	})
+}
+
+// update starts the tutorial over with F1, and closes it a little while
+// after the last step
+func (t *tutorial) update(win *pixelgl.Window, dt float64) {
+	if win.JustPressed(pixelgl.KeyF1) {
+		t.step, t.elapsed = 0, 0
+	}
+	if t.step < 0 {
+		return
+	}
+	t.elapsed += dt
+	if t.step == len(tutorialSteps)-1 && t.elapsed > tutorialLinger {
+		t.step = -1
+	}
+}
+
+func (t *tutorial) draw(win *pixelgl.Window) {
+	if t.step < 0 {
+		return
+	}
+	step := tutorialSteps[t.step]
+	bounds := win.Bounds()
+	t.label.Clear()
+	fmt.Fprintf(t.label, "%d/%d  %s", t.step+1, len(tutorialSteps), step.say)
+
+	// A box under the text, and a pulsing frame around the region if there is one
+	panel := t.label.Bounds().Moved(pixel.V(bounds.Center().X-t.label.Bounds().W()/2, bounds.Max.Y-64))
+	t.marks.Clear()
+	t.marks.Color = pixel.RGBA{R: 1, G: 1, B: 0.8, A: 1}.Scaled(0.9)
+	t.marks.Push(panel.Min.Sub(pixel.V(10, 8)), panel.Max.Add(pixel.V(10, 8)))
+	t.marks.Rectangle(0)
+	if step.region != nil {
+		r := step.region(bounds)
+		pulse := 0.6 + 0.4*math.Sin(t.elapsed*2*math.Pi)
+		t.marks.Color = pixel.ToRGBA(colornames.Orangered).Scaled(pulse)
+		t.marks.Push(r.Min, r.Max)
+		t.marks.Rectangle(4)
+	}
+	win.SetMatrix(pixel.IM)
+	t.marks.Draw(win)
+	t.label.Draw(win, pixel.IM.Moved(panel.Min.Sub(t.label.Bounds().Min)))
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"github.com/faiface/pixel/pixelgl"
+	"github.com/faiface/pixel/text"
+	"github.com/scottyw/falling-trees/trees"
+	"golang.org/x/image/colornames"
+	"golang.org/x/image/font/basicfont"
+)
+
+// Distance in pixels from the top of the window to the wave table, clear of
+// the frame timings
+const waveTableTop = 120
+
+// waveTable drops a new wave of trees with G, and F2 tints the trees by wave
+// with a table of how each wave is doing down the left of the window, so it's
+// clear how later waves bury earlier ones
+type waveTable struct {
+	size   int // Trees in each wave dropped with G
+	label  *text.Text
+	swatch *imdraw.IMDraw
+}
+
+func newWaveTable(size int) *waveTable {
+	label := text.New(pixel.ZV, text.NewAtlas(basicfont.Face7x13, text.ASCII))
+	label.Color = colornames.Black
+	return &waveTable{size: size, label: label, swatch: imdraw.New(nil)}
+}
+
+// update toggles the wave colours and reports whether G asked for a wave to
+// be dropped
+func (t *waveTable) update(win *pixelgl.Window, world *trees.World) bool {
+	if win.JustPressed(pixelgl.KeyF2) {
+		world.ShowWaves(!world.ShowingWaves())
+	}
+	return win.JustPressed(pixelgl.KeyG) && t.size > 0
+}
+
+func (t *waveTable) draw(win *pixelgl.Window, world *trees.World) {
+	if !world.ShowingWaves() {
+		return
+	}
+	t.label.Clear()
+	t.swatch.Clear()
+	fmt.Fprintln(t.label, "wave  trees  height  buried")
+	top := pixel.V(win.Bounds().Min.X+8, win.Bounds().Max.Y-waveTableTop)
+	for i, s := range world.WaveStats() {
+		fmt.Fprintf(t.label, "%4d  %5d  %5.1fm  %5.0f%%\n", s.Wave, s.Trees, s.MeanHeight, 100*s.Buried)
+		y := top.Y - float64(i+1)*t.label.LineHeight
+		t.swatch.Color = trees.WaveColour(s.Wave)
+		t.swatch.Push(pixel.V(top.X, y), pixel.V(top.X+10, y+10))
+		t.swatch.Rectangle(0)
+	}
+	win.SetMatrix(pixel.IM)
+	t.swatch.Draw(win)
+	t.label.Draw(win, pixel.IM.Moved(top.Add(pixel.V(16, -t.label.LineHeight))))
+}
@@ -0,0 +1,227 @@
+// Package gym is a reinforcement learning environment on the falling trees
+// simulation, in the style of OpenAI Gym. An agent drops trees one at a time
+// over a level, choosing where each one goes, and is rewarded for building the
+// pile higher without losing trees off the edge of the world.
+//
+//	env := gym.New(gym.DefaultConfig())
+//	obs := env.Reset(1)
+//	for done := false; !done; {
+//		var reward float64
+//		obs, reward, done = env.Step(gym.Action{X: choose(obs)})
+//	}
+//
+// Everything runs without a window, as fast as the physics can go.
+package gym
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/ByteArena/box2d"
+	"github.com/faiface/pixel"
+	"github.com/scottyw/falling-trees/trees"
+)
+
+const (
+	// Trees slower than this in metres per second count as settled
+	settledSpeed = 0.2
+
+	// How far above the top of the pile in metres each tree is dropped from
+	dropClearance = 3
+)
+
+// Config is how each episode is set up and scored
+type Config struct {
+	// Level the trees are dropped over, and the options its worlds are built
+	// with. The options' Trees and Rand are set by the environment.
+	Options trees.Options
+
+	// Trees dropped in an episode
+	Trees int
+
+	// Most seconds simulated after each drop for the tree to settle, cut
+	// short once everything has
+	Settle float64
+
+	// Physics steps per simulated second
+	Rate float64
+
+	// Number of columns the top of the pile is measured in across the
+	// level's drop area, for the observation
+	Columns int
+
+	// Cells across and up the occupancy grid of the world in the observation,
+	// or 0 to leave it out, and the area in metres it covers, all of Bounds if
+	// empty
+	Grid     int
+	GridArea pixel.Rect
+
+	// Reward lost for each tree that falls off the world, in metres of pile
+	// height, and for the fraction of trees still moving after a drop
+	FallPenalty   float64
+	MovingPenalty float64
+}
+
+// DefaultConfig drops fifty trees over the mountain, one every two seconds
+func DefaultConfig() Config {
+	return Config{
+		Options:       trees.DefaultOptions(),
+		Trees:         50,
+		Settle:        2,
+		Rate:          60,
+		Columns:       25,
+		Grid:          84,
+		FallPenalty:   1,
+		MovingPenalty: 1,
+	}
+}
+
+// Action is where the next tree is dropped, from 0 at the left of the level's
+// drop area to 1 at its right
+type Action struct {
+	X float64
+}
+
+// Observation is what the agent sees after each step
+type Observation struct {
+	// Height in metres of the top of the settled pile in each column across
+	// the drop area, from left to right, or 0 where there are no trees
+	Heights []float64
+
+	// Trees still moving, and trees left to drop in the episode
+	Moving int
+	Left   int
+
+	// What fills each cell of a grid over the world, if the config has one
+	Grid *trees.Occupancy `json:",omitempty"`
+}
+
+// Env is one environment, which runs one episode at a time. It isn't safe to
+// use from more than one goroutine at once.
+type Env struct {
+	cfg    Config
+	world  *trees.World
+	left   int
+	height float64 // Of the pile after the last step
+	lost   int     // Trees fallen off the world by the last step
+}
+
+// New makes an environment, which needs a Reset before its first Step
+func New(cfg Config) *Env {
+	return &Env{cfg: cfg}
+}
+
+// Reset starts a new episode with a fresh world and no trees, its randomness
+// seeded so the same seed and actions give the same episode
+func (e *Env) Reset(seed int64) Observation {
+	opts := e.cfg.Options
+	opts.Trees = 0
+	opts.Rand = rand.New(rand.NewSource(seed))
+	e.world = trees.NewWorld(opts)
+	if e.cfg.Grid > 0 {
+		area := e.cfg.GridArea
+		if area.Area() == 0 {
+			area = trees.Bounds
+		}
+		e.world.SetOccupancy(trees.NewOccupancy(area, e.cfg.Grid, e.cfg.Grid))
+	}
+	e.left, e.height, e.lost = e.cfg.Trees, 0, 0
+	return e.observe()
+}
+
+// Step drops a tree where the action says and lets the world settle, then
+// reports what the agent sees, its reward for the step and whether the
+// episode is over. The reward is how much higher the pile got, less the
+// penalties for trees lost off the world and for trees left moving.
+func (e *Env) Step(a Action) (Observation, float64, bool) {
+	if e.world == nil || e.left <= 0 {
+		return e.observe(), 0, true
+	}
+	drop := e.world.Level().Drop
+	x := drop.Min.X + pixel.Clamp(a.X, 0, 1)*drop.W()
+	e.world.SpawnTree(pixel.V(x, math.Max(drop.Min.Y, e.height+dropClearance)))
+	e.left--
+
+	dt := 1 / e.cfg.Rate
+	for i := 0; i < int(e.cfg.Settle*e.cfg.Rate); i++ {
+		e.world.Step(dt)
+		if e.settled() {
+			break
+		}
+	}
+
+	obs := e.observe()
+	height, lost := 0.0, e.fallen()
+	for _, h := range obs.Heights {
+		height = math.Max(height, h)
+	}
+	reward := height - e.height - e.cfg.FallPenalty*float64(lost-e.lost)
+	if placed := e.cfg.Trees - e.left; placed > 0 {
+		reward -= e.cfg.MovingPenalty * float64(obs.Moving) / float64(placed)
+	}
+	e.height, e.lost = height, lost
+	return obs, reward, e.left == 0
+}
+
+// World is the world of the current episode, for drawing or measuring
+func (e *Env) World() *trees.World {
+	return e.world
+}
+
+// observe measures the top of the settled pile in each column, and copies
+// the grid the world rasterized after its last step
+func (e *Env) observe() Observation {
+	obs := Observation{Heights: make([]float64, e.cfg.Columns), Left: e.left}
+	if e.world == nil {
+		return obs
+	}
+	if g := e.world.Occupancy(); g != nil {
+		grid := *g
+		grid.Cells = append([]trees.Occupant(nil), g.Cells...)
+		obs.Grid = &grid
+	}
+	drop := e.world.Level().Drop
+	width := drop.W() / float64(e.cfg.Columns)
+	for _, tree := range e.world.Trees() {
+		if moving(tree) {
+			obs.Moving++
+			continue
+		}
+		p := tree.GetPosition()
+		i := int((p.X - drop.Min.X) / width)
+		if i >= 0 && i < len(obs.Heights) {
+			obs.Heights[i] = math.Max(obs.Heights[i], p.Y+trees.TreeRadius)
+		}
+	}
+	return obs
+}
+
+// moving reports whether a tree hasn't settled yet
+func moving(tree *box2d.B2Body) bool {
+	v := tree.GetLinearVelocity()
+	return tree.IsAwake() && math.Hypot(v.X, v.Y) >= settledSpeed
+}
+
+// settled reports whether every tree has come to rest for long enough that
+// the physics has sent it to sleep, which a tree just dropped hasn't however
+// slowly it's moving
+func (e *Env) settled() bool {
+	for _, tree := range e.world.Trees() {
+		if tree.IsAwake() {
+			return false
+		}
+	}
+	return true
+}
+
+// fallen counts the trees that have fallen off the world, or are below the
+// bottom of it on their way off
+func (e *Env) fallen() int {
+	n := e.world.Fallen()
+	for _, tree := range e.world.Trees() {
+		if tree.GetPosition().Y < 0 {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,114 @@
+package gym
+
+import (
+	"math"
+	"testing"
+)
+
+const epsilon = 1e-9
+
+// config is a short episode with time for each tree to settle and nothing
+// taken off the reward, so that the reward is exactly how much the pile grew
+func config() Config {
+	cfg := DefaultConfig()
+	cfg.Trees = 5
+	cfg.Settle = 10
+	cfg.Grid = 16
+	cfg.FallPenalty = 0
+	cfg.MovingPenalty = 0
+	return cfg
+}
+
+// top is the height of the highest column in an observation
+func top(obs Observation) float64 {
+	height := 0.0
+	for _, h := range obs.Heights {
+		height = math.Max(height, h)
+	}
+	return height
+}
+
+// shape checks an observation has the columns and grid the config asks for
+func shape(t *testing.T, step int, cfg Config, obs Observation) {
+	t.Helper()
+	if len(obs.Heights) != cfg.Columns {
+		t.Errorf("step %d: %d columns, want %d", step, len(obs.Heights), cfg.Columns)
+	}
+	if obs.Grid == nil {
+		t.Fatalf("step %d: no grid", step)
+	}
+	if obs.Grid.Columns != cfg.Grid || obs.Grid.Rows != cfg.Grid || len(obs.Grid.Cells) != cfg.Grid*cfg.Grid {
+		t.Errorf("step %d: grid is %dx%d with %d cells, want %dx%d", step,
+			obs.Grid.Columns, obs.Grid.Rows, len(obs.Grid.Cells), cfg.Grid, cfg.Grid)
+	}
+}
+
+func TestEpisode(t *testing.T) {
+	cfg := config()
+	env := New(cfg)
+	obs := env.Reset(1)
+	shape(t, 0, cfg, obs)
+	if obs.Left != cfg.Trees || obs.Moving != 0 || top(obs) != 0 {
+		t.Errorf("reset to %d left, %d moving and a pile %v high, want %d, 0 and 0",
+			obs.Left, obs.Moving, top(obs), cfg.Trees)
+	}
+
+	// Drop every tree in the same place on the flat left of the mountain, which
+	// trees don't roll off, so the pile grows
+	var total float64
+	height := 0.0
+	for step := 1; step <= cfg.Trees; step++ {
+		obs, reward, done := env.Step(Action{X: 0.35})
+		shape(t, step, cfg, obs)
+		if obs.Left != cfg.Trees-step {
+			t.Errorf("step %d: %d left, want %d", step, obs.Left, cfg.Trees-step)
+		}
+		if want := top(obs) - height; math.Abs(reward-want) > epsilon {
+			t.Errorf("step %d: reward is %v, want %v for the pile growing", step, reward, want)
+		}
+		if done != (step == cfg.Trees) {
+			t.Errorf("step %d: done is %v with %d left", step, done, obs.Left)
+		}
+		total += reward
+		height = top(obs)
+	}
+	if height <= 0 {
+		t.Errorf("pile is %v high after dropping %d trees", height, cfg.Trees)
+	}
+	if math.Abs(total-height) > epsilon {
+		t.Errorf("rewards add up to %v, want the pile's height %v", total, height)
+	}
+
+	// Once it's over nothing more happens
+	obs, reward, done := env.Step(Action{X: 0.35})
+	if !done || reward != 0 || obs.Left != 0 || len(env.World().Trees()) != cfg.Trees {
+		t.Errorf("after the episode, done is %v and reward %v with %d trees", done, reward, len(env.World().Trees()))
+	}
+}
+
+func TestStepBeforeReset(t *testing.T) {
+	obs, reward, done := New(config()).Step(Action{X: 0.35})
+	if !done || reward != 0 || len(obs.Heights) != config().Columns {
+		t.Errorf("before a reset, done is %v and reward %v with %d columns", done, reward, len(obs.Heights))
+	}
+}
+
+func TestSameSeed(t *testing.T) {
+	// The same seed and actions give the same episode
+	run := func() []float64 {
+		env := New(config())
+		env.Reset(7)
+		var heights []float64
+		for _, x := range []float64{0.2, 0.8, 0.5} {
+			obs, _, _ := env.Step(Action{X: x})
+			heights = append(heights, obs.Heights...)
+		}
+		return heights
+	}
+	a, b := run(), run()
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("column %d is %v then %v", i, a[i], b[i])
+		}
+	}
+}
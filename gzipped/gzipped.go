@@ -0,0 +1,27 @@
+// Package gzipped reads files that may or may not have been gzipped, so that
+// compressed and plain copies of the same file read the same way.
+package gzipped
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+)
+
+// magic starts every gzip stream
+var magic = []byte{0x1f, 0x8b}
+
+// NewReader unwraps gzip if the input starts with it and passes anything else
+// through untouched
+func NewReader(in io.Reader) (io.Reader, error) {
+	buffered := bufio.NewReader(in)
+	start, err := buffered.Peek(len(magic))
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if !bytes.Equal(start, magic) {
+		return buffered, nil
+	}
+	return gzip.NewReader(buffered)
+}
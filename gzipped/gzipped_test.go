@@ -0,0 +1,58 @@
+package gzipped
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"testing"
+)
+
+// compressed gzips data in memory
+func compressed(t *testing.T, data string) string {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte(data))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestNewReader(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"empty", "", ""},
+		{"one byte", "\x1f", "\x1f"},
+		{"plain", "<?xml version=\"1.0\"?>", "<?xml version=\"1.0\"?>"},
+		{"almost gzip", "\x1f\x8a\x08", "\x1f\x8a\x08"},
+		{"gzip", compressed(t, "<?xml version=\"1.0\"?>"), "<?xml version=\"1.0\"?>"},
+		{"gzipped gzip", compressed(t, compressed(t, "twice")), compressed(t, "twice")},
+		{"gzipped nothing", compressed(t, ""), ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r, err := NewReader(bytes.NewReader([]byte(test.in)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := ioutil.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != test.want {
+				t.Errorf("read %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestNewReaderBadGzip(t *testing.T) {
+	// A gzip magic number followed by a broken header is an error rather than
+	// being passed through as plain data
+	if _, err := NewReader(bytes.NewReader([]byte{0x1f, 0x8b, 0})); err == nil {
+		t.Error("read a broken gzip header")
+	}
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"net"
+	"sort"
+
+	"github.com/faiface/pixel"
+	simv1 "github.com/scottyw/falling-trees/api/sim/v1"
+	"github.com/scottyw/falling-trees/trees"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// apiVersion is the version of the simulation API, which is also in the name
+// of the protobuf package it's served from, so a client written against one
+// version keeps working, or fails loudly, rather than being handed something
+// else
+const apiVersion = 1
+
+// Most steps one call to Step can take, so a typo can't stall the simulation
+const maxAPISteps = 100000
+
+// Snapshots a stream holds for a client that's behind before skipping them
+const snapshotBacklog = 16
+
+// simServer drives the world from another process over gRPC, such as a
+// Python notebook or a reinforcement learning agent, with clients generated
+// from api/sim/v1/sim.proto. Every call is handed to the main loop to run
+// between steps, since the world isn't safe to touch from anywhere else.
+type simServer struct {
+	simv1.UnimplementedSimServer
+	calls   chan func()
+	world   *trees.World
+	stats   *metrics
+	running *bool  // Whether the main loop steps the world in real time
+	stepper func() // Steps the world once at the current config's rate
+
+	// Snapshot streams, only touched on the main loop
+	watchers map[*watcher]bool
+}
+
+// watcher is one client's stream of snapshots
+type watcher struct {
+	every, since int
+	out          chan *simv1.SnapshotReply
+}
+
+// serveAPI serves the simulation API on a TCP address. The main loop runs the
+// server's calls and steps the world through it, so snapshots stream however
+// the world is stepped.
+func serveAPI(addr string, world *trees.World, stats *metrics, running *bool, step func()) (*simServer, error) {
+	s := &simServer{
+		calls:    make(chan func()),
+		world:    world,
+		stats:    stats,
+		running:  running,
+		stepper:  step,
+		watchers: map[*watcher]bool{},
+	}
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	server := grpc.NewServer()
+	simv1.RegisterSimServer(server, s)
+	simv1.RegisterEnvServer(server, newEnvServer())
+	reflection.Register(server)
+	go func() {
+		if err := server.Serve(l); err != nil {
+			log.Printf("api: %v", err)
+		}
+	}()
+	return s, nil
+}
+
+// do runs a call on the main loop and waits for it to finish
+func (s *simServer) do(call func() error) error {
+	done := make(chan error, 1)
+	s.calls <- func() { done <- call() }
+	return <-done
+}
+
+// step steps the world once on the main loop, sending a snapshot to every
+// stream that's due one
+func (s *simServer) step() {
+	s.stepper()
+	if len(s.watchers) == 0 {
+		return
+	}
+	var reply *simv1.SnapshotReply
+	for w := range s.watchers {
+		if w.since++; w.since < w.every {
+			continue
+		}
+		w.since = 0
+		if reply == nil {
+			snapshot, err := s.snapshot()
+			if err != nil {
+				log.Printf("api: %v", err)
+				return
+			}
+			reply = &simv1.SnapshotReply{Steps: s.stats.stepped(), Snapshot: snapshot}
+		}
+		// Skip the snapshot if the client is too far behind
+		select {
+		case w.out <- reply:
+		default:
+		}
+	}
+}
+
+// snapshot is the world as WriteSnapshot writes it
+func (s *simServer) snapshot() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := s.world.WriteSnapshot(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (s *simServer) Version(context.Context, *simv1.VersionRequest) (*simv1.VersionReply, error) {
+	return &simv1.VersionReply{Api: apiVersion, Snapshot: int32(trees.SnapshotFormat.Version())}, nil
+}
+
+func (s *simServer) Spawn(_ context.Context, req *simv1.SpawnRequest) (*simv1.SpawnReply, error) {
+	typ, ok := trees.LookupEntityType(req.Type)
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown entity type %q", req.Type)
+	}
+	reply := &simv1.SpawnReply{}
+	return reply, s.do(func() error {
+		reply.Id = s.world.Spawn(typ, pixel.V(req.X, req.Y)).ID
+		return nil
+	})
+}
+
+func (s *simServer) Step(_ context.Context, req *simv1.StepRequest) (*simv1.StepReply, error) {
+	if req.Steps < 0 || req.Steps > maxAPISteps {
+		return nil, status.Errorf(codes.InvalidArgument, "steps must be from 0 to %d", maxAPISteps)
+	}
+	reply := &simv1.StepReply{}
+	return reply, s.do(func() error {
+		for i := int32(0); i < req.Steps; i++ {
+			s.step()
+		}
+		reply.Steps, reply.Trees = s.stats.stepped(), int32(len(s.world.Trees()))
+		if req.Snapshot {
+			var err error
+			reply.Snapshot, err = s.snapshot()
+			return err
+		}
+		return nil
+	})
+}
+
+func (s *simServer) Query(_ context.Context, req *simv1.QueryRequest) (*simv1.QueryReply, error) {
+	reply := &simv1.QueryReply{}
+	return reply, s.do(func() error {
+		for _, e := range s.world.Query(pixel.R(req.MinX, req.MinY, req.MaxX, req.MaxY)) {
+			p, v := e.Body.GetPosition(), e.Body.GetLinearVelocity()
+			reply.Bodies = append(reply.Bodies, &simv1.Body{
+				Id:    e.ID,
+				Type:  e.Type.Name(),
+				X:     p.X,
+				Y:     p.Y,
+				Angle: e.Body.GetAngle(),
+				Vx:    v.X,
+				Vy:    v.Y,
+				Spin:  e.Body.GetAngularVelocity(),
+				Awake: e.Body.IsAwake(),
+			})
+		}
+		sort.Slice(reply.Bodies, func(i, j int) bool { return reply.Bodies[i].Id < reply.Bodies[j].Id })
+		return nil
+	})
+}
+
+func (s *simServer) Snapshot(context.Context, *simv1.SnapshotRequest) (*simv1.SnapshotReply, error) {
+	reply := &simv1.SnapshotReply{}
+	return reply, s.do(func() error {
+		var err error
+		reply.Steps = s.stats.stepped()
+		reply.Snapshot, err = s.snapshot()
+		return err
+	})
+}
+
+func (s *simServer) Snapshots(req *simv1.SnapshotsRequest, stream simv1.Sim_SnapshotsServer) error {
+	w := &watcher{every: int(req.Every), out: make(chan *simv1.SnapshotReply, snapshotBacklog)}
+	if w.every < 1 {
+		w.every = 1
+	}
+	s.do(func() error {
+		s.watchers[w] = true
+		return nil
+	})
+	defer s.do(func() error {
+		delete(s.watchers, w)
+		return nil
+	})
+	for {
+		select {
+		case reply := <-w.out:
+			if err := stream.Send(reply); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
+
+func (s *simServer) Restore(_ context.Context, req *simv1.RestoreRequest) (*simv1.RestoreReply, error) {
+	return &simv1.RestoreReply{}, s.do(func() error {
+		if err := s.world.ReadSnapshot(bytes.NewReader(req.Snapshot)); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil
+	})
+}
+
+func (s *simServer) Run(_ context.Context, req *simv1.RunRequest) (*simv1.RunReply, error) {
+	return &simv1.RunReply{}, s.do(func() error {
+		*s.running = req.Running
+		return nil
+	})
+}
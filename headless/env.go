@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"sync"
+
+	simv1 "github.com/scottyw/falling-trees/api/sim/v1"
+	"github.com/scottyw/falling-trees/gym"
+)
+
+// envServer serves a reinforcement learning environment alongside the
+// simulation API, with a world of its own that's only stepped by its
+// episodes
+type envServer struct {
+	simv1.UnimplementedEnvServer
+	mu  sync.Mutex
+	env *gym.Env
+}
+
+func newEnvServer() *envServer {
+	return &envServer{env: gym.New(gym.DefaultConfig())}
+}
+
+func (s *envServer) Reset(_ context.Context, req *simv1.ResetRequest) (*simv1.Observation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return observation(s.env.Reset(req.Seed)), nil
+}
+
+func (s *envServer) Step(_ context.Context, req *simv1.EnvStepRequest) (*simv1.EnvStepReply, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	obs, reward, done := s.env.Step(gym.Action{X: req.X})
+	return &simv1.EnvStepReply{Observation: observation(obs), Reward: reward, Done: done}, nil
+}
+
+// observation is what the agent sees, as the API sends it
+func observation(obs gym.Observation) *simv1.Observation {
+	reply := &simv1.Observation{Heights: obs.Heights, Moving: int32(obs.Moving), Left: int32(obs.Left)}
+	if g := obs.Grid; g != nil {
+		reply.Grid = &simv1.Occupancy{
+			MinX:    g.Area.Min.X,
+			MinY:    g.Area.Min.Y,
+			MaxX:    g.Area.Max.X,
+			MaxY:    g.Area.Max.Y,
+			Columns: int32(g.Columns),
+			Rows:    int32(g.Rows),
+			Cells:   make([]byte, len(g.Cells)),
+		}
+		for i, c := range g.Cells {
+			reply.Grid.Cells[i] = byte(c)
+		}
+	}
+	return reply
+}
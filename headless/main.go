@@ -0,0 +1,252 @@
+// Headless runs the falling trees simulation without a window, for long runs
+// on servers and under process supervisors.
+//
+// SIGTERM writes a checkpoint of the world before exiting, which can be picked
+// up again with -resume. SIGHUP reloads the config file. With -api, other
+// programs can spawn, step, query and stream snapshots of the world over gRPC.
+//
+// Every flag can also be set from an environment variable named after it,
+// such as TREES_CHECKPOINT for -checkpoint, which suits running in a
+// container. Flags given on the command line take precedence.
+package main
+
+import (
+	"compress/gzip"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/faiface/pixel"
+	"github.com/scottyw/falling-trees/storage"
+	"github.com/scottyw/falling-trees/trees"
+)
+
+var (
+	configPath     = flag.String("config", "", "JSON config file, reloaded on SIGHUP")
+	checkpointPath = flag.String("checkpoint", "checkpoint.json", "file or URL to write a checkpoint of the world to on SIGTERM")
+	resumePath     = flag.String("resume", "", "carry on from a checkpoint file or URL instead of scattering new trees")
+	seed           = flag.Int64("seed", 1, "seed for scattering the trees, so the same seed scatters them the same way every run")
+	treeCount      = flag.Int("trees", 800, "number of trees to scatter when not resuming")
+	duration       = flag.Duration("duration", 0, "write a checkpoint and exit after this long, 0 to run forever")
+	metricsAddr    = flag.String("metrics", "", "serve Prometheus metrics on this address, e.g. :9090")
+	solverName     = flag.String("solver", trees.DefaultOptions().Solver.Name, "solver profile: fast, balanced or accurate")
+	apiAddr        = flag.String("api", "", "serve the simulation API over gRPC on this address, e.g. :9091")
+	paused         = flag.Bool("paused", false, "leave the world standing still until it's stepped or set running through the API")
+)
+
+// envName is the environment variable that sets a flag
+func envName(flag string) string {
+	return "TREES_" + strings.ToUpper(strings.Replace(flag, "-", "_", -1))
+}
+
+// flagsFromEnv sets flags from the environment before the command line is
+// parsed, so that anything on the command line wins
+func flagsFromEnv() error {
+	var err error
+	flag.VisitAll(func(f *flag.Flag) {
+		if v, ok := os.LookupEnv(envName(f.Name)); ok && err == nil {
+			if setErr := f.Value.Set(v); setErr != nil {
+				err = fmt.Errorf("%s: %v", envName(f.Name), setErr)
+			}
+		}
+	})
+	return err
+}
+
+// config is the part of the simulation that can be changed while it runs.
+// Settings in the config file can be overridden with TREES_GRAVITY and
+// TREES_RATE.
+type config struct {
+	Version int `json:"version"`
+
+	// Vertical gravity in metres per second squared
+	Gravity float64 `json:"gravity"`
+
+	// Physics steps per second, in simulated time and real time alike
+	Rate float64 `json:"rate"`
+}
+
+// configFormat has no migrations yet, so every config file is version 0
+var configFormat = trees.Format{Name: "config"}
+
+func defaultConfig() config {
+	return config{Gravity: -10, Rate: 60}
+}
+
+func loadConfig(path string) (config, error) {
+	cfg := defaultConfig()
+	if path != "" {
+		file, err := os.Open(path)
+		if err != nil {
+			return cfg, err
+		}
+		defer file.Close()
+		if err := configFormat.Decode(file, &cfg); err != nil {
+			return cfg, err
+		}
+	}
+	for name, value := range map[string]*float64{"TREES_GRAVITY": &cfg.Gravity, "TREES_RATE": &cfg.Rate} {
+		if v, ok := os.LookupEnv(name); ok {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return cfg, fmt.Errorf("%s: %v", name, err)
+			}
+			*value = f
+		}
+	}
+	if cfg.Rate <= 0 {
+		cfg.Rate = defaultConfig().Rate
+	}
+	return cfg, nil
+}
+
+// checkpoint saves the world to a file or URL, leaving any earlier checkpoint
+// in place if it fails part way through. Checkpoints with names ending in .gz
+// are compressed.
+func checkpoint(world *trees.World, location string) error {
+	compress := strings.HasSuffix(strings.SplitN(location, "?", 2)[0], ".gz")
+	return storage.Save(location, func(out io.Writer) error {
+		return writeCheckpoint(world, out, compress)
+	})
+}
+
+func writeCheckpoint(world *trees.World, out io.Writer, compress bool) error {
+	if !compress {
+		return world.WriteSnapshot(out)
+	}
+	zip := gzip.NewWriter(out)
+	if err := world.WriteSnapshot(zip); err != nil {
+		return err
+	}
+	return zip.Close()
+}
+
+func resume(world *trees.World, location string) error {
+	file, err := storage.Load(location)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return world.ReadSnapshot(file)
+}
+
+func main() {
+	if err := flagsFromEnv(); err != nil {
+		log.Fatal(err)
+	}
+	flag.Parse()
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	opts := trees.DefaultOptions()
+	opts.Gravity = pixel.V(0, cfg.Gravity)
+	opts.Trees = *treeCount
+	opts.Rand = rand.New(rand.NewSource(*seed))
+	solver, ok := trees.LookupSolverProfile(*solverName)
+	if !ok {
+		log.Fatalf("unknown solver profile %q", *solverName)
+	}
+	opts.Solver = solver
+	if *resumePath != "" {
+		opts.Trees = 0
+	}
+	world := trees.NewWorld(opts)
+	if *resumePath != "" {
+		if err := resume(world, *resumePath); err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("resumed %d trees from %s", len(world.Trees()), *resumePath)
+	}
+
+	stats := &metrics{started: time.Now()}
+	if *metricsAddr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", stats)
+		go func() {
+			log.Fatal(http.ListenAndServe(*metricsAddr, mux))
+		}()
+	}
+
+	step := func() {
+		start := time.Now()
+		world.Step(1 / cfg.Rate)
+		stats.update(world, time.Since(start))
+	}
+
+	running := !*paused
+	var calls <-chan func()
+	if *apiAddr != "" {
+		api, err := serveAPI(*apiAddr, world, stats, &running, step)
+		if err != nil {
+			log.Fatal(err)
+		}
+		calls, step = api.calls, api.step
+		log.Printf("serving the simulation API on %s", *apiAddr)
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGHUP, os.Interrupt)
+
+	// Treat running out of time just like being asked to stop
+	var deadline <-chan time.Time
+	if *duration > 0 {
+		deadline = time.After(*duration)
+	}
+
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / cfg.Rate))
+	defer func() { ticker.Stop() }()
+	for {
+		select {
+		case <-ticker.C:
+			if !running {
+				continue
+			}
+			step()
+
+		case call := <-calls:
+			call()
+
+		case <-deadline:
+			if err := checkpoint(world, *checkpointPath); err != nil {
+				log.Fatal(err)
+			}
+			log.Printf("ran for %v, wrote checkpoint to %s", *duration, *checkpointPath)
+			return
+
+		case sig := <-signals:
+			switch sig {
+			case syscall.SIGHUP:
+				reloaded, err := loadConfig(*configPath)
+				if err != nil {
+					log.Printf("keeping the current config: %v", err)
+					continue
+				}
+				cfg = reloaded
+				world.SetGravity(pixel.V(0, cfg.Gravity))
+				ticker.Stop()
+				ticker = time.NewTicker(time.Duration(float64(time.Second) / cfg.Rate))
+				log.Printf("reloaded config from %s", *configPath)
+			case syscall.SIGTERM:
+				if err := checkpoint(world, *checkpointPath); err != nil {
+					log.Fatal(err)
+				}
+				log.Printf("wrote checkpoint to %s", *checkpointPath)
+				return
+			default:
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/scottyw/falling-trees/trees"
+)
+
+// metrics are updated by the simulation after every step and served over
+// HTTP in the Prometheus text format
+type metrics struct {
+	mu       sync.Mutex
+	started  time.Time
+	steps    uint64
+	bodies   int
+	awake    int
+	contacts int
+	stepTime time.Duration
+}
+
+func (m *metrics) update(world *trees.World, took time.Duration) {
+	awake := 0
+	for _, tree := range world.Trees() {
+		if tree.IsAwake() {
+			awake++
+		}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.steps++
+	m.bodies = len(world.Trees())
+	m.awake = awake
+	m.contacts = world.Physics().GetContactCount()
+	m.stepTime = took
+}
+
+// stepped counts the steps taken so far
+func (m *metrics) stepped() uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.steps
+}
+
+func (m *metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	fmt.Fprintf(w, "# HELP trees_uptime_seconds Time since the simulation started.\n")
+	fmt.Fprintf(w, "# TYPE trees_uptime_seconds gauge\n")
+	fmt.Fprintf(w, "trees_uptime_seconds %g\n", time.Since(m.started).Seconds())
+	fmt.Fprintf(w, "# HELP trees_steps_total Physics steps taken.\n")
+	fmt.Fprintf(w, "# TYPE trees_steps_total counter\n")
+	fmt.Fprintf(w, "trees_steps_total %d\n", m.steps)
+	fmt.Fprintf(w, "# HELP trees_bodies Trees in the world.\n")
+	fmt.Fprintf(w, "# TYPE trees_bodies gauge\n")
+	fmt.Fprintf(w, "trees_bodies %d\n", m.bodies)
+	fmt.Fprintf(w, "# HELP trees_awake_bodies Trees that are still moving.\n")
+	fmt.Fprintf(w, "# TYPE trees_awake_bodies gauge\n")
+	fmt.Fprintf(w, "trees_awake_bodies %d\n", m.awake)
+	fmt.Fprintf(w, "# HELP trees_contacts Contacts being tracked by the physics.\n")
+	fmt.Fprintf(w, "# TYPE trees_contacts gauge\n")
+	fmt.Fprintf(w, "trees_contacts %d\n", m.contacts)
+	fmt.Fprintf(w, "# HELP trees_step_seconds Time taken by the latest physics step.\n")
+	fmt.Fprintf(w, "# TYPE trees_step_seconds gauge\n")
+	fmt.Fprintf(w, "trees_step_seconds %g\n", m.stepTime.Seconds())
+}
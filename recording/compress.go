@@ -0,0 +1,24 @@
+package recording
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+
+	"github.com/scottyw/falling-trees/gzipped"
+)
+
+// decompressBytes unwraps a recording held in memory if it's gzipped. A
+// compressed recording that was cut off part way through keeps everything
+// before the cut.
+func decompressBytes(data []byte) ([]byte, error) {
+	zr, err := gzipped.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	plain, err := ioutil.ReadAll(zr)
+	if err == io.ErrUnexpectedEOF {
+		err = nil
+	}
+	return plain, err
+}
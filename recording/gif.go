@@ -0,0 +1,224 @@
+package recording
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/gif"
+	"io"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// GIFOptions control how a GIFWriter encodes frames
+type GIFOptions struct {
+	// Goroutines encoding frames at once, or zero for one per CPU
+	Workers int
+
+	// Frames that can wait to be encoded before new ones are dropped, or zero
+	// for two per worker
+	Queue int
+
+	// Each side of a frame is divided by Shrink before encoding, or left as
+	// it is if Shrink is zero or one
+	Shrink int
+
+	// Time each frame is shown for, to the nearest hundredth of a second
+	Delay time.Duration
+
+	// BottomUp means rows are given bottom row first, as read back from OpenGL
+	BottomUp bool
+}
+
+// GIFWriter encodes frames into an animated GIF that loops forever.
+// Shrinking, quantizing and compressing a frame takes far longer than a frame
+// lasts, so all of that happens on worker goroutines and AddFrame only hands
+// the frame over. If the workers fall behind, frames are dropped rather than
+// holding up the caller.
+type GIFWriter struct {
+	opts    GIFOptions
+	queue   chan gifFrame
+	encoded chan gifFrame
+	workers sync.WaitGroup
+	done    chan error
+	next    int
+	dropped int
+}
+
+// gifFrame is a frame on its way through the pipeline. Frames are numbered
+// as they're queued since workers can finish them in any order.
+type gifFrame struct {
+	seq           int
+	pix           []uint8
+	width, height int
+	data          []byte // The frame encoded on its own as a complete GIF
+	err           error
+}
+
+// NewGIFWriter starts the workers for a GIF written to w. Callers must Close
+// the GIFWriter to finish the file.
+func NewGIFWriter(w io.Writer, opts GIFOptions) *GIFWriter {
+	if opts.Workers <= 0 {
+		opts.Workers = runtime.NumCPU()
+	}
+	if opts.Queue <= 0 {
+		opts.Queue = 2 * opts.Workers
+	}
+	if opts.Shrink < 1 {
+		opts.Shrink = 1
+	}
+	g := &GIFWriter{
+		opts:    opts,
+		queue:   make(chan gifFrame, opts.Queue),
+		encoded: make(chan gifFrame, opts.Queue),
+		done:    make(chan error, 1),
+	}
+	for i := 0; i < opts.Workers; i++ {
+		g.workers.Add(1)
+		go g.work()
+	}
+	go g.write(w)
+	return g
+}
+
+// AddFrame queues a frame of RGBA pixels, four bytes each, for encoding. The
+// GIFWriter takes ownership of pix. It reports false if the frame was dropped
+// because the queue is full.
+func (g *GIFWriter) AddFrame(pix []uint8, width, height int) bool {
+	select {
+	case g.queue <- gifFrame{seq: g.next, pix: pix, width: width, height: height}:
+		g.next++
+		return true
+	default:
+		g.dropped++
+		return false
+	}
+}
+
+// Dropped counts the frames dropped so far because the workers were behind
+func (g *GIFWriter) Dropped() int {
+	return g.dropped
+}
+
+// Close waits for every queued frame to be written and finishes the file
+func (g *GIFWriter) Close() error {
+	close(g.queue)
+	g.workers.Wait()
+	close(g.encoded)
+	return <-g.done
+}
+
+func (g *GIFWriter) work() {
+	defer g.workers.Done()
+	for f := range g.queue {
+		var buf bytes.Buffer
+		f.err = gif.Encode(&buf, g.shrink(f), nil)
+		f.data, f.pix = buf.Bytes(), nil
+		g.encoded <- f
+	}
+}
+
+// shrink averages each square of Shrink by Shrink pixels into one, turning
+// the frame the right way up as it goes
+func (g *GIFWriter) shrink(f gifFrame) *image.RGBA {
+	s := g.opts.Shrink
+	out := image.NewRGBA(image.Rect(0, 0, f.width/s, f.height/s))
+	for y := 0; y < out.Rect.Dy(); y++ {
+		for x := 0; x < out.Rect.Dx(); x++ {
+			var sum [4]int
+			for dy := 0; dy < s; dy++ {
+				row := y*s + dy
+				if g.opts.BottomUp {
+					row = f.height - 1 - row
+				}
+				for dx := 0; dx < s; dx++ {
+					i := row*f.width*4 + (x*s+dx)*4
+					for c := range sum {
+						sum[c] += int(f.pix[i+c])
+					}
+				}
+			}
+			o := out.PixOffset(x, y)
+			for c := range sum {
+				out.Pix[o+c] = uint8(sum[c] / (s * s))
+			}
+		}
+	}
+	return out
+}
+
+// write stitches encoded frames into one GIF in the order they were queued.
+// The first frame supplies the header and colour table, which is the same for
+// every frame since they all use the standard palette.
+func (g *GIFWriter) write(w io.Writer) {
+	var err error
+	pending := map[int]gifFrame{}
+	next := 0
+	delay := int((g.opts.Delay + 5*time.Millisecond) / (10 * time.Millisecond))
+	for f := range g.encoded {
+		pending[f.seq] = f
+		for {
+			f, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			if err == nil {
+				err = f.err
+			}
+			if err == nil {
+				err = writeGIFFrame(w, f.data, next == 0, delay)
+			}
+			next++
+		}
+	}
+	if err == nil && next > 0 {
+		_, err = w.Write([]byte{0x3b})
+	}
+	g.done <- err
+}
+
+var errGIFFrame = errors.New("recording: unexpected GIF frame layout")
+
+// writeGIFFrame copies the image block out of a single frame GIF, prefixed
+// with a graphic control extension for its delay. The first frame also
+// writes the header and an application extension that loops the animation.
+func writeGIFFrame(w io.Writer, data []byte, first bool, delay int) error {
+	// Header, logical screen descriptor and global colour table
+	if len(data) < 13 {
+		return errGIFFrame
+	}
+	start := 13
+	if flags := data[10]; flags&0x80 != 0 {
+		start += 3 << (uint(flags&0x07) + 1)
+	}
+
+	// Skip any extensions the encoder wrote, then take everything up to the trailer
+	for start < len(data) && data[start] == 0x21 {
+		i := start + 2
+		for i < len(data) && data[i] != 0 {
+			i += int(data[i]) + 1
+		}
+		start = i + 1
+	}
+	if start >= len(data) || data[start] != 0x2c || data[len(data)-1] != 0x3b {
+		return errGIFFrame
+	}
+
+	var out []byte
+	if first {
+		out = append(out, data[:13]...)
+		out[4] = '9' // GIF89a, needed for the extensions
+		if data[10]&0x80 != 0 {
+			out = append(out, data[13:13+3<<(uint(data[10]&0x07)+1)]...)
+		}
+		out = append(out, 0x21, 0xff, 11)
+		out = append(out, "NETSCAPE2.0"...)
+		out = append(out, 3, 1, 0, 0, 0)
+	}
+	out = append(out, 0x21, 0xf9, 4, 0, byte(delay), byte(delay>>8), 0, 0)
+	out = append(out, data[start:len(data)-1]...)
+	_, err := w.Write(out)
+	return err
+}
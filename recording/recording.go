@@ -0,0 +1,296 @@
+// Package recording reads and writes a compact binary format for recording
+// the position and angle of every body in a simulation, frame by frame.
+//
+// A recording starts with a header:
+//
+//	magic       "FTREC"
+//	version     uvarint
+//	posScale    uvarint, quantization steps per metre
+//	angleScale  uvarint, quantization steps per radian
+//
+// followed by any number of frames:
+//
+//	elapsed     uvarint, microseconds since the previous frame
+//	bodies      uvarint, number of bodies in the frame
+//	changed     uvarint, number of bodies that moved since the previous frame
+//	changed times:
+//	  gap       uvarint, bodies skipped since the previous changed body
+//	  dx dy da  varint, change in quantized position and angle
+//
+// Bodies are identified by their index within the frame. A body that wasn't
+// in the previous frame is treated as having been at the origin. Quantizing
+// means a body at rest costs nothing at all, so a settled pile of thousands of
+// trees adds only a few bytes per frame.
+//
+// Recordings can also be compressed with gzip, which readers detect for
+// themselves.
+package recording
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/scottyw/falling-trees/gzipped"
+)
+
+const (
+	magic   = "FTREC"
+	version = 1
+
+	// Millimetre positions and roughly a quarter of a milliradian
+	defaultPosScale   = 1000
+	defaultAngleScale = 4096
+
+	// Most bodies a frame can add to the one before it, far more than any
+	// world spawns in a frame, so a corrupt count can't allocate without end
+	maxNewBodies = 1 << 16
+)
+
+// ErrFormat is returned when reading something that isn't a recording
+var ErrFormat = errors.New("recording: not a recording")
+
+// Transform is the position in metres and angle in radians of a body
+type Transform struct {
+	X, Y, Angle float64
+}
+
+// Frame is the state of every body at a moment in the recording
+type Frame struct {
+	Elapsed time.Duration // Time since the previous frame
+	Bodies  []Transform
+}
+
+type quantized struct {
+	x, y, angle int64
+}
+
+// Writer encodes frames to an underlying writer
+type Writer struct {
+	w          *bufio.Writer
+	posScale   float64
+	angleScale float64
+	prev       []quantized
+	buf        []byte
+}
+
+// NewWriter writes the recording header and returns a Writer ready for frames.
+// Callers must Flush the writer when they're done.
+func NewWriter(w io.Writer) (*Writer, error) {
+	rw := &Writer{
+		w:          bufio.NewWriter(w),
+		posScale:   defaultPosScale,
+		angleScale: defaultAngleScale,
+	}
+	rw.buf = append(rw.buf, magic...)
+	rw.buf = appendUvarint(rw.buf, version)
+	rw.buf = appendUvarint(rw.buf, defaultPosScale)
+	rw.buf = appendUvarint(rw.buf, defaultAngleScale)
+	if _, err := rw.w.Write(rw.buf); err != nil {
+		return nil, err
+	}
+	return rw, nil
+}
+
+// WriteFrame appends a frame to the recording
+func (rw *Writer) WriteFrame(f Frame) error {
+	current := make([]quantized, len(f.Bodies))
+	for i, b := range f.Bodies {
+		current[i] = quantized{
+			x:     int64(math.Round(b.X * rw.posScale)),
+			y:     int64(math.Round(b.Y * rw.posScale)),
+			angle: int64(math.Round(b.Angle * rw.angleScale)),
+		}
+	}
+
+	// Work out which bodies moved enough to register
+	var changed []int
+	for i, q := range current {
+		if i >= len(rw.prev) || q != rw.prev[i] {
+			changed = append(changed, i)
+		}
+	}
+
+	rw.buf = rw.buf[:0]
+	rw.buf = appendUvarint(rw.buf, uint64(f.Elapsed.Microseconds()))
+	rw.buf = appendUvarint(rw.buf, uint64(len(current)))
+	rw.buf = appendUvarint(rw.buf, uint64(len(changed)))
+	next := 0
+	for _, i := range changed {
+		var prev quantized
+		if i < len(rw.prev) {
+			prev = rw.prev[i]
+		}
+		q := current[i]
+		rw.buf = appendUvarint(rw.buf, uint64(i-next))
+		rw.buf = appendVarint(rw.buf, q.x-prev.x)
+		rw.buf = appendVarint(rw.buf, q.y-prev.y)
+		rw.buf = appendVarint(rw.buf, q.angle-prev.angle)
+		next = i + 1
+	}
+	rw.prev = current
+
+	_, err := rw.w.Write(rw.buf)
+	return err
+}
+
+// Flush writes any buffered data to the underlying writer
+func (rw *Writer) Flush() error {
+	return rw.w.Flush()
+}
+
+// Reader decodes frames from an underlying reader
+type Reader struct {
+	r      *bufio.Reader
+	header header
+	prev   []quantized
+}
+
+// NewReader reads the recording header and returns a Reader ready for frames
+func NewReader(r io.Reader) (*Reader, error) {
+	plain, err := gzipped.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	rr := &Reader{r: bufio.NewReader(plain)}
+	h, err := readHeader(rr.r)
+	if err != nil {
+		return nil, err
+	}
+	rr.header = h
+	return rr, nil
+}
+
+// ReadFrame decodes the next frame. It returns io.EOF once there are no more
+// frames and io.ErrUnexpectedEOF if the recording stops part way through one.
+func (rr *Reader) ReadFrame() (Frame, error) {
+	elapsed, current, err := decodeFrame(rr.r, rr.prev)
+	if err != nil {
+		return Frame{}, err
+	}
+	rr.prev = current
+	return rr.header.frame(elapsed, current), nil
+}
+
+// header holds the quantization used throughout a recording
+type header struct {
+	posScale   float64
+	angleScale float64
+}
+
+func readHeader(r io.ByteReader) (header, error) {
+	for i := 0; i < len(magic); i++ {
+		if b, err := r.ReadByte(); err != nil || b != magic[i] {
+			return header{}, ErrFormat
+		}
+	}
+	v, err := binary.ReadUvarint(r)
+	if err != nil {
+		return header{}, ErrFormat
+	}
+	if v != version {
+		return header{}, fmt.Errorf("recording: unsupported version %d", v)
+	}
+	posScale, err := binary.ReadUvarint(r)
+	if err != nil || posScale == 0 {
+		return header{}, ErrFormat
+	}
+	angleScale, err := binary.ReadUvarint(r)
+	if err != nil || angleScale == 0 {
+		return header{}, ErrFormat
+	}
+	return header{posScale: float64(posScale), angleScale: float64(angleScale)}, nil
+}
+
+// frame converts quantized state back into a Frame
+func (h header) frame(elapsed time.Duration, state []quantized) Frame {
+	f := Frame{
+		Elapsed: elapsed,
+		Bodies:  make([]Transform, len(state)),
+	}
+	for i, q := range state {
+		f.Bodies[i] = Transform{
+			X:     float64(q.x) / h.posScale,
+			Y:     float64(q.y) / h.posScale,
+			Angle: float64(q.angle) / h.angleScale,
+		}
+	}
+	return f
+}
+
+// decodeFrame reads one frame, applying its deltas to the previous state. It
+// returns io.EOF only if there is no frame at all.
+func decodeFrame(r io.ByteReader, prev []quantized) (time.Duration, []quantized, error) {
+	elapsed, err := binary.ReadUvarint(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	count, err := readUvarint(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	changed, err := readUvarint(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	if count > uint64(len(prev))+maxNewBodies || changed > count {
+		return 0, nil, ErrFormat
+	}
+
+	current := make([]quantized, count)
+	copy(current, prev)
+	next := uint64(0)
+	for ; changed > 0; changed-- {
+		gap, err := readUvarint(r)
+		if err != nil {
+			return 0, nil, err
+		}
+		i := next + gap
+		if i >= count {
+			return 0, nil, ErrFormat
+		}
+		var d [3]int64
+		for j := range d {
+			if d[j], err = readVarint(r); err != nil {
+				return 0, nil, err
+			}
+		}
+		current[i].x += d[0]
+		current[i].y += d[1]
+		current[i].angle += d[2]
+		next = i + 1
+	}
+	return time.Duration(elapsed) * time.Microsecond, current, nil
+}
+
+// readUvarint reads within a frame, where running out of data is unexpected
+func readUvarint(r io.ByteReader) (uint64, error) {
+	v, err := binary.ReadUvarint(r)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return v, err
+}
+
+// readVarint reads within a frame, where running out of data is unexpected
+func readVarint(r io.ByteReader) (int64, error) {
+	v, err := binary.ReadVarint(r)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return v, err
+}
+
+func appendUvarint(buf []byte, v uint64) []byte {
+	var scratch [binary.MaxVarintLen64]byte
+	return append(buf, scratch[:binary.PutUvarint(scratch[:], v)]...)
+}
+
+func appendVarint(buf []byte, v int64) []byte {
+	var scratch [binary.MaxVarintLen64]byte
+	return append(buf, scratch[:binary.PutVarint(scratch[:], v)]...)
+}
@@ -0,0 +1,149 @@
+package recording
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"testing"
+	"time"
+)
+
+// session is a few frames of bodies coming and going, some at rest, some at
+// the edge of a quantization step and some a long way from the origin
+var session = []Frame{
+	{Elapsed: 0, Bodies: []Transform{{0, 0, 0}, {1.25, -3.5, 0.5}}},
+	{Elapsed: 16667 * time.Microsecond, Bodies: []Transform{{0, 0, 0}, {1.25, -3.5, 0.5}, {0.0005, -0.0005, math.Pi}}},
+	{Elapsed: 16667 * time.Microsecond, Bodies: []Transform{{0, 0, 0}}},
+	{Elapsed: time.Second, Bodies: nil},
+	{Elapsed: 16667 * time.Microsecond, Bodies: []Transform{{1e9, -1e9, 1e6}, {-1e9 + 0.0004999, 1e9 - 0.0004999, -1e6}, {0, 0, 0}}},
+	{Elapsed: 16667 * time.Microsecond, Bodies: []Transform{{-1e9, 1e9, -1e6}, {1e9, -1e9, 1e6}, {0, 0, 0}}},
+	{Elapsed: 16667 * time.Microsecond, Bodies: []Transform{{-1e9, 1e9, -1e6}, {1e9, -1e9, 1e6}, {0, 0, 0}}},
+}
+
+// record writes frames to a recording in memory
+func record(t *testing.T, frames []Frame) []byte {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range frames {
+		if err := w.WriteFrame(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// same checks a frame read back against the one written, to within half a
+// quantization step
+func same(t *testing.T, i int, got, want Frame) {
+	t.Helper()
+	if got.Elapsed != want.Elapsed {
+		t.Errorf("frame %d lasted %v, want %v", i, got.Elapsed, want.Elapsed)
+	}
+	if len(got.Bodies) != len(want.Bodies) {
+		t.Fatalf("frame %d has %d bodies, want %d", i, len(got.Bodies), len(want.Bodies))
+	}
+	for j, b := range got.Bodies {
+		w := want.Bodies[j]
+		if math.Abs(b.X-w.X) > 0.5/defaultPosScale || math.Abs(b.Y-w.Y) > 0.5/defaultPosScale ||
+			math.Abs(b.Angle-w.Angle) > 0.5/defaultAngleScale {
+			t.Errorf("frame %d body %d is %v, want %v", i, j, b, w)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	r, err := NewReader(bytes.NewReader(record(t, session)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range session {
+		got, err := r.ReadFrame()
+		if err != nil {
+			t.Fatalf("frame %d: %v", i, err)
+		}
+		same(t, i, got, want)
+	}
+	if _, err := r.ReadFrame(); err != io.EOF {
+		t.Errorf("after the last frame, error is %v, want io.EOF", err)
+	}
+}
+
+func TestAtRest(t *testing.T) {
+	// A frame in which nothing moves is its elapsed time, body count and a
+	// changed count of zero
+	rest := Frame{Elapsed: time.Microsecond, Bodies: []Transform{{1, 2, 3}, {4, 5, 6}}}
+	one := record(t, []Frame{rest})
+	two := record(t, []Frame{rest, rest})
+	if extra := len(two) - len(one); extra != 3 {
+		t.Errorf("a frame at rest took %d bytes, want 3", extra)
+	}
+}
+
+func TestTruncated(t *testing.T) {
+	data := record(t, session)
+	header := len(record(t, nil))
+	for cut := 0; cut < len(data); cut++ {
+		r, err := NewReader(bytes.NewReader(data[:cut]))
+		if cut < header {
+			if err == nil {
+				t.Errorf("cut at %d: read a header from %d bytes", cut, cut)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("cut at %d: %v", cut, err)
+		}
+		var frames int
+		for {
+			_, err = r.ReadFrame()
+			if err != nil {
+				break
+			}
+			frames++
+		}
+		if frames >= len(session) {
+			t.Errorf("cut at %d: read all %d frames", cut, frames)
+		}
+		if err != io.EOF && err != io.ErrUnexpectedEOF && !errors.Is(err, ErrFormat) {
+			t.Errorf("cut at %d: error is %v", cut, err)
+		}
+	}
+}
+
+func TestCorrupt(t *testing.T) {
+	tests := []struct {
+		name  string
+		frame []byte
+	}{
+		{"more changed than bodies", []byte{0, 1, 2}},
+		{"change past the last body", []byte{0, 2, 1, 2, 0, 0, 0}},
+		{"too many new bodies", append(append([]byte{0}, appendUvarint(nil, maxNewBodies+1)...), 0)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data := append(record(t, nil), test.frame...)
+			r, err := NewReader(bytes.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := r.ReadFrame(); !errors.Is(err, ErrFormat) {
+				t.Errorf("error is %v, want ErrFormat", err)
+			}
+		})
+	}
+}
+
+func TestNotARecording(t *testing.T) {
+	for _, data := range []string{"", "FTRE", "FTREX", "GIF89a", "FTREC\x02\x01\x01"} {
+		if _, err := NewReader(bytes.NewReader([]byte(data))); err == nil {
+			t.Errorf("read %q as a recording", data)
+		}
+	}
+}
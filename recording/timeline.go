@@ -0,0 +1,116 @@
+package recording
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"time"
+)
+
+// A decoded copy of the state is kept every keyframeInterval frames so that
+// seeking never has to decode more than that many frames
+const keyframeInterval = 300
+
+// Timeline gives random access to a recording held in memory, for scrubbing
+// back and forth through it
+type Timeline struct {
+	data     []byte
+	header   header
+	offsets  []int           // Where each frame starts in data
+	times    []time.Duration // When each frame is shown, from the start of the recording
+	keys     [][]quantized   // State at every keyframeInterval'th frame
+	cached   int             // Most recently decoded frame, or -1
+	cachedAt []quantized
+}
+
+// NewTimeline indexes a complete recording. A final frame that was cut off
+// part way through, say by a crash while recording, is ignored.
+func NewTimeline(data []byte) (*Timeline, error) {
+	data, err := decompressBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	r := bytes.NewReader(data)
+	h, err := readHeader(r)
+	if err != nil {
+		return nil, err
+	}
+	t := &Timeline{data: data, header: h, cached: -1}
+	var state []quantized
+	var at time.Duration
+	for {
+		offset := len(data) - r.Len()
+		elapsed, current, err := decodeFrame(r, state)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		at += elapsed
+		if len(t.offsets)%keyframeInterval == 0 {
+			t.keys = append(t.keys, current)
+		}
+		t.offsets = append(t.offsets, offset)
+		t.times = append(t.times, at)
+		state = current
+	}
+	return t, nil
+}
+
+// Len is the number of frames in the recording
+func (t *Timeline) Len() int {
+	return len(t.offsets)
+}
+
+// Duration is how long the recording lasts
+func (t *Timeline) Duration() time.Duration {
+	if len(t.times) == 0 {
+		return 0
+	}
+	return t.times[len(t.times)-1]
+}
+
+// Index finds the frame being shown at a time from the start of the recording
+func (t *Timeline) Index(at time.Duration) int {
+	i := sort.Search(len(t.times), func(i int) bool { return t.times[i] > at }) - 1
+	if i < 0 {
+		return 0
+	}
+	return i
+}
+
+// Time is when a frame is shown, from the start of the recording
+func (t *Timeline) Time(i int) time.Duration {
+	return t.times[i]
+}
+
+// Frame decodes the i'th frame. Playing forwards one frame at a time is cheap
+// since decoding carries on from the previous call.
+func (t *Timeline) Frame(i int) (Frame, error) {
+	key := i / keyframeInterval * keyframeInterval
+	from, state := key, t.keys[key/keyframeInterval]
+	if t.cached >= from && t.cached <= i {
+		from, state = t.cached, t.cachedAt
+	}
+
+	// Frames are stored back to back so decode forwards from there
+	r := bytes.NewReader(t.data)
+	if from < i {
+		r.Seek(int64(t.offsets[from+1]), io.SeekStart)
+	}
+	for j := from + 1; j <= i; j++ {
+		_, next, err := decodeFrame(r, state)
+		if err != nil {
+			return Frame{}, err
+		}
+		state = next
+	}
+	t.cached, t.cachedAt = i, state
+
+	elapsed := t.times[i]
+	if i > 0 {
+		elapsed -= t.times[i-1]
+	}
+	return t.header.frame(elapsed, state), nil
+}
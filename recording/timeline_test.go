@@ -0,0 +1,151 @@
+package recording
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// long is enough frames to cross a couple of keyframes, with bodies moving
+// every frame and coming and going as it goes
+func long() []Frame {
+	frames := make([]Frame, 2*keyframeInterval+100)
+	for i := range frames {
+		bodies := make([]Transform, 1+i%7)
+		for j := range bodies {
+			bodies[j] = Transform{X: float64(i*j) * 0.01, Y: -float64(i+j) * 0.02, Angle: float64(j) * 0.1}
+		}
+		frames[i] = Frame{Elapsed: time.Duration(16000+i%3*1000) * time.Microsecond, Bodies: bodies}
+	}
+	return frames
+}
+
+// played reads every frame of a recording in order
+func played(t *testing.T, data []byte) []Frame {
+	r, err := NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var frames []Frame
+	for {
+		f, err := r.ReadFrame()
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return frames
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		frames = append(frames, f)
+	}
+}
+
+// seek checks frames picked at random from a timeline against the same
+// frames played in order
+func seek(t *testing.T, tl *Timeline, want []Frame) {
+	t.Helper()
+	if tl.Len() != len(want) {
+		t.Fatalf("timeline has %d frames, want %d", tl.Len(), len(want))
+	}
+	order := rand.New(rand.NewSource(1)).Perm(len(want))
+
+	// Step forwards and backwards from a few of them too
+	for _, i := range order[:len(order)/10] {
+		order = append(order, i, i+1, i-1)
+	}
+	for _, i := range order {
+		if i < 0 || i >= len(want) {
+			continue
+		}
+		got, err := tl.Frame(i)
+		if err != nil {
+			t.Fatalf("frame %d: %v", i, err)
+		}
+		same(t, i, got, want[i])
+	}
+}
+
+func TestTimeline(t *testing.T) {
+	frames := long()
+	tl, err := NewTimeline(record(t, frames))
+	if err != nil {
+		t.Fatal(err)
+	}
+	seek(t, tl, frames)
+
+	var at time.Duration
+	for i, f := range frames {
+		at += f.Elapsed
+		if got := tl.Time(i); got != at {
+			t.Errorf("frame %d is at %v, want %v", i, got, at)
+		}
+		if got := tl.Index(at); got != i {
+			t.Errorf("index at %v is %d, want %d", at, got, i)
+		}
+		if got := tl.Index(at + time.Microsecond); got != i {
+			t.Errorf("index just after %v is %d, want %d", at, got, i)
+		}
+	}
+	if got := tl.Duration(); got != at {
+		t.Errorf("duration is %v, want %v", got, at)
+	}
+	if got := tl.Index(-time.Second); got != 0 {
+		t.Errorf("index before the start is %d, want 0", got)
+	}
+	if got := tl.Index(at + time.Hour); got != len(frames)-1 {
+		t.Errorf("index after the end is %d, want %d", got, len(frames)-1)
+	}
+}
+
+func TestTimelineEmpty(t *testing.T) {
+	tl, err := NewTimeline(record(t, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tl.Len() != 0 || tl.Duration() != 0 {
+		t.Errorf("empty timeline has %d frames lasting %v", tl.Len(), tl.Duration())
+	}
+}
+
+func TestTimelineTruncated(t *testing.T) {
+	frames := long()
+	for _, n := range []int{0, 1, keyframeInterval - 1, keyframeInterval, keyframeInterval + 1, len(frames) - 1} {
+		// Cut one byte into the frame after the last complete one
+		data := record(t, frames)[:len(record(t, frames[:n]))+1]
+		tl, err := NewTimeline(data)
+		if err != nil {
+			t.Fatalf("cut after %d frames: %v", n, err)
+		}
+		seek(t, tl, frames[:n])
+	}
+}
+
+func TestTimelineGzipped(t *testing.T) {
+	frames := long()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write(record(t, frames))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	tl, err := NewTimeline(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seek(t, tl, frames)
+
+	// Whatever survives a cut in the compressed stream still plays
+	cut := data[:len(data)/2]
+	tl, err = NewTimeline(cut)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tl.Len() == 0 || tl.Len() >= len(frames) {
+		t.Errorf("half a compressed recording has %d of %d frames", tl.Len(), len(frames))
+	}
+	seek(t, tl, played(t, cut))
+}
@@ -0,0 +1,145 @@
+package recording
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+// VideoOptions control how a VideoWriter encodes frames
+type VideoOptions struct {
+	// Frames a second the video plays at
+	Rate int
+
+	// Frames that can wait to be piped to ffmpeg before new ones are dropped,
+	// or zero for eight
+	Queue int
+
+	// BottomUp means rows are given bottom row first, as read back from OpenGL
+	BottomUp bool
+
+	// The ffmpeg to run, or "ffmpeg" from the PATH if empty
+	FFmpeg string
+}
+
+// VideoWriter encodes frames into a video, such as an MP4, by piping them raw
+// to ffmpeg, which picks the format from the file name. ffmpeg starts with the
+// first frame since it needs to know the size up front, and later frames of
+// any other size are dropped. Like GIFWriter, AddFrame only hands the frame
+// over, dropping it rather than holding up the caller if ffmpeg falls behind.
+type VideoWriter struct {
+	path    string
+	opts    VideoOptions
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	stderr  bytes.Buffer
+	width   int
+	height  int
+	queue   chan []uint8
+	done    chan error
+	dropped int
+}
+
+// NewVideoWriter prepares a video written to path. Callers must Close the
+// VideoWriter to finish the file.
+func NewVideoWriter(path string, opts VideoOptions) *VideoWriter {
+	if opts.Queue <= 0 {
+		opts.Queue = 8
+	}
+	if opts.FFmpeg == "" {
+		opts.FFmpeg = "ffmpeg"
+	}
+	return &VideoWriter{path: path, opts: opts, queue: make(chan []uint8, opts.Queue), done: make(chan error, 1)}
+}
+
+// start runs ffmpeg for frames of the given size
+func (v *VideoWriter) start(width, height int) error {
+	filters := []string{
+		// Most players can only show even sizes
+		"crop=trunc(iw/2)*2:trunc(ih/2)*2",
+	}
+	if v.opts.BottomUp {
+		filters = append([]string{"vflip"}, filters...)
+	}
+	cmd := exec.Command(v.opts.FFmpeg,
+		"-y", "-loglevel", "error",
+		"-f", "rawvideo", "-pixel_format", "rgba",
+		"-video_size", fmt.Sprintf("%dx%d", width, height),
+		"-framerate", fmt.Sprint(v.opts.Rate),
+		"-i", "-",
+		"-vf", strings.Join(filters, ","),
+		"-pix_fmt", "yuv420p",
+		v.path,
+	)
+	cmd.Stderr = &v.stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("recording: can't run %s: %v", v.opts.FFmpeg, err)
+	}
+	v.cmd, v.stdin, v.width, v.height = cmd, stdin, width, height
+	go v.pipe()
+	return nil
+}
+
+// AddFrame queues a frame of RGBA pixels, four bytes each, for ffmpeg. The
+// VideoWriter takes ownership of pix. It reports false if the frame was
+// dropped, and an error if ffmpeg couldn't be started.
+func (v *VideoWriter) AddFrame(pix []uint8, width, height int) (bool, error) {
+	if v.cmd == nil {
+		if err := v.start(width, height); err != nil {
+			return false, err
+		}
+	}
+	if width != v.width || height != v.height {
+		v.dropped++
+		return false, nil
+	}
+	select {
+	case v.queue <- pix:
+		return true, nil
+	default:
+		v.dropped++
+		return false, nil
+	}
+}
+
+// Dropped counts the frames dropped so far because ffmpeg was behind or the
+// size changed
+func (v *VideoWriter) Dropped() int {
+	return v.dropped
+}
+
+// pipe writes queued frames to ffmpeg until the queue is closed. If ffmpeg
+// stops taking frames, the rest are thrown away so Close doesn't hang.
+func (v *VideoWriter) pipe() {
+	var err error
+	for pix := range v.queue {
+		if err == nil {
+			_, err = v.stdin.Write(pix)
+		}
+	}
+	if closeErr := v.stdin.Close(); err == nil {
+		err = closeErr
+	}
+	v.done <- err
+}
+
+// Close waits for every queued frame to be piped to ffmpeg and for ffmpeg to
+// finish the file
+func (v *VideoWriter) Close() error {
+	close(v.queue)
+	if v.cmd == nil {
+		// Never got a frame, so there's nothing to finish
+		return nil
+	}
+	err := <-v.done
+	if waitErr := v.cmd.Wait(); waitErr != nil {
+		return fmt.Errorf("recording: %s: %v: %s", v.opts.FFmpeg, waitErr, strings.TrimSpace(v.stderr.String()))
+	}
+	return err
+}
@@ -0,0 +1,63 @@
+// Package spline turns a handful of control points into a smooth curve that
+// passes through every one of them, using uniform Catmull-Rom splines.
+//
+// Curves are made of one span between each pair of neighbouring control
+// points. Each span is cut into a number of straight pieces for drawing or
+// for physics, and the more subdivisions, the smoother the result.
+package spline
+
+import (
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+)
+
+// Point finds the position a fraction t of the way along the span from p1 to
+// p2, with p0 and p3 the control points either side setting its shape
+func Point(p0, p1, p2, p3 pixel.Vec, t float64) pixel.Vec {
+	t2 := t * t
+	t3 := t2 * t
+	return p0.Scaled(-t3 + 2*t2 - t).
+		Add(p1.Scaled(3*t3 - 5*t2 + 2)).
+		Add(p2.Scaled(-3*t3 + 4*t2 + t)).
+		Add(p3.Scaled(t3 - t2)).
+		Scaled(0.5)
+}
+
+// Sample cuts the curve through a list of control points into straight pieces,
+// subdivisions per span, and returns the ends of the pieces. The first and
+// last control points are repeated so that the curve reaches them.
+func Sample(points []pixel.Vec, subdivisions int) []pixel.Vec {
+	if len(points) < 3 || subdivisions < 2 {
+		return append([]pixel.Vec(nil), points...)
+	}
+	at := func(i int) pixel.Vec {
+		if i < 0 {
+			return points[0]
+		}
+		if i >= len(points) {
+			return points[len(points)-1]
+		}
+		return points[i]
+	}
+	samples := make([]pixel.Vec, 0, (len(points)-1)*subdivisions+1)
+	for i := 0; i < len(points)-1; i++ {
+		for s := 0; s < subdivisions; s++ {
+			t := float64(s) / float64(subdivisions)
+			samples = append(samples, Point(at(i-1), at(i), at(i+1), at(i+2), t))
+		}
+	}
+	return append(samples, points[len(points)-1])
+}
+
+// Draw pushes the curve through a list of control points as a line of the
+// given thickness. Points and thickness are in whatever units imd draws in.
+func Draw(imd *imdraw.IMDraw, points []pixel.Vec, subdivisions int, thickness float64) {
+	samples := Sample(points, subdivisions)
+	if len(samples) < 2 {
+		return
+	}
+	for _, p := range samples {
+		imd.Push(p)
+	}
+	imd.Line(thickness)
+}
@@ -0,0 +1,105 @@
+package spline
+
+import (
+	"math"
+	"testing"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+)
+
+const epsilon = 1e-9
+
+func near(a, b pixel.Vec) bool {
+	return math.Abs(a.X-b.X) < epsilon && math.Abs(a.Y-b.Y) < epsilon
+}
+
+func TestPoint(t *testing.T) {
+	p0, p1, p2, p3 := pixel.V(-1, 3), pixel.V(0, 0), pixel.V(2, 1), pixel.V(5, -2)
+	if got := Point(p0, p1, p2, p3, 0); !near(got, p1) {
+		t.Errorf("start of the span is %v, want %v", got, p1)
+	}
+	if got := Point(p0, p1, p2, p3, 1); !near(got, p2) {
+		t.Errorf("end of the span is %v, want %v", got, p2)
+	}
+}
+
+func TestSample(t *testing.T) {
+	tests := []struct {
+		name   string
+		points []pixel.Vec
+	}{
+		{"three", []pixel.Vec{pixel.V(0, 0), pixel.V(1, 2), pixel.V(3, -1)}},
+		{"zigzag", []pixel.Vec{pixel.V(0, 0), pixel.V(1, 1), pixel.V(2, 0), pixel.V(3, 1), pixel.V(4, 0)}},
+		{"doubled back", []pixel.Vec{pixel.V(0, 0), pixel.V(4, 0), pixel.V(1, 0)}},
+		{"repeated point", []pixel.Vec{pixel.V(0, 0), pixel.V(1, 1), pixel.V(1, 1), pixel.V(2, 0)}},
+	}
+	const subdivisions = 8
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			samples := Sample(test.points, subdivisions)
+			if want := (len(test.points)-1)*subdivisions + 1; len(samples) != want {
+				t.Fatalf("%d samples, want %d", len(samples), want)
+			}
+
+			// Every control point is on the curve, the first and last included
+			for i, p := range test.points {
+				if got := samples[i*subdivisions]; !near(got, p) {
+					t.Errorf("sample at control point %d is %v, want %v", i, got, p)
+				}
+			}
+			for i, p := range samples {
+				if math.IsNaN(p.X) || math.IsNaN(p.Y) {
+					t.Errorf("sample %d is %v", i, p)
+				}
+			}
+			Draw(imdraw.New(nil), test.points, subdivisions, 1)
+		})
+	}
+}
+
+func TestSampleStraight(t *testing.T) {
+	// Repeating the end points keeps a curve through points in a line on that
+	// line, without overshooting either end
+	points := []pixel.Vec{pixel.V(0, 0), pixel.V(1, 1), pixel.V(3, 3)}
+	for i, p := range Sample(points, 10) {
+		if math.Abs(p.X-p.Y) > epsilon || p.X < -epsilon || p.X > 3+epsilon {
+			t.Errorf("sample %d is %v, off the line from %v to %v", i, p, points[0], points[2])
+		}
+	}
+}
+
+func TestSampleFew(t *testing.T) {
+	tests := []struct {
+		name         string
+		points       []pixel.Vec
+		subdivisions int
+	}{
+		{"none", nil, 8},
+		{"one", []pixel.Vec{pixel.V(1, 2)}, 8},
+		{"two", []pixel.Vec{pixel.V(1, 2), pixel.V(3, 4)}, 8},
+		{"no subdivisions", []pixel.Vec{pixel.V(0, 0), pixel.V(1, 2), pixel.V(3, -1)}, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Too little to curve comes back as it is, as a copy
+			samples := Sample(test.points, test.subdivisions)
+			if len(samples) != len(test.points) {
+				t.Fatalf("%d samples, want %d", len(samples), len(test.points))
+			}
+			for i, p := range test.points {
+				if samples[i] != p {
+					t.Errorf("sample %d is %v, want %v", i, samples[i], p)
+				}
+			}
+			if len(samples) > 0 {
+				samples[0] = pixel.V(-100, -100)
+				if test.points[0] == samples[0] {
+					t.Error("samples share the control points' storage")
+				}
+			}
+
+			Draw(imdraw.New(nil), test.points, test.subdivisions, 1)
+		})
+	}
+}
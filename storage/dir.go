@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"io"
+	"io/fs"
+	"net/url"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+// Dir is the files alongside a location, such as the images a map refers to,
+// for reading as an fs.FS. Names are taken to be relative to the location,
+// whether it's a path or a URL.
+func Dir(location string) fs.FS {
+	return dir{location}
+}
+
+type dir struct {
+	location string
+}
+
+func (d dir) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	location := filepath.Join(filepath.Dir(d.location), filepath.FromSlash(name))
+	if u, err := url.Parse(d.location); err == nil && len(u.Scheme) > 1 {
+		location = u.ResolveReference(&url.URL{Path: name}).String()
+	}
+	file, err := Load(location)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	return loaded{file, path.Base(name)}, nil
+}
+
+// loaded is a file opened from a backend, which knows nothing about it but
+// its name
+type loaded struct {
+	io.ReadCloser
+	name string
+}
+
+func (l loaded) Stat() (fs.FileInfo, error) { return l, nil }
+func (l loaded) Name() string               { return l.name }
+func (l loaded) Size() int64                { return 0 }
+func (l loaded) Mode() fs.FileMode          { return 0444 }
+func (l loaded) ModTime() time.Time         { return time.Time{} }
+func (l loaded) IsDir() bool                { return false }
+func (l loaded) Sys() interface{}           { return nil }
@@ -0,0 +1,35 @@
+package storage
+
+import (
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+// Files keeps files on the local disk
+type Files struct{}
+
+func (Files) Load(location *url.URL) (io.ReadCloser, error) {
+	return os.Open(filepath.FromSlash(location.Path))
+}
+
+// Save writes to a temporary file next to the real one first and renames it
+// into place, so a crash part way through never leaves half a file behind
+func (Files) Save(location *url.URL, write func(io.Writer) error) error {
+	path := filepath.FromSlash(location.Path)
+	tmp, err := os.Create(filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".tmp"))
+	if err != nil {
+		return err
+	}
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+// HTTP keeps files on a web server that answers GET and PUT, such as a
+// WebDAV share or an object store behind presigned URLs
+type HTTP struct {
+	// Client makes the requests, or nil for http.DefaultClient
+	Client *http.Client
+}
+
+func (h HTTP) client() *http.Client {
+	if h.Client == nil {
+		return http.DefaultClient
+	}
+	return h.Client
+}
+
+func (h HTTP) Load(location *url.URL) (io.ReadCloser, error) {
+	req, err := http.NewRequest(http.MethodGet, location.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	return fetch(h.client(), req)
+}
+
+// Save writes the whole file into memory first so that nothing is sent
+// unless write succeeds
+func (h HTTP) Save(location *url.URL, write func(io.Writer) error) error {
+	var body bytes.Buffer
+	if err := write(&body); err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPut, location.String(), bytes.NewReader(body.Bytes()))
+	if err != nil {
+		return err
+	}
+	return store(h.client(), req)
+}
+
+// fetch sends a request for a file and returns its body, turning error
+// statuses into errors
+func fetch(client *http.Client, req *http.Request) (io.ReadCloser, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := status(req, resp); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// store sends a request that saves a file
+func store(client *http.Client, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return status(req, resp)
+}
+
+func status(req *http.Request, resp *http.Response) error {
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return fmt.Errorf("storage: %s %s: %w", req.Method, where(req.URL), os.ErrNotExist)
+	case resp.StatusCode/100 != 2:
+		detail, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("storage: %s %s: %s %s", req.Method, where(req.URL), resp.Status, bytes.TrimSpace(detail))
+	}
+	return nil
+}
+
+// where names a location in errors, leaving out any credentials or signature
+// in the URL
+func where(u *url.URL) string {
+	return u.Scheme + "://" + u.Host + u.Path
+}
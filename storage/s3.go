@@ -0,0 +1,172 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3 keeps files as objects in S3 buckets, named s3://bucket/key. Requests
+// are signed with AWS signature version 4.
+type S3 struct {
+	// Credentials and region, or empty to use AWS_ACCESS_KEY_ID,
+	// AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN and AWS_REGION
+	AccessKey, SecretKey, SessionToken, Region string
+
+	// Endpoint such as http://localhost:9000 for an S3 compatible store,
+	// which is addressed by path rather than by bucket host name. Empty uses
+	// AWS_ENDPOINT_URL, or AWS itself if that isn't set either.
+	Endpoint string
+
+	// Client makes the requests, or nil for http.DefaultClient
+	Client *http.Client
+}
+
+// withEnv fills in anything left empty from the environment
+func (s S3) withEnv() S3 {
+	for _, v := range []struct {
+		value *string
+		env   string
+	}{
+		{&s.AccessKey, "AWS_ACCESS_KEY_ID"},
+		{&s.SecretKey, "AWS_SECRET_ACCESS_KEY"},
+		{&s.SessionToken, "AWS_SESSION_TOKEN"},
+		{&s.Region, "AWS_REGION"},
+		{&s.Endpoint, "AWS_ENDPOINT_URL"},
+	} {
+		if *v.value == "" {
+			*v.value = os.Getenv(v.env)
+		}
+	}
+	if s.Region == "" {
+		s.Region = "us-east-1"
+	}
+	return s
+}
+
+// object is the HTTP URL of an object
+func (s S3) object(location *url.URL) (*url.URL, error) {
+	bucket, key := location.Host, strings.TrimPrefix(location.Path, "/")
+	if bucket == "" || key == "" {
+		return nil, fmt.Errorf("storage: %s is not of the form s3://bucket/key", location)
+	}
+	if s.Endpoint == "" {
+		return &url.URL{Scheme: "https", Host: bucket + ".s3." + s.Region + ".amazonaws.com", Path: "/" + key}, nil
+	}
+	u, err := url.Parse(s.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + bucket + "/" + key
+	return u, nil
+}
+
+func (s S3) request(method string, location *url.URL, body []byte) (*http.Request, *http.Client, error) {
+	s = s.withEnv()
+	if s.AccessKey == "" || s.SecretKey == "" {
+		return nil, nil, errors.New("storage: no AWS credentials for " + location.String())
+	}
+	u, err := s.object(location)
+	if err != nil {
+		return nil, nil, err
+	}
+	req, err := http.NewRequest(method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, nil, err
+	}
+	s.sign(req, body, time.Now())
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return req, client, nil
+}
+
+func (s S3) Load(location *url.URL) (io.ReadCloser, error) {
+	req, client, err := s.request(http.MethodGet, location, nil)
+	if err != nil {
+		return nil, err
+	}
+	return fetch(client, req)
+}
+
+// Save writes the whole object into memory first, since the signature
+// covers a hash of everything sent
+func (s S3) Save(location *url.URL, write func(io.Writer) error) error {
+	var body bytes.Buffer
+	if err := write(&body); err != nil {
+		return err
+	}
+	req, client, err := s.request(http.MethodPut, location, body.Bytes())
+	if err != nil {
+		return err
+	}
+	return store(client, req)
+}
+
+// sign adds AWS signature version 4 headers to a request
+func (s S3) sign(req *http.Request, body []byte, now time.Time) {
+	stamp := now.UTC().Format("20060102T150405Z")
+	day := stamp[:8]
+	payload := sha256.Sum256(body)
+	req.Header.Set("X-Amz-Date", stamp)
+	req.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(payload[:]))
+	if s.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.SessionToken)
+	}
+
+	// Sign the host and every x-amz header, along with any range asked for
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		name = strings.ToLower(name)
+		if strings.HasPrefix(name, "x-amz-") || name == "range" {
+			headers[name] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	var names []string
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonical strings.Builder
+	for _, name := range names {
+		canonical.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signed := strings.Join(names, ";")
+
+	request := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.Query().Encode(),
+		canonical.String(),
+		signed,
+		hex.EncodeToString(payload[:]),
+	}, "\n")
+	hashed := sha256.Sum256([]byte(request))
+	scope := day + "/" + s.Region + "/s3/aws4_request"
+	toSign := "AWS4-HMAC-SHA256\n" + stamp + "\n" + scope + "\n" + hex.EncodeToString(hashed[:])
+
+	key := []byte("AWS4" + s.SecretKey)
+	for _, part := range []string{day, s.Region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, toSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.AccessKey, scope, signed, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
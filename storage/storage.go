@@ -0,0 +1,81 @@
+// Package storage loads and saves whole files wherever they're kept, chosen
+// by the scheme of their location. Plain paths and file: URLs are files on
+// disk, http: and https: URLs are fetched with GET and saved with PUT, and
+// s3: URLs name an object in an S3 bucket, as in s3://bucket/key.
+//
+// Other backends can be added with Register.
+package storage
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"sync"
+)
+
+// Backend keeps files at URLs with one particular scheme
+type Backend interface {
+	// Load opens the file at a location for reading. Files that aren't there
+	// give an error matching os.ErrNotExist with errors.Is.
+	Load(location *url.URL) (io.ReadCloser, error)
+
+	// Save replaces the file at a location with whatever write writes. If
+	// write fails the old file is left as it was.
+	Save(location *url.URL, write func(io.Writer) error) error
+}
+
+var (
+	mu       sync.Mutex
+	backends = map[string]Backend{
+		"":      Files{},
+		"file":  Files{},
+		"http":  HTTP{},
+		"https": HTTP{},
+		"s3":    S3{},
+	}
+)
+
+// Register makes a backend available for locations with a scheme, replacing
+// any backend already registered for it
+func Register(scheme string, b Backend) {
+	mu.Lock()
+	defer mu.Unlock()
+	backends[scheme] = b
+}
+
+// backend finds the backend for a location. Anything without a scheme is a
+// plain path and is passed on whole, since a # or ? in a file name doesn't
+// start a fragment or query.
+func backend(location string) (Backend, *url.URL, error) {
+	u, err := url.Parse(location)
+	if err != nil || len(u.Scheme) <= 1 {
+		// Not a URL, or a Windows path with a drive letter
+		return Files{}, &url.URL{Path: location}, nil
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	b, ok := backends[u.Scheme]
+	if !ok {
+		return nil, nil, fmt.Errorf("storage: no backend for %s: locations", u.Scheme)
+	}
+	return b, u, nil
+}
+
+// Load opens a file for reading from a path or URL
+func Load(location string) (io.ReadCloser, error) {
+	b, u, err := backend(location)
+	if err != nil {
+		return nil, err
+	}
+	return b.Load(u)
+}
+
+// Save replaces the file at a path or URL with whatever write writes, leaving
+// it as it was if write fails
+func Save(location string, write func(io.Writer) error) error {
+	b, u, err := backend(location)
+	if err != nil {
+		return err
+	}
+	return b.Save(u, write)
+}
@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"testing"
+)
+
+func TestBackend(t *testing.T) {
+	tests := []struct {
+		location string
+		backend  Backend
+		path     string
+	}{
+		{"saves/world.json", Files{}, "saves/world.json"},
+		{"saves/run#2.json", Files{}, "saves/run#2.json"},
+		{"a?b.json", Files{}, "a?b.json"},
+		{"/tmp/100%.json", Files{}, "/tmp/100%.json"},
+		{`C:\saves\world.json`, Files{}, `C:\saves\world.json`},
+		{"file:///tmp/run%232.json", Files{}, "/tmp/run#2.json"},
+		{"s3://bucket/key.json", S3{}, "/key.json"},
+	}
+	for _, test := range tests {
+		t.Run(test.location, func(t *testing.T) {
+			b, u, err := backend(test.location)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if b != test.backend {
+				t.Errorf("backend is %T, want %T", b, test.backend)
+			}
+			if u.Path != test.path {
+				t.Errorf("path is %q, want %q", u.Path, test.path)
+			}
+		})
+	}
+}
+
+func TestUnknownScheme(t *testing.T) {
+	if _, _, err := backend("gopher://host/file"); err == nil {
+		t.Error("no error for a scheme without a backend")
+	}
+}
@@ -0,0 +1,136 @@
+package trees
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/faiface/pixel"
+)
+
+// Blueprint is a design for a world rather than a moment in one: where
+// everything is placed, without any of the motion a Snapshot carries. Editors
+// work on a blueprint and build a fresh world from it each time they run it,
+// so every run starts the same way however the last one went.
+type Blueprint struct {
+	Version    int           `json:"version"`
+	Level      string        `json:"level,omitempty"`
+	Parts      []Part        `json:"parts"`
+	Paths      []PartPath    `json:"paths,omitempty"`
+	SlowFields []SlowField   `json:"slowFields,omitempty"`
+	Ridges     [][]pixel.Vec `json:"ridges,omitempty"`
+	Stamps     []StampState  `json:"stamps,omitempty"`
+}
+
+// Part is a tree or entity placed at a position in metres, turned by Angle
+// radians. Type is the name of a registered entity type, which is "tree" for
+// trees. Frozen parts are scenery until thawed.
+type Part struct {
+	Type   string  `json:"type"`
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Angle  float64 `json:"angle,omitempty"`
+	Frozen bool    `json:"frozen,omitempty"`
+}
+
+// PartPath sends one of the parts, given by its index in Parts, back and
+// forth along a path starting from the beginning
+type PartPath struct {
+	Part   int         `json:"part"`
+	Points []pixel.Vec `json:"points"`
+	Speed  float64     `json:"speed"`
+}
+
+// Add places a part and returns its index
+func (b *Blueprint) Add(p Part) int {
+	b.Parts = append(b.Parts, p)
+	return len(b.Parts) - 1
+}
+
+// Remove takes a part out along with any path it follows, keeping the other
+// paths pointing at the right parts
+func (b *Blueprint) Remove(i int) {
+	b.Parts = append(b.Parts[:i:i], b.Parts[i+1:]...)
+	paths := b.Paths[:0:0]
+	for _, p := range b.Paths {
+		switch {
+		case p.Part == i:
+			continue
+		case p.Part > i:
+			p.Part--
+		}
+		paths = append(paths, p)
+	}
+	b.Paths = paths
+}
+
+// Blueprint is the design the snapshot was taken of, with everything where it
+// is in the snapshot but standing still
+func (s Snapshot) Blueprint() Blueprint {
+	b := Blueprint{Version: BlueprintFormat.Version(), Level: s.Level, SlowFields: s.SlowFields, Ridges: s.Ridges, Stamps: s.Stamps}
+	for _, t := range s.Trees {
+		b.Add(Part{Type: (treeType{}).Name(), X: t.X, Y: t.Y, Angle: t.Angle, Frozen: t.Frozen})
+	}
+	for _, e := range s.Entities {
+		b.Add(Part{Type: e.Type, X: e.X, Y: e.Y, Angle: e.Angle, Frozen: e.Frozen})
+	}
+	for _, p := range s.Paths {
+		b.Paths = append(b.Paths, PartPath{Part: len(s.Trees) + p.Entity, Points: p.Points, Speed: p.Speed})
+	}
+	return b
+}
+
+// Snapshot is the moment just before the blueprint is set going, with
+// everything at rest and every path at its start
+func (b Blueprint) Snapshot() (Snapshot, error) {
+	s := Snapshot{Version: SnapshotFormat.Version(), Level: b.Level, SlowFields: b.SlowFields, Ridges: b.Ridges, Stamps: b.Stamps}
+	entity := make([]int, len(b.Parts))
+	for i, p := range b.Parts {
+		state := BodyState{X: p.X, Y: p.Y, Angle: p.Angle, Awake: true, Frozen: p.Frozen}
+		if p.Type == (treeType{}).Name() {
+			entity[i] = -1
+			s.Trees = append(s.Trees, state)
+			continue
+		}
+		entity[i] = len(s.Entities)
+		s.Entities = append(s.Entities, EntityState{Type: p.Type, BodyState: state})
+	}
+	for _, p := range b.Paths {
+		if p.Part < 0 || p.Part >= len(b.Parts) || entity[p.Part] < 0 {
+			return Snapshot{}, fmt.Errorf("trees: path for part %d, which isn't an entity", p.Part)
+		}
+		s.Paths = append(s.Paths, PathState{Entity: entity[p.Part], Points: p.Points, Speed: p.Speed})
+	}
+	return s, nil
+}
+
+// Blueprint captures where everything in the World is now as a design
+func (w *World) Blueprint() Blueprint {
+	return w.Snapshot().Blueprint()
+}
+
+// Build replaces everything in the World with a fresh build of a blueprint,
+// the same way Restore does for a snapshot
+func (w *World) Build(b Blueprint) error {
+	s, err := b.Snapshot()
+	if err != nil {
+		return err
+	}
+	return w.Restore(s)
+}
+
+// WriteBlueprint saves a blueprint as JSON
+func WriteBlueprint(out io.Writer, b Blueprint) error {
+	b.Version = BlueprintFormat.Version()
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "\t")
+	return enc.Encode(b)
+}
+
+// ReadBlueprint loads a blueprint written by WriteBlueprint, in this build or
+// an older one
+func ReadBlueprint(in io.Reader) (Blueprint, error) {
+	var b Blueprint
+	err := BlueprintFormat.Decode(in, &b)
+	return b, err
+}
@@ -0,0 +1,39 @@
+package trees
+
+import "github.com/faiface/pixel"
+
+// SetView tells Render which part of the world in metres will be seen, so it
+// can leave out the trees that would be drawn off screen, since zoomed in
+// most of them are. An empty rectangle draws every tree.
+func (w *World) SetView(view pixel.Rect) {
+	w.view = view.Norm()
+}
+
+// View is the part of the world Render draws trees in, set with SetView
+func (w *World) View() pixel.Rect {
+	return w.view
+}
+
+// Drawn counts the trees the last Render drew, leaving out those outside the
+// view
+func (w *World) Drawn() int {
+	return w.drawn
+}
+
+// inView reports whether anything reach metres or less from a point would
+// land in the view
+func (w *World) inView(at pixel.Vec, reach float64) bool {
+	if w.view.Area() == 0 {
+		return true
+	}
+	v := w.view
+	return at.X+reach >= v.Min.X && at.X-reach <= v.Max.X && at.Y+reach >= v.Min.Y && at.Y-reach <= v.Max.Y
+}
+
+// spriteReach is how far in metres a tree's sprite reaches from the centre
+// of its body at most, whichever way it's turned
+func spriteReach(sprite *pixel.Sprite, pivot pixel.Vec, size float64) float64 {
+	frame := sprite.Frame()
+	scale := 2 * size * TreeRadius / frame.W()
+	return (pivot.Len() + frame.Size().Len()/2) * scale
+}
@@ -0,0 +1,104 @@
+package trees
+
+import (
+	"github.com/ByteArena/box2d"
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"golang.org/x/image/colornames"
+)
+
+// Colours of the debug drawing
+var (
+	debugAABB   = pixel.ToRGBA(colornames.Darkgray).Mul(pixel.Alpha(0.6))
+	debugSprite = pixel.ToRGBA(colornames.Magenta)
+	debugOrigin = pixel.ToRGBA(colornames.Red)
+	debugCentre = pixel.ToRGBA(colornames.Blue)
+)
+
+// DrawDebug pushes outlines of what the physics is actually simulating, in
+// pixels, to draw over the world: the outline of every fixture in the tint
+// of its collision layer, faded for bodies that are asleep, inside the
+// bounding box the broadphase sorts it by, along with a cross at each body's
+// origin and a dot at its centre of mass. The frame each tree sprite is drawn
+// into is outlined too, so a sprite that doesn't line up with its body stands
+// out. Bodies are shown where the last step left them, while sprites are
+// drawn part of the way between steps, so the two can be a little apart while
+// trees move.
+func (w *World) DrawDebug(imd *imdraw.IMDraw) {
+	for _, body := range w.bodies() {
+		fade := pixel.Alpha(1)
+		if !body.IsAwake() && body.GetType() != box2d.B2BodyType.B2_staticBody {
+			fade = pixel.Alpha(0.4)
+		}
+		for f := body.GetFixtureList(); f != nil; f = f.GetNext() {
+			for child := 0; child < f.GetShape().GetChildCount(); child++ {
+				box := f.GetAABB(child)
+				imd.Color = debugAABB
+				imd.Push(pixel.V(box.LowerBound.X, box.LowerBound.Y).Scaled(PixelsPerMetre), pixel.V(box.UpperBound.X, box.UpperBound.Y).Scaled(PixelsPerMetre))
+				imd.Rectangle(1)
+			}
+			imd.Color = pixel.ToRGBA(layerTint(fixtureLayers(f).Category)).Mul(fade)
+			drawOutline(imd, body, f.GetShape())
+		}
+		o := body.GetPosition()
+		origin := pixel.V(o.X, o.Y).Scaled(PixelsPerMetre)
+		imd.Color = debugOrigin.Mul(fade)
+		imd.Push(origin.Add(pixel.V(-4, 0)), origin.Add(pixel.V(4, 0)))
+		imd.Line(1)
+		imd.Push(origin.Add(pixel.V(0, -4)), origin.Add(pixel.V(0, 4)))
+		imd.Line(1)
+		c := body.GetWorldCenter()
+		imd.Color = debugCentre.Mul(fade)
+		imd.Push(pixel.V(c.X, c.Y).Scaled(PixelsPerMetre))
+		imd.Circle(2, 0)
+	}
+
+	// Outline each sprite where Render draws it
+	imd.Color = debugSprite
+	for _, tree := range w.trees {
+		sprite, pivot := w.treeSprite(tree)
+		if sprite == nil {
+			break // Trees are circles
+		}
+		at, size := pixel.V(tree.GetWorldCenter().X, tree.GetWorldCenter().Y), 1.0
+		if e, ok := w.Lookup(tree); ok {
+			at, size = w.drawnAt(e), treeScale(e)
+		}
+		m := spriteMatrix(sprite, pivot, at.Scaled(PixelsPerMetre), size)
+		half := sprite.Frame().Size().Scaled(0.5)
+		for _, corner := range []pixel.Vec{{X: -half.X, Y: -half.Y}, {X: half.X, Y: -half.Y}, half, {X: -half.X, Y: half.Y}} {
+			imd.Push(m.Project(corner))
+		}
+		imd.Polygon(1)
+	}
+}
+
+// drawOutline pushes the outline of a shape on a body, with a line from the
+// centre of a circle to its edge to show which way it's turned
+func drawOutline(imd *imdraw.IMDraw, body *box2d.B2Body, shape box2d.B2ShapeInterface) {
+	point := func(v box2d.B2Vec2) pixel.Vec {
+		p := body.GetWorldPoint(v)
+		return pixel.V(p.X, p.Y).Scaled(PixelsPerMetre)
+	}
+	switch shape := shape.(type) {
+	case *box2d.B2CircleShape:
+		centre := point(shape.M_p)
+		imd.Push(centre)
+		imd.Circle(shape.M_radius*PixelsPerMetre, 1)
+		imd.Push(centre, point(box2d.MakeB2Vec2(shape.M_p.X+shape.M_radius, shape.M_p.Y)))
+		imd.Line(1)
+	case *box2d.B2PolygonShape:
+		for i := 0; i < shape.M_count; i++ {
+			imd.Push(point(shape.M_vertices[i]))
+		}
+		imd.Polygon(1)
+	case *box2d.B2EdgeShape:
+		imd.Push(point(shape.M_vertex1), point(shape.M_vertex2))
+		imd.Line(1)
+	case *box2d.B2ChainShape:
+		for i := 0; i < shape.M_count; i++ {
+			imd.Push(point(shape.M_vertices[i]))
+		}
+		imd.Line(1)
+	}
+}
@@ -0,0 +1,104 @@
+package trees
+
+import (
+	"math"
+
+	"github.com/ByteArena/box2d"
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"golang.org/x/image/colornames"
+)
+
+const (
+	// Most decals kept at once. The oldest go first once there are more.
+	maxDecals = 256
+
+	// Radius in metres of the smallest and largest dents
+	minDentSize = 0.15
+	maxDentSize = 0.6
+
+	// Radius in metres of a scorch mark, and how close to the terrain an
+	// explosion has to be to leave one
+	scorchSize  = 1.5
+	scorchReach = 3
+)
+
+// decal is a mark left on the terrain, fading away over the World's
+// DecalLifetime
+type decal struct {
+	at    pixel.Vec
+	size  float64
+	color pixel.RGBA
+	age   float64
+}
+
+// isTerrain reports whether a body is part of the landscape rather than
+// something that moves around on it
+func isTerrain(body *box2d.B2Body) bool {
+	for f := body.GetFixtureList(); f != nil; f = f.GetNext() {
+		if fixtureLayers(f).Category == LayerTerrain {
+			return true
+		}
+	}
+	return false
+}
+
+// addDecal marks the terrain, making room by dropping the oldest mark
+func (w *World) addDecal(d decal) {
+	if w.opts.DecalLifetime <= 0 {
+		return
+	}
+	if len(w.decals) == maxDecals {
+		w.decals = append(w.decals[:0], w.decals[1:]...)
+	}
+	w.decals = append(w.decals, d)
+}
+
+// dent marks the terrain where something landed hard enough
+func (w *World) dent(c Collision) {
+	if w.opts.DecalImpulse <= 0 || c.Impulse < w.opts.DecalImpulse {
+		return
+	}
+	if !isTerrain(c.A) && !isTerrain(c.B) {
+		return
+	}
+	size := minDentSize * c.Impulse / w.opts.DecalImpulse
+	w.addDecal(decal{at: c.Point, size: math.Min(size, maxDentSize), color: pixel.ToRGBA(colornames.Saddlebrown)})
+}
+
+// scorch marks the terrain near an explosion
+func (w *World) scorch(centre pixel.Vec) {
+	near := false
+	aabb := box2d.MakeB2AABB()
+	aabb.LowerBound.Set(centre.X-scorchReach, centre.Y-scorchReach)
+	aabb.UpperBound.Set(centre.X+scorchReach, centre.Y+scorchReach)
+	w.physics.QueryAABB(func(f *box2d.B2Fixture) bool {
+		near = fixtureLayers(f).Category == LayerTerrain
+		return !near
+	}, aabb)
+	if near {
+		w.addDecal(decal{at: centre, size: scorchSize, color: pixel.ToRGBA(colornames.Black)})
+	}
+}
+
+// ageDecals fades every decal by dt seconds, forgetting those that have gone
+func (w *World) ageDecals(dt float64) {
+	kept := w.decals[:0]
+	for _, d := range w.decals {
+		d.age += dt
+		if d.age < w.opts.DecalLifetime {
+			kept = append(kept, d)
+		}
+	}
+	w.decals = kept
+}
+
+// drawDecals draws each mark as a translucent blot that fades as it ages
+func (w *World) drawDecals(imd *imdraw.IMDraw) {
+	for _, d := range w.decals {
+		fade := 1 - d.age/w.opts.DecalLifetime
+		imd.Color = d.color.Scaled(0.5 * fade)
+		imd.Push(d.at.Scaled(PixelsPerMetre))
+		imd.Circle(d.size*PixelsPerMetre, 0)
+	}
+}
@@ -0,0 +1,59 @@
+package trees
+
+import (
+	"math"
+
+	"github.com/faiface/pixel"
+)
+
+// DensityGrid counts the trees and entities whose centres fall in each square
+// cell of an area. Cells run along rows from the bottom left corner of Area,
+// which is in metres.
+type DensityGrid struct {
+	Area          pixel.Rect
+	Cell          float64
+	Columns, Rows int
+	Counts        []int
+	Max           int // Most bodies in any one cell
+}
+
+// Count is the number of bodies in a cell
+func (g DensityGrid) Count(column, row int) int {
+	return g.Counts[row*g.Columns+column]
+}
+
+// CellRect is the area a cell covers in metres
+func (g DensityGrid) CellRect(column, row int) pixel.Rect {
+	min := g.Area.Min.Add(pixel.V(float64(column), float64(row)).Scaled(g.Cell))
+	return pixel.Rect{Min: min, Max: min.Add(pixel.V(g.Cell, g.Cell))}
+}
+
+// Density counts the bodies in every cell of a grid of the given cell size in
+// metres laid over an area. Cells at the top and right edges are cut short by
+// the area rather than spilling past it.
+func (w *World) Density(area pixel.Rect, cell float64) DensityGrid {
+	g := DensityGrid{
+		Area:    area,
+		Cell:    cell,
+		Columns: int(math.Ceil(area.W() / cell)),
+		Rows:    int(math.Ceil(area.H() / cell)),
+	}
+	g.Counts = make([]int, g.Columns*g.Rows)
+	for _, e := range w.Query(area) {
+		p := e.Body.GetWorldCenter()
+		if !area.Contains(pixel.V(p.X, p.Y)) {
+			continue
+		}
+		column := int((p.X - area.Min.X) / cell)
+		row := int((p.Y - area.Min.Y) / cell)
+		if column >= g.Columns || row >= g.Rows {
+			continue
+		}
+		i := row*g.Columns + column
+		g.Counts[i]++
+		if g.Counts[i] > g.Max {
+			g.Max = g.Counts[i]
+		}
+	}
+	return g
+}
@@ -0,0 +1,43 @@
+package trees
+
+import "github.com/ByteArena/box2d"
+
+// destroyQueue holds bodies waiting to be taken out of the world. Box2D can't
+// destroy bodies while it's stepping, so anything flagged during a step waits
+// until the step and its events are done.
+type destroyQueue struct {
+	bodies  []*box2d.B2Body
+	flagged map[*box2d.B2Body]bool
+}
+
+// Destroy flags a tree or entity for removal at the end of the current step,
+// or the next one if the world isn't stepping. It's safe to call from contact
+// listeners, tools and event subscribers, and flagging a body twice is harmless.
+func (w *World) Destroy(body *box2d.B2Body) {
+	if w.doomed.flagged[body] {
+		return
+	}
+	w.doomed.flagged[body] = true
+	w.doomed.bodies = append(w.doomed.bodies, body)
+}
+
+// flushDestroyed removes everything flagged with Destroy. Subscribers to the
+// resulting BodyDestroyed events can flag more bodies, which go in the same flush.
+func (w *World) flushDestroyed() {
+	for len(w.doomed.bodies) > 0 {
+		body := w.doomed.bodies[0]
+		w.doomed.bodies = w.doomed.bodies[1:]
+		delete(w.doomed.flagged, body)
+		e, ok := w.Lookup(body)
+		if !ok {
+			// Already gone, or never belonged to the world
+			continue
+		}
+		if _, isTree := e.Type.(treeType); isTree {
+			w.RemoveTree(body)
+		} else {
+			w.RemoveEntity(body)
+		}
+	}
+	w.doomed.bodies = nil
+}
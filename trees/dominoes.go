@@ -0,0 +1,204 @@
+package trees
+
+import (
+	"math"
+
+	"github.com/ByteArena/box2d"
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"golang.org/x/image/colornames"
+)
+
+// Half the size of a domino in metres, and how far apart the domino levels
+// stand them. Dominoes knock each other over as long as they're closer
+// together than they are tall.
+var dominoSize = pixel.V(0.2, 1.25)
+
+const dominoSpacing = 1.6
+
+// A domino leaning further than this in radians has toppled
+const dominoToppled = math.Pi / 4
+
+// Strength of the push tool in metres per second of sideways speed it gives
+// the top of whatever it pushes
+const pushSpeed = 2
+
+// dominoType spawns tall thin blocks that stand on end until knocked over
+type dominoType struct{}
+
+func (dominoType) Name() string { return "domino" }
+
+func (dominoType) Spawn(w *World, pos pixel.Vec) *box2d.B2Body {
+	bodyDef := box2d.MakeB2BodyDef()
+	bodyDef.Type = box2d.B2BodyType.B2_dynamicBody
+	bodyDef.Position.Set(pos.X, pos.Y)
+	body := w.physics.CreateBody(&bodyDef)
+	shape := box2d.MakeB2PolygonShape()
+	shape.SetAsBox(dominoSize.X, dominoSize.Y)
+	fixtureDef := box2d.MakeB2FixtureDef()
+	fixtureDef.Shape = &shape
+	fixtureDef.Density = 1
+	fixtureDef.Friction = 0.6
+	fixtureDef.Restitution = 0.05
+	body.CreateFixtureFromDef(&fixtureDef)
+	return body
+}
+
+func (dominoType) Draw(imd *imdraw.IMDraw, body *box2d.B2Body) {
+	imd.Color = colornames.Ivory
+	corners := []pixel.Vec{
+		pixel.V(-dominoSize.X, -dominoSize.Y),
+		pixel.V(dominoSize.X, -dominoSize.Y),
+		pixel.V(dominoSize.X, dominoSize.Y),
+		pixel.V(-dominoSize.X, dominoSize.Y),
+	}
+	for _, corner := range corners {
+		p := body.GetWorldPoint(box2d.MakeB2Vec2(corner.X, corner.Y))
+		imd.Push(pixel.V(p.X, p.Y).Scaled(PixelsPerMetre))
+	}
+	imd.Polygon(0)
+	imd.Color = colornames.Black
+	for _, corner := range corners {
+		p := body.GetWorldPoint(box2d.MakeB2Vec2(corner.X, corner.Y))
+		imd.Push(pixel.V(p.X, p.Y).Scaled(PixelsPerMetre))
+	}
+	imd.Polygon(1)
+}
+
+// Topple is a domino falling over. X is where it stood in metres and At is
+// the simulated time in seconds since the World was created.
+type Topple struct {
+	ID uint64
+	X  float64
+	At float64
+}
+
+// recordTopples notes every domino that has tipped over since the last step
+func (w *World) recordTopples() {
+	for _, e := range w.entities {
+		if _, isDomino := e.Type.(dominoType); !isDomino || w.toppled[e] {
+			continue
+		}
+		angle := math.Remainder(e.Body.GetAngle(), 2*math.Pi)
+		if math.Abs(angle) < dominoToppled {
+			continue
+		}
+		w.toppled[e] = true
+		w.topples = append(w.topples, Topple{ID: e.ID, X: e.Body.GetPosition().X, At: w.elapsed})
+	}
+}
+
+// Topples lists the dominoes that have fallen over, in the order they fell.
+// The slice belongs to the World and must not be modified.
+func (w *World) Topples() []Topple {
+	return w.topples
+}
+
+// ChainSpeed is how fast in metres per second toppling runs along a chain of
+// dominoes, from a straight line fitted to where and when they fell. It
+// reports false until at least three have fallen.
+func (w *World) ChainSpeed() (float64, bool) {
+	n := float64(len(w.topples))
+	if n < 3 {
+		return 0, false
+	}
+	var sumT, sumX, sumTT, sumTX float64
+	for _, t := range w.topples {
+		sumT += t.At
+		sumX += t.X
+		sumTT += t.At * t.At
+		sumTX += t.At * t.X
+	}
+	d := n*sumTT - sumT*sumT
+	if d <= 0 {
+		return 0, false
+	}
+	return math.Abs((n*sumTX - sumT*sumX) / d), true
+}
+
+// pushTool gives whatever is nearest the mouse a shove at the top, away from
+// the mouse, which is enough to start a chain of dominoes falling
+type pushTool struct{}
+
+func (pushTool) Name() string { return "push" }
+
+func (pushTool) Press(w *World, pos pixel.Vec) {
+	e := w.Pick(pos, 3)
+	if e == nil || e.Body.GetType() != box2d.B2BodyType.B2_dynamicBody {
+		return
+	}
+	centre := e.Body.GetWorldCenter()
+	direction := 1.0
+	if pos.X > centre.X {
+		direction = -1
+	}
+	top := centre.Y
+	for f := e.Body.GetFixtureList(); f != nil; f = f.GetNext() {
+		top = math.Max(top, f.GetAABB(0).UpperBound.Y)
+	}
+	impulse := box2d.MakeB2Vec2(direction*pushSpeed*e.Body.GetMass(), 0)
+	e.Body.ApplyLinearImpulse(impulse, box2d.MakeB2Vec2(centre.X, top), true)
+}
+
+func (pushTool) Drag(w *World, pos pixel.Vec)    {}
+func (pushTool) Release(w *World, pos pixel.Vec) {}
+
+// dominoLine stands dominoes on a flat surface at height y, along from x
+func dominoLine(w *World, x, y float64, count int) {
+	for i := 0; i < count; i++ {
+		w.Spawn(dominoType{}, pixel.V(x+float64(i)*dominoSpacing, y+dominoSize.Y))
+	}
+}
+
+// Layout of the domino staircases in metres
+const (
+	dominoSteps     = 8
+	dominoStepRise  = 0.75
+	dominoesPerStep = 5
+
+	// Dominoes are spaced the same across the edge of a step as along it
+	dominoStepWidth = dominoesPerStep * dominoSpacing
+)
+
+// dominoStairs builds a level of steps each with a few dominoes on it,
+// rising from left to right or falling if down is set
+func dominoStairs(name string, down bool) Level {
+	height := func(step int) float64 {
+		if down {
+			step = dominoSteps - 1 - step
+		}
+		return 1 + float64(step+1)*dominoStepRise
+	}
+	from := -dominoSteps * dominoStepWidth / 2.0
+	return Level{
+		Name: name,
+		Drop: mountain.Drop,
+		Build: func(ground *box2d.B2Body, imd *imdraw.IMDraw) {
+			imd.Color = colornames.Sandybrown
+			addBox(ground, imd, pixel.R(-50, -1, 50, 1))
+			for step := 0; step < dominoSteps; step++ {
+				x := from + float64(step)*dominoStepWidth
+				addBox(ground, imd, pixel.R(x, 1, x+dominoStepWidth, height(step)))
+			}
+		},
+		Setup: func(w *World) {
+			for step := 0; step < dominoSteps; step++ {
+				x := from + float64(step)*dominoStepWidth + dominoSpacing/2
+				dominoLine(w, x, height(step), dominoesPerStep)
+			}
+		},
+	}
+}
+
+func init() {
+	RegisterEntityType(dominoType{})
+	RegisterTool(pushTool{})
+	RegisterLevel(Level{
+		Name:  "dominoes",
+		Drop:  mountain.Drop,
+		Build: buildFlat,
+		Setup: func(w *World) { dominoLine(w, -40, 1, 50) },
+	})
+	RegisterLevel(dominoStairs("domino stairs", false))
+	RegisterLevel(dominoStairs("domino steps down", true))
+}
@@ -0,0 +1,40 @@
+package trees
+
+import (
+	"github.com/ByteArena/box2d"
+	"github.com/faiface/pixel"
+)
+
+// Gust shoves every tree sideways with an impulse in newton-seconds. Positive
+// strength blows to the right.
+func (w *World) Gust(strength float64) {
+	impulse := box2d.MakeB2Vec2(strength, strength/4)
+	for _, tree := range w.trees {
+		tree.ApplyLinearImpulseToCenter(impulse, true)
+	}
+}
+
+// Explode blasts every dynamic body away from a centre given in metres. Bodies
+// at the centre receive the full impulse, falling away to nothing at the edge
+// of the radius. Explosions near the terrain leave a scorch mark on it.
+func (w *World) Explode(centre pixel.Vec, radius, impulse float64) {
+	w.scorch(centre)
+	w.events.Publish(Explosion{Centre: centre, Radius: radius, Impulse: impulse})
+	for _, body := range w.bodies() {
+		if body.GetType() != box2d.B2BodyType.B2_dynamicBody {
+			continue
+		}
+		p := body.GetWorldCenter()
+		offset := pixel.V(p.X, p.Y).Sub(centre)
+		distance := offset.Len()
+		if distance >= radius {
+			continue
+		}
+		direction := pixel.V(0, 1)
+		if distance > 0 {
+			direction = offset.Unit()
+		}
+		push := direction.Scaled(impulse * (1 - distance/radius))
+		body.ApplyLinearImpulseToCenter(box2d.MakeB2Vec2(push.X, push.Y), true)
+	}
+}
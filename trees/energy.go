@@ -0,0 +1,39 @@
+package trees
+
+import (
+	"github.com/ByteArena/box2d"
+)
+
+// Energy is the mechanical energy of everything moving in a world, in joules.
+// Potential energy is measured from a height of zero against gravity as it
+// is right now, tide and all.
+type Energy struct {
+	Kinetic   float64 // Of moving and spinning
+	Potential float64
+}
+
+// Total is the kinetic and potential energy together
+func (e Energy) Total() float64 {
+	return e.Kinetic + e.Potential
+}
+
+// Energy adds up the energy of every body the physics moves. Left alone, a
+// world only ever loses energy, to damping, friction and inelastic bounces,
+// so a step that gains some without anything being spawned, pushed or blown
+// up is a sign that the solver has gone unstable.
+func (w *World) Energy() Energy {
+	var e Energy
+	g := w.physics.GetGravity()
+	for _, body := range w.bodies() {
+		if body.GetType() != box2d.B2BodyType.B2_dynamicBody {
+			continue
+		}
+		m := body.GetMass()
+		v := body.GetLinearVelocity()
+		spin := body.GetAngularVelocity()
+		e.Kinetic += 0.5*m*(v.X*v.X+v.Y*v.Y) + 0.5*body.GetInertia()*spin*spin
+		p := body.GetWorldCenter()
+		e.Potential -= m * (g.X*p.X + g.Y*p.Y)
+	}
+	return e
+}
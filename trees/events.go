@@ -0,0 +1,217 @@
+package trees
+
+import (
+	"github.com/ByteArena/box2d"
+	"github.com/faiface/pixel"
+)
+
+// Event is anything published on a Bus. Subscribers tell events apart with a
+// type switch, and plugins are free to publish event types of their own.
+type Event interface{}
+
+// BodySpawned is published when something is added to a World
+type BodySpawned struct {
+	Body *box2d.B2Body
+	Type EntityType
+}
+
+// BodyDestroyed is published just before something is removed from a World
+type BodyDestroyed struct {
+	Body *box2d.B2Body
+}
+
+// Collision is published when two bodies first hit each other. Impulse is the
+// largest normal impulse in newton-seconds at any point of contact, and Point is
+// where they touched in metres. EntityA and EntityB are the entities the bodies
+// belong to, or nil for the ground.
+type Collision struct {
+	A, B             *box2d.B2Body
+	EntityA, EntityB *Entity
+	Point            pixel.Vec
+	Impulse          float64
+}
+
+// Stepped is published at the very end of every step, once everything the
+// step set off has been published, with the seconds it simulated
+type Stepped struct {
+	Dt float64
+}
+
+// Hitch is published when Advance is asked to move on by more than
+// MaxAdvance, with how long it was asked for and how much of that was dropped,
+// in seconds
+type Hitch struct {
+	Elapsed float64
+	Dropped float64
+}
+
+// Explosion is published when Explode blasts things away from a centre in
+// metres
+type Explosion struct {
+	Centre  pixel.Vec
+	Radius  float64
+	Impulse float64
+}
+
+// GravityChanged is published when SetGravity, SetGravityPreset or SetTide
+// change the gravity, with the gravity and tide the World has now
+type GravityChanged struct {
+	Gravity pixel.Vec
+	Tide    Tide
+}
+
+// LevelLoaded is published once a World has finished building its terrain
+type LevelLoaded struct {
+	Name string
+}
+
+// ModeChanged is published by applications when they switch between modes
+// such as playing back a recording or touring the world on their own
+type ModeChanged struct {
+	Mode string
+}
+
+// Bus delivers events to every subscriber in the order they subscribed.
+// Events are delivered immediately on the publishing goroutine.
+type Bus struct {
+	subscribers []subscriber
+	next        int
+}
+
+type subscriber struct {
+	id int
+	fn func(Event)
+}
+
+// NewBus creates a bus with no subscribers
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe calls fn with every event published from now on. Call the
+// returned function to stop.
+func (b *Bus) Subscribe(fn func(Event)) func() {
+	id := b.next
+	b.next++
+	b.subscribers = append(b.subscribers, subscriber{id: id, fn: fn})
+	return func() {
+		for i, s := range b.subscribers {
+			if s.id == id {
+				b.subscribers = append(b.subscribers[:i:i], b.subscribers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Publish delivers an event to every subscriber
+func (b *Bus) Publish(e Event) {
+	for _, s := range b.subscribers {
+		s.fn(e)
+	}
+}
+
+// contacts listens to Box2D during a step and turns new contacts into
+// Collision events. Box2D is mid-step when it calls back, so events are held
+// until the step is over and subscribers are free to change the world.
+type contacts struct {
+	fresh   map[box2d.B2ContactInterface]bool
+	pending []Event
+
+	// The body the World is measuring forces on, and what's been measured
+	// during the current step
+	watch   *box2d.B2Body
+	watched []contactForce
+}
+
+func (c *contacts) BeginContact(contact box2d.B2ContactInterface) {
+	c.fresh[contact] = true
+}
+
+func (c *contacts) EndContact(contact box2d.B2ContactInterface) {
+	delete(c.fresh, contact)
+}
+
+func (c *contacts) PreSolve(contact box2d.B2ContactInterface, oldManifold box2d.B2Manifold) {}
+
+// PostSolve reports the first solve of each new contact since that's when
+// the impulse of the impact is known. It also measures the forces on the
+// watched body.
+func (c *contacts) PostSolve(contact box2d.B2ContactInterface, impulse *box2d.B2ContactImpulse) {
+	if c.watch != nil {
+		c.watchContact(contact, impulse)
+	}
+	if !c.fresh[contact] {
+		return
+	}
+	delete(c.fresh, contact)
+
+	count := contact.GetManifold().PointCount
+	if count == 0 {
+		return
+	}
+	var manifold box2d.B2WorldManifold
+	contact.GetWorldManifold(&manifold)
+	strongest := 0
+	for i := 1; i < count; i++ {
+		if impulse.NormalImpulses[i] > impulse.NormalImpulses[strongest] {
+			strongest = i
+		}
+	}
+	p := manifold.Points[strongest]
+	c.pending = append(c.pending, Collision{
+		A:       contact.GetFixtureA().GetBody(),
+		B:       contact.GetFixtureB().GetBody(),
+		Point:   pixel.V(p.X, p.Y),
+		Impulse: impulse.NormalImpulses[strongest],
+	})
+}
+
+// bodyPair is an unordered pair of bodies that have collided
+type bodyPair struct {
+	a, b *box2d.B2Body
+}
+
+// collisionFilter drops collisions too soft to matter and repeat hits between
+// the same pair of bodies, so a pile jostling at rest doesn't flood subscribers
+type collisionFilter struct {
+	minImpulse float64
+	cooldown   float64
+	now        float64
+	pruned     float64
+	last       map[bodyPair]float64
+}
+
+// advance moves the filter's clock on by dt seconds, forgetting pairs whose
+// cooldown has run out
+func (f *collisionFilter) advance(dt float64) {
+	f.now += dt
+	if f.now-f.pruned < f.cooldown {
+		return
+	}
+	for pair, at := range f.last {
+		if f.now-at >= f.cooldown {
+			delete(f.last, pair)
+		}
+	}
+	f.pruned = f.now
+}
+
+// allow reports whether a collision should be published
+func (f *collisionFilter) allow(c Collision) bool {
+	if c.Impulse < f.minImpulse {
+		return false
+	}
+	if f.cooldown <= 0 {
+		return true
+	}
+	pair := bodyPair{c.A, c.B}
+	if _, ok := f.last[pair]; !ok {
+		pair = bodyPair{c.B, c.A}
+	}
+	if at, ok := f.last[pair]; ok && f.now-at < f.cooldown {
+		return false
+	}
+	f.last[pair] = f.now
+	return true
+}
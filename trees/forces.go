@@ -0,0 +1,92 @@
+package trees
+
+import (
+	"github.com/ByteArena/box2d"
+	"github.com/faiface/pixel"
+)
+
+// Kinds of force reported by Forces
+const (
+	ForceGravity  = "gravity"
+	ForceNormal   = "normal"
+	ForceFriction = "friction"
+)
+
+// Force is a force in newtons acting on a body at a point in metres
+type Force struct {
+	Kind   string
+	Point  pixel.Vec
+	Vector pixel.Vec
+}
+
+// contactForce is the impulse on the watched body at one point of contact
+// during the last step
+type contactForce struct {
+	point             pixel.Vec
+	normal, tangent   pixel.Vec
+	normalI, tangentI float64
+}
+
+// Forces lists the forces that acted on a body during the last step: its
+// weight at its centre of mass, then the normal force and friction at each
+// point where it touches something. Torque is the total turning force about
+// its centre of mass in newton-metres, positive anticlockwise.
+//
+// Contact forces are only measured for one body at a time, starting from the
+// step after Forces is first called for it, so annotating a body means
+// calling Forces for it on every frame. Box2D doesn't solve the contacts of
+// sleeping bodies, so this also keeps the body awake.
+func (w *World) Forces(body *box2d.B2Body) (forces []Force, torque float64) {
+	body.SetAwake(true)
+	centre := body.GetWorldCenter()
+	c := pixel.V(centre.X, centre.Y)
+	if body.GetType() == box2d.B2BodyType.B2_dynamicBody {
+		g := w.physics.GetGravity()
+		forces = append(forces, Force{Kind: ForceGravity, Point: c, Vector: pixel.V(g.X, g.Y).Scaled(body.GetMass())})
+	}
+	if w.contacts.watch != body {
+		w.contacts.watch = body
+		w.contacts.watched = nil
+		return forces, 0
+	}
+	if w.lastStep <= 0 {
+		return forces, 0
+	}
+	for _, cf := range w.contacts.watched {
+		for _, f := range []Force{
+			{Kind: ForceNormal, Point: cf.point, Vector: cf.normal.Scaled(cf.normalI / w.lastStep)},
+			{Kind: ForceFriction, Point: cf.point, Vector: cf.tangent.Scaled(cf.tangentI / w.lastStep)},
+		} {
+			forces = append(forces, f)
+			torque += f.Point.Sub(c).Cross(f.Vector)
+		}
+	}
+	return forces, torque
+}
+
+// watchContact records the impulses on the watched body from one contact
+func (c *contacts) watchContact(contact box2d.B2ContactInterface, impulse *box2d.B2ContactImpulse) {
+	a, b := contact.GetFixtureA().GetBody(), contact.GetFixtureB().GetBody()
+	if a != c.watch && b != c.watch {
+		return
+	}
+	var manifold box2d.B2WorldManifold
+	contact.GetWorldManifold(&manifold)
+
+	// The manifold normal points from A to B, so the forces on A are reversed
+	normal := pixel.V(manifold.Normal.X, manifold.Normal.Y)
+	if a == c.watch {
+		normal = normal.Scaled(-1)
+	}
+	tangent := pixel.V(normal.Y, -normal.X)
+	for i := 0; i < contact.GetManifold().PointCount; i++ {
+		p := manifold.Points[i]
+		c.watched = append(c.watched, contactForce{
+			point:    pixel.V(p.X, p.Y),
+			normal:   normal,
+			tangent:  tangent,
+			normalI:  impulse.NormalImpulses[i],
+			tangentI: impulse.TangentImpulses[i],
+		})
+	}
+}
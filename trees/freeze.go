@@ -0,0 +1,101 @@
+package trees
+
+import (
+	"github.com/ByteArena/box2d"
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"golang.org/x/image/colornames"
+)
+
+// Tint for frozen trees
+var frozenTint = colornames.Lightsteelblue
+
+// Freeze turns a tree or entity into static geometry that stays put and
+// holds up anything that lands on it
+func (w *World) Freeze(body *box2d.B2Body) {
+	if _, ok := w.Lookup(body); ok && body.GetType() == box2d.B2BodyType.B2_dynamicBody {
+		body.SetType(box2d.B2BodyType.B2_staticBody)
+	}
+}
+
+// Thaw lets a frozen tree or entity move again
+func (w *World) Thaw(body *box2d.B2Body) {
+	if w.Frozen(body) {
+		body.SetType(box2d.B2BodyType.B2_dynamicBody)
+		body.SetAwake(true)
+	}
+}
+
+// Frozen reports whether a tree or entity has been frozen
+func (w *World) Frozen(body *box2d.B2Body) bool {
+	_, ok := w.Lookup(body)
+	return ok && body.GetType() == box2d.B2BodyType.B2_staticBody
+}
+
+// ToggleFreeze freezes every moving tree and entity centred inside a
+// rectangle given in metres. If nothing inside is moving, it thaws whatever
+// is frozen there instead.
+func (w *World) ToggleFreeze(r pixel.Rect) {
+	var moving, frozen []*box2d.B2Body
+	check := func(body *box2d.B2Body) {
+		p := body.GetPosition()
+		if !r.Contains(pixel.V(p.X, p.Y)) {
+			return
+		}
+		switch body.GetType() {
+		case box2d.B2BodyType.B2_dynamicBody:
+			moving = append(moving, body)
+		case box2d.B2BodyType.B2_staticBody:
+			frozen = append(frozen, body)
+		}
+	}
+	for _, tree := range w.trees {
+		check(tree)
+	}
+	for _, e := range w.entities {
+		check(e.Body)
+	}
+	if len(moving) > 0 {
+		for _, body := range moving {
+			w.Freeze(body)
+		}
+		return
+	}
+	for _, body := range frozen {
+		w.Thaw(body)
+	}
+}
+
+// freezeTool freezes or thaws everything in a rectangle dragged out with the mouse
+type freezeTool struct {
+	start, end pixel.Vec
+	dragging   bool
+}
+
+func (t *freezeTool) Name() string { return "freeze" }
+
+func (t *freezeTool) Press(w *World, pos pixel.Vec) {
+	t.start, t.end, t.dragging = pos, pos, true
+}
+
+func (t *freezeTool) Drag(w *World, pos pixel.Vec) {
+	t.end = pos
+}
+
+func (t *freezeTool) Release(w *World, pos pixel.Vec) {
+	t.end, t.dragging = pos, false
+	w.ToggleFreeze(pixel.Rect{Min: t.start, Max: t.end}.Norm())
+}
+
+func (t *freezeTool) DrawOverlay(imd *imdraw.IMDraw) {
+	if !t.dragging {
+		return
+	}
+	imd.Color = frozenTint
+	imd.Push(t.start.Scaled(PixelsPerMetre), t.end.Scaled(PixelsPerMetre))
+	imd.Rectangle(2)
+}
+
+func init() {
+	RegisterTool(&freezeTool{})
+}
@@ -0,0 +1,93 @@
+package trees
+
+import (
+	"github.com/ByteArena/box2d"
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"golang.org/x/image/colornames"
+)
+
+// grabStrength is how hard a grabbed body is pulled towards the target, in
+// newtons per kilogram of its mass, about a hundred times its weight on Earth.
+// It goes by mass rather than weight so bodies can still be dragged in zero-g.
+const grabStrength = 1000
+
+// grab is a body being dragged by a mouse joint
+type grab struct {
+	entity *Entity
+	joint  *box2d.B2MouseJoint
+}
+
+// Grab takes hold of the moving body under a position in metres with a
+// mouse joint, which pulls that point of the body towards a target set with
+// MoveGrab until ReleaseGrab lets go. It returns nil if there's nothing there
+// to grab. Only one body can be held at a time.
+func (w *World) Grab(pos pixel.Vec) *Entity {
+	w.ReleaseGrab()
+	point := box2d.MakeB2Vec2(pos.X, pos.Y)
+	aabb := box2d.MakeB2AABB()
+	aabb.LowerBound = box2d.MakeB2Vec2(pos.X-0.01, pos.Y-0.01)
+	aabb.UpperBound = box2d.MakeB2Vec2(pos.X+0.01, pos.Y+0.01)
+	var found *Entity
+	w.physics.QueryAABB(func(fixture *box2d.B2Fixture) bool {
+		body := fixture.GetBody()
+		if body.GetType() != box2d.B2BodyType.B2_dynamicBody || !fixture.TestPoint(point) {
+			return true
+		}
+		found, _ = w.Lookup(body)
+		return found == nil
+	}, aabb)
+	if found == nil {
+		return nil
+	}
+	def := box2d.MakeB2MouseJointDef()
+	def.BodyA = w.anchor
+	def.BodyB = found.Body
+	def.Target = point
+	def.MaxForce = grabStrength * found.Body.GetMass()
+	found.Body.SetAwake(true)
+	w.grab = &grab{entity: found, joint: w.physics.CreateJoint(&def).(*box2d.B2MouseJoint)}
+	return found
+}
+
+// Grabbed is the entity being held by Grab, or nil
+func (w *World) Grabbed() *Entity {
+	if w.grab == nil {
+		return nil
+	}
+	if _, ok := w.Lookup(w.grab.entity.Body); !ok {
+		// Destroying the body took the joint with it
+		w.grab = nil
+		return nil
+	}
+	return w.grab.entity
+}
+
+// MoveGrab moves the point the grabbed body is pulled towards
+func (w *World) MoveGrab(target pixel.Vec) {
+	if w.Grabbed() != nil {
+		w.grab.joint.SetTarget(box2d.MakeB2Vec2(target.X, target.Y))
+		w.grab.entity.Body.SetAwake(true)
+	}
+}
+
+// ReleaseGrab lets go of the grabbed body, leaving it moving however it was
+func (w *World) ReleaseGrab() {
+	if w.Grabbed() != nil {
+		w.physics.DestroyJoint(w.grab.joint)
+	}
+	w.grab = nil
+}
+
+// drawGrab draws a line from the grabbed point to where it's being pulled
+func (w *World) drawGrab(imd *imdraw.IMDraw) {
+	if w.Grabbed() == nil {
+		return
+	}
+	from, to := w.grab.joint.GetAnchorB(), w.grab.joint.GetTarget()
+	imd.Color = colornames.White
+	imd.Push(pixel.V(from.X, from.Y).Scaled(PixelsPerMetre), pixel.V(to.X, to.Y).Scaled(PixelsPerMetre))
+	imd.Line(2)
+	imd.Push(pixel.V(to.X, to.Y).Scaled(PixelsPerMetre))
+	imd.Circle(4, 0)
+}
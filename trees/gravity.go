@@ -0,0 +1,63 @@
+package trees
+
+import "github.com/faiface/pixel"
+
+// GravityPreset is the gravity of some other world along with how thick its
+// air is, which sets how much trees are slowed as they fall
+type GravityPreset struct {
+	Name    string
+	Gravity pixel.Vec
+
+	// Linear damping of the trees, as TreeDamping in the options
+	Drag float64
+}
+
+// The built-in gravity presets, from weakest to strongest. Earth's gravity is
+// rounded to 10 like the default options.
+var (
+	GravityZero    = GravityPreset{Name: "zero-g", Gravity: pixel.ZV, Drag: 0.02}
+	GravityMoon    = GravityPreset{Name: "moon", Gravity: pixel.V(0, -1.62), Drag: 0}
+	GravityMars    = GravityPreset{Name: "mars", Gravity: pixel.V(0, -3.71), Drag: 0.005}
+	GravityEarth   = GravityPreset{Name: "earth", Gravity: pixel.V(0, -10), Drag: 0.02}
+	GravityJupiter = GravityPreset{Name: "jupiter", Gravity: pixel.V(0, -24.79), Drag: 0.1}
+)
+
+// GravityPresets lists the built-in gravity presets from weakest to strongest
+func GravityPresets() []GravityPreset {
+	return []GravityPreset{GravityZero, GravityMoon, GravityMars, GravityEarth, GravityJupiter}
+}
+
+// LookupGravityPreset finds a built-in gravity preset by name
+func LookupGravityPreset(name string) (GravityPreset, bool) {
+	for _, p := range GravityPresets() {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return GravityPreset{}, false
+}
+
+// SetGravityPreset changes the gravity and the drag on every tree together
+func (w *World) SetGravityPreset(p GravityPreset) {
+	w.opts.TreeDamping = p.Drag
+	for _, tree := range w.trees {
+		tree.SetLinearDamping(p.Drag)
+	}
+	for _, e := range w.entities {
+		if _, ghost := e.Type.(ghostTreeType); ghost {
+			e.Body.SetLinearDamping(p.Drag)
+		}
+	}
+	w.SetGravity(p.Gravity)
+}
+
+// GravityPreset is the preset whose gravity and drag the World has now, if
+// it matches one
+func (w *World) GravityPreset() (GravityPreset, bool) {
+	for _, p := range GravityPresets() {
+		if p.Gravity == w.opts.Gravity && p.Drag == w.opts.TreeDamping {
+			return p, true
+		}
+	}
+	return GravityPreset{}, false
+}
@@ -0,0 +1,126 @@
+package trees
+
+import (
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/ByteArena/box2d"
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"golang.org/x/image/colornames"
+)
+
+// Shape of the generated hills in metres
+const (
+	hillsPrefix = "hills "
+	hillsWidth  = 100 // Across, centred on the origin like the mountain's base
+	hillsStep   = 1.0 // Between the points of the height profile
+	hillsFloor  = 1   // Height of the lowest valley
+	hillsHeight = 18  // Between the lowest valley and the highest peak
+	hillsBase   = -1  // Bottom of the ground drawn under the hills
+	hillsRim    = 12  // How far in from each end the hills turn up, to hold most trees in
+)
+
+// hillsOctaves are the wavelengths in metres of the noise the height profile
+// is made of, each with how much of the height it adds. The long ones make
+// the hills and valleys, the short ones make them bumpy.
+var hillsOctaves = []struct{ wavelength, weight float64 }{
+	{40, 0.6},
+	{16, 0.3},
+	{6, 0.1},
+}
+
+// Hills is a level of rolling hills and valleys generated from a seed, so
+// every seed has hills of its own. The level is named for its seed, and
+// LookupLevel generates it from the name whether it's registered or not, so
+// snapshots, saves and replays of it load onto the same hills.
+func Hills(seed int64) Level {
+	heights := hillsProfile(seed)
+	top := 0.0
+	for _, h := range heights {
+		top = math.Max(top, h)
+	}
+	return Level{
+		Name:  hillsPrefix + strconv.FormatInt(seed, 10),
+		Drop:  pixel.R(-hillsWidth/2+2, top+4, hillsWidth/2-2, top+64),
+		Trees: 600,
+		Build: func(ground *box2d.B2Body, imd *imdraw.IMDraw) {
+			buildHills(ground, imd, heights)
+		},
+	}
+}
+
+// hillsLevel generates the hills a level name is for, if it's the name of
+// some hills
+func hillsLevel(name string) (Level, bool) {
+	if !strings.HasPrefix(name, hillsPrefix) {
+		return Level{}, false
+	}
+	seed, err := strconv.ParseInt(strings.TrimPrefix(name, hillsPrefix), 10, 64)
+	if err != nil {
+		return Level{}, false
+	}
+	return Hills(seed), true
+}
+
+// hillsProfile is the height of the hills at each step across them, from
+// octaves of smoothed noise
+func hillsProfile(seed int64) []float64 {
+	random := rand.New(rand.NewSource(seed))
+	n := int(hillsWidth/hillsStep) + 1
+	heights := make([]float64, n)
+	for _, octave := range hillsOctaves {
+		// Random heights a wavelength apart, eased between
+		lattice := make([]float64, int(hillsWidth/octave.wavelength)+2)
+		for i := range lattice {
+			lattice[i] = random.Float64()
+		}
+		for i := range heights {
+			at := float64(i) * hillsStep / octave.wavelength
+			cell := int(at)
+			t := at - float64(cell)
+			t = t * t * (3 - 2*t)
+			heights[i] += octave.weight * (lattice[cell] + (lattice[cell+1]-lattice[cell])*t)
+		}
+	}
+
+	// Stretch the noise so the deepest valley and highest peak are always
+	// the same heights
+	low, high := math.Inf(1), math.Inf(-1)
+	for _, h := range heights {
+		low, high = math.Min(low, h), math.Max(high, h)
+	}
+	for i, h := range heights {
+		heights[i] = hillsFloor + (h-low)/math.Max(high-low, 1e-9)*hillsHeight
+		in := math.Min(float64(i), float64(n-1-i)) * hillsStep / hillsRim
+		if in < 1 {
+			heights[i] += hillsHeight / 2 * (1 - in) * (1 - in)
+		}
+	}
+	return heights
+}
+
+// buildHills adds a height profile to the ground as one chain, and fills in
+// the ground under it a strip at a time
+func buildHills(ground *box2d.B2Body, imd *imdraw.IMDraw, heights []float64) {
+	points := make([]pixel.Vec, len(heights))
+	for i, h := range heights {
+		points[i] = pixel.V(-hillsWidth/2+float64(i)*hillsStep, h)
+	}
+	addChain(ground, points)
+
+	imd.Color = colornames.Sandybrown
+	for i := 1; i < len(points); i++ {
+		a, b := points[i-1], points[i]
+		for _, p := range []pixel.Vec{pixel.V(a.X, hillsBase), pixel.V(b.X, hillsBase), b, a} {
+			imd.Push(p.Scaled(PixelsPerMetre))
+		}
+		imd.Polygon(0)
+	}
+}
+
+func init() {
+	RegisterLevel(Hills(1))
+}
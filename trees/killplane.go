@@ -0,0 +1,54 @@
+package trees
+
+import "github.com/ByteArena/box2d"
+
+// killPlane is the height in metres below which bodies have fallen off the
+// world
+func (w *World) killPlane() float64 {
+	if w.opts.KillPlane != 0 {
+		return w.opts.KillPlane
+	}
+	return Bounds.Min.Y
+}
+
+// cull flags every tree and entity that has fallen below the kill plane for
+// the destroy queue, since nothing down there can ever come back and the
+// physics would otherwise go on simulating it forever
+func (w *World) cull() {
+	plane := w.killPlane()
+	for _, tree := range w.trees {
+		if tree.GetPosition().Y < plane {
+			w.Destroy(tree)
+			w.fallen++
+		}
+	}
+	for _, e := range w.entities {
+		if e.Body.GetPosition().Y < plane {
+			w.Destroy(e.Body)
+			w.fallen++
+		}
+	}
+}
+
+// Fallen counts the trees and entities destroyed for falling off the world
+func (w *World) Fallen() int {
+	return w.fallen
+}
+
+// Asleep counts the trees and entities the physics has sent to sleep, which
+// cost next to nothing to simulate until something wakes them. Frozen trees
+// and anything else static never move and aren't counted.
+func (w *World) Asleep() int {
+	asleep := 0
+	for _, tree := range w.trees {
+		if !tree.IsAwake() && tree.GetType() == box2d.B2BodyType.B2_dynamicBody {
+			asleep++
+		}
+	}
+	for _, e := range w.entities {
+		if !e.Body.IsAwake() && e.Body.GetType() == box2d.B2BodyType.B2_dynamicBody {
+			asleep++
+		}
+	}
+	return asleep
+}
@@ -0,0 +1,62 @@
+package trees
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+// Landing is where a tree first came to rest. X is in metres and At is the
+// simulated time in seconds since the World was created.
+type Landing struct {
+	ID uint64
+	X  float64
+	At float64
+}
+
+// Seconds a tree has to stay settled before it counts as landed, so trees
+// brushing past each other in the air don't count
+const landingSettle = 0.5
+
+// recordLandings notes every tree that has settled since the last step of dt
+// seconds. Trees knocked loose again later keep their first landing.
+func (w *World) recordLandings(dt float64) {
+	for _, tree := range w.trees {
+		e, _ := w.Lookup(tree)
+		if e.landed {
+			continue
+		}
+		if !settled(tree) {
+			e.settling = 0
+			continue
+		}
+		e.settling += dt
+		if e.settling < landingSettle && tree.IsAwake() {
+			continue
+		}
+		e.landed = true
+		w.landings = append(w.landings, Landing{ID: e.ID, X: tree.GetPosition().X, At: w.elapsed})
+	}
+}
+
+// Landings lists where each tree came to rest, in the order they landed.
+// Trees still falling, including those that missed the mountain, aren't
+// listed. The slice belongs to the World and must not be modified.
+func (w *World) Landings() []Landing {
+	return w.landings
+}
+
+// WriteLandings saves the landings as CSV with a header row
+func (w *World) WriteLandings(out io.Writer) error {
+	c := csv.NewWriter(out)
+	c.Write([]string{"id", "x", "at"})
+	for _, l := range w.landings {
+		c.Write([]string{
+			strconv.FormatUint(l.ID, 10),
+			strconv.FormatFloat(l.X, 'f', 3, 64),
+			strconv.FormatFloat(l.At, 'f', 3, 64),
+		})
+	}
+	c.Flush()
+	return c.Error()
+}
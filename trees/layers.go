@@ -0,0 +1,201 @@
+package trees
+
+import (
+	"image/color"
+
+	"github.com/ByteArena/box2d"
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"golang.org/x/image/colornames"
+)
+
+// Layer is a set of collision categories, one bit each
+type Layer uint16
+
+// Collision categories, one per kind of body
+const (
+	LayerTerrain Layer = 1 << iota
+	LayerTrees
+	LayerObjects
+	LayerGhosts
+
+	LayerAll Layer = 0xFFFF
+)
+
+// CollisionLayers says which category a body is in and which categories it
+// collides with. Two bodies only collide if each one's mask includes the
+// other's category.
+type CollisionLayers struct {
+	Category Layer
+	Mask     Layer
+}
+
+// Layered is implemented by entity types that want something other than the
+// default of colliding with everything from the objects layer
+type Layered interface {
+	Layers() CollisionLayers
+}
+
+var (
+	treeLayers   = CollisionLayers{Category: LayerTrees, Mask: LayerAll}
+	objectLayers = CollisionLayers{Category: LayerObjects, Mask: LayerAll}
+)
+
+// layersOf picks the layers for a newly spawned entity type
+func layersOf(typ EntityType) CollisionLayers {
+	if l, ok := typ.(Layered); ok {
+		return l.Layers()
+	}
+	return objectLayers
+}
+
+// setLayers puts every fixture on a body into the given layers
+func setLayers(body *box2d.B2Body, layers CollisionLayers) {
+	for f := body.GetFixtureList(); f != nil; f = f.GetNext() {
+		filter := f.GetFilterData()
+		filter.CategoryBits = uint16(layers.Category)
+		filter.MaskBits = uint16(layers.Mask)
+		f.SetFilterData(filter)
+	}
+}
+
+// layerFilter decides which fixtures collide from their layers. This port of
+// Box2D doesn't filter at all without one, and leaves the filter of fixtures
+// made without an explicit one zeroed, so those are treated as terrain that
+// collides with everything.
+type layerFilter struct{}
+
+func (layerFilter) ShouldCollide(a, b *box2d.B2Fixture) bool {
+	la, lb := fixtureLayers(a), fixtureLayers(b)
+	return la.Mask&lb.Category != 0 && lb.Mask&la.Category != 0
+}
+
+func fixtureLayers(f *box2d.B2Fixture) CollisionLayers {
+	filter := f.GetFilterData()
+	if filter.CategoryBits == 0 {
+		return CollisionLayers{Category: LayerTerrain, Mask: LayerAll}
+	}
+	return CollisionLayers{Category: Layer(filter.CategoryBits), Mask: Layer(filter.MaskBits)}
+}
+
+// Tints used to tell layers apart when ShowLayers is on
+var layerTints = map[Layer]color.Color{
+	LayerTerrain: colornames.Saddlebrown,
+	LayerTrees:   colornames.Forestgreen,
+	LayerObjects: colornames.Royalblue,
+	LayerGhosts:  colornames.Orchid,
+}
+
+func layerTint(category Layer) color.Color {
+	if tint, ok := layerTints[category]; ok {
+		return tint
+	}
+	return colornames.Black
+}
+
+// ShowLayers switches Render between drawing the world normally and drawing
+// every body in a flat tint for its collision category
+func (w *World) ShowLayers(show bool) {
+	w.showLayers = show
+}
+
+// ShowingLayers reports whether Render is tinting bodies by collision category
+func (w *World) ShowingLayers() bool {
+	return w.showLayers
+}
+
+// renderLayers draws every fixture of every body tinted by its category
+func (w *World) renderLayers(t pixel.Target) {
+	w.shapes.Clear()
+	for _, body := range w.bodies() {
+		drawFixtures(w.shapes, body)
+	}
+	w.shapes.Draw(t)
+}
+
+// drawFixtures pushes the outline of each of a body's fixtures, filled in
+// with the tint for its category
+func drawFixtures(imd *imdraw.IMDraw, body *box2d.B2Body) {
+	for f := body.GetFixtureList(); f != nil; f = f.GetNext() {
+		imd.Color = layerTint(fixtureLayers(f).Category)
+		fillFixture(imd, body, f)
+	}
+}
+
+// fillFixture pushes the shape of a fixture filled in with the current colour
+func fillFixture(imd *imdraw.IMDraw, body *box2d.B2Body, f *box2d.B2Fixture) {
+	switch shape := f.GetShape().(type) {
+	case *box2d.B2CircleShape:
+		p := body.GetWorldPoint(shape.M_p)
+		imd.Push(pixel.V(p.X, p.Y).Scaled(PixelsPerMetre))
+		imd.Circle(shape.M_radius*PixelsPerMetre, 0)
+	case *box2d.B2PolygonShape:
+		for i := 0; i < shape.M_count; i++ {
+			p := body.GetWorldPoint(shape.M_vertices[i])
+			imd.Push(pixel.V(p.X, p.Y).Scaled(PixelsPerMetre))
+		}
+		imd.Polygon(0)
+	case *box2d.B2EdgeShape:
+		for _, v := range []box2d.B2Vec2{shape.M_vertex1, shape.M_vertex2} {
+			p := body.GetWorldPoint(v)
+			imd.Push(pixel.V(p.X, p.Y).Scaled(PixelsPerMetre))
+		}
+		imd.Line(4)
+	case *box2d.B2ChainShape:
+		for i := 0; i < shape.M_count; i++ {
+			p := body.GetWorldPoint(shape.M_vertices[i])
+			imd.Push(pixel.V(p.X, p.Y).Scaled(PixelsPerMetre))
+		}
+		imd.Line(4)
+	}
+}
+
+// DrawSilhouette fills in the shape of an entity's body where it's drawn, in
+// pixels and in the colour the imdraw is set to, to draw a mask of it
+func (w *World) DrawSilhouette(imd *imdraw.IMDraw, e *Entity) {
+	c := e.Body.GetWorldCenter()
+	offset := w.drawnAt(e).Sub(pixel.V(c.X, c.Y))
+	imd.SetMatrix(pixel.IM.Moved(offset.Scaled(PixelsPerMetre)))
+	for f := e.Body.GetFixtureList(); f != nil; f = f.GetNext() {
+		fillFixture(imd, e.Body, f)
+	}
+	imd.SetMatrix(pixel.IM)
+}
+
+// ghostTreeType spawns trees that land on the terrain but pass straight
+// through each other and everything else
+type ghostTreeType struct{}
+
+func (ghostTreeType) Name() string { return "ghost tree" }
+
+func (ghostTreeType) Layers() CollisionLayers {
+	return CollisionLayers{Category: LayerGhosts, Mask: LayerTerrain}
+}
+
+func (ghostTreeType) Spawn(w *World, pos pixel.Vec) *box2d.B2Body {
+	bodyDef := box2d.MakeB2BodyDef()
+	bodyDef.Type = box2d.B2BodyType.B2_dynamicBody
+	bodyDef.Position.Set(pos.X, pos.Y)
+	bodyDef.LinearDamping = w.opts.TreeDamping
+	body := w.physics.CreateBody(&bodyDef)
+	shape := box2d.MakeB2CircleShape()
+	shape.SetRadius(TreeRadius)
+	fixtureDef := box2d.MakeB2FixtureDef()
+	fixtureDef.Shape = &shape
+	fixtureDef.Density = 1
+	fixtureDef.Friction = 1
+	fixtureDef.Restitution = w.opts.TreeRestitution
+	body.CreateFixtureFromDef(&fixtureDef)
+	return body
+}
+
+func (ghostTreeType) Draw(imd *imdraw.IMDraw, body *box2d.B2Body) {
+	p := body.GetPosition()
+	imd.Color = pixel.ToRGBA(colornames.Forestgreen).Scaled(0.4)
+	imd.Push(pixel.V(p.X, p.Y).Scaled(PixelsPerMetre))
+	imd.Circle(TreeRadius*PixelsPerMetre, 0)
+}
+
+func init() {
+	RegisterEntityType(ghostTreeType{})
+}
@@ -0,0 +1,182 @@
+package trees
+
+import (
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+
+	"github.com/ByteArena/box2d"
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"golang.org/x/image/colornames"
+)
+
+// LevelFile is a level described in JSON rather than in code, for levels that
+// are loaded at run time. Terrain is made of convex polygons, boxes and
+// round pegs in metres, and anything else the level starts with is placed
+// from a blueprint, or set going from a scene frozen by World.Scene.
+type LevelFile struct {
+	Version    int          `json:"version"`
+	Name       string       `json:"name"`
+	Drop       pixel.Rect   `json:"drop"`
+	Trees      int          `json:"trees"`
+	Bins       []pixel.Rect `json:"bins,omitempty"`
+	SlowFields []SlowField  `json:"slowFields,omitempty"`
+
+	// Colour of the terrain as a name such as "sandybrown", which is the
+	// default
+	Colour   string        `json:"colour,omitempty"`
+	Polygons [][]pixel.Vec `json:"polygons,omitempty"`
+	Boxes    []pixel.Rect  `json:"boxes,omitempty"`
+	Pegs     []Peg         `json:"pegs,omitempty"`
+
+	// Lines of terrain through two or more points, which can bend any way
+	Chains [][]pixel.Vec `json:"chains,omitempty"`
+
+	Blueprint *Blueprint `json:"blueprint,omitempty"`
+	Scene     *Snapshot  `json:"scene,omitempty"`
+}
+
+// degenerate reports whether Box2D would refuse a convex polygon in metres for
+// having points on top of each other or next to no area, which it asserts
+// against when the fixture is made
+func degenerate(points []pixel.Vec) bool {
+	area := 0.0
+	for i, p := range points {
+		for _, q := range points[:i] {
+			if p.Sub(q).Len() <= box2d.B2_linearSlop {
+				return true
+			}
+		}
+		area += p.Cross(points[(i+1)%len(points)]) / 2
+	}
+	return math.Abs(area) <= box2d.B2_linearSlop*box2d.B2_linearSlop
+}
+
+// Peg is a round piece of terrain
+type Peg struct {
+	Centre pixel.Vec `json:"centre"`
+	Radius float64   `json:"radius"`
+}
+
+// LevelFormat is the format of ReadLevel
+var LevelFormat = Format{Name: "level"}
+
+// ReadLevel loads a level from JSON. The level isn't registered, so it's up
+// to the caller to pass it to RegisterLevel.
+func ReadLevel(in io.Reader) (Level, error) {
+	var f LevelFile
+	if err := LevelFormat.Decode(in, &f); err != nil {
+		return Level{}, err
+	}
+	return f.Level()
+}
+
+// Level checks the level file makes sense and turns it into a Level
+func (f LevelFile) Level() (Level, error) {
+	if f.Name == "" {
+		return Level{}, fmt.Errorf("trees: level has no name")
+	}
+	if f.Drop.Area() <= 0 && f.Trees > 0 {
+		return Level{}, fmt.Errorf("trees: level %q has trees but nowhere to drop them", f.Name)
+	}
+	for _, p := range f.Polygons {
+		if len(p) < 3 || len(p) > box2d.B2_maxPolygonVertices {
+			return Level{}, fmt.Errorf("trees: level %q has a polygon with %d points, not 3 to %d", f.Name, len(p), box2d.B2_maxPolygonVertices)
+		}
+		if degenerate(p) {
+			return Level{}, fmt.Errorf("trees: level %q has a polygon too small or thin to collide with", f.Name)
+		}
+	}
+	for _, c := range f.Chains {
+		if len(c) < 2 {
+			return Level{}, fmt.Errorf("trees: level %q has a chain with %d points", f.Name, len(c))
+		}
+		for i := 1; i < len(c); i++ {
+			if c[i].Sub(c[i-1]).Len() <= box2d.B2_linearSlop {
+				return Level{}, fmt.Errorf("trees: level %q has a chain with points on top of each other", f.Name)
+			}
+		}
+	}
+	for _, p := range f.Pegs {
+		if p.Radius <= 0 {
+			return Level{}, fmt.Errorf("trees: level %q has a peg with no size", f.Name)
+		}
+	}
+	var colour color.Color = colornames.Sandybrown
+	if f.Colour != "" {
+		c, ok := colornames.Map[f.Colour]
+		if !ok {
+			return Level{}, fmt.Errorf("trees: level %q has unknown colour %q", f.Name, f.Colour)
+		}
+		colour = c
+	}
+	l := Level{
+		Name:       f.Name,
+		Drop:       f.Drop,
+		Trees:      f.Trees,
+		Bins:       f.Bins,
+		SlowFields: f.SlowFields,
+		Build: func(ground *box2d.B2Body, imd *imdraw.IMDraw) {
+			imd.Color = colour
+			for _, p := range f.Polygons {
+				addPolygon(ground, imd, p...)
+			}
+			for _, b := range f.Boxes {
+				addBox(ground, imd, b.Norm())
+			}
+			for _, p := range f.Pegs {
+				addPeg(ground, imd, p.Centre, p.Radius)
+			}
+			for _, c := range f.Chains {
+				addChain(ground, c)
+				for _, p := range c {
+					imd.Push(p.Scaled(PixelsPerMetre))
+				}
+				imd.Line(6)
+			}
+		},
+	}
+	if f.Blueprint != nil && f.Scene != nil {
+		return Level{}, fmt.Errorf("trees: level %q has both a blueprint and a scene", f.Name)
+	}
+	if f.Scene != nil {
+		s := *f.Scene
+		s.Level = f.Name
+		if _, _, err := s.resolve(); err != nil {
+			return Level{}, fmt.Errorf("trees: level %q: %v", f.Name, err)
+		}
+		l.Setup = func(w *World) {
+			if err := w.Restore(s); err != nil {
+				panic(err)
+			}
+		}
+	}
+	if f.Blueprint != nil {
+		// Building the blueprint replaces the world's slow fields, so it has
+		// to bring the level's own along with it
+		b := *f.Blueprint
+		b.Level = f.Name
+		b.SlowFields = append(append([]SlowField{}, f.SlowFields...), b.SlowFields...)
+		for _, p := range b.Parts {
+			if _, ok := LookupEntityType(p.Type); !ok && p.Type != (treeType{}).Name() {
+				return Level{}, fmt.Errorf("trees: level %q has a part of unknown type %q", f.Name, p.Type)
+			}
+		}
+		for _, st := range b.Stamps {
+			if _, ok := LookupStamp(st.Stamp); !ok {
+				return Level{}, fmt.Errorf("trees: level %q has unknown stamp %q", f.Name, st.Stamp)
+			}
+		}
+		if _, err := b.Snapshot(); err != nil {
+			return Level{}, fmt.Errorf("trees: level %q: %v", f.Name, err)
+		}
+		l.Setup = func(w *World) {
+			if err := w.Build(b); err != nil {
+				panic(err)
+			}
+		}
+	}
+	return l, nil
+}
@@ -0,0 +1,251 @@
+package trees
+
+import (
+	"sort"
+
+	"github.com/ByteArena/box2d"
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"golang.org/x/image/colornames"
+)
+
+// Level is the terrain a World is built on
+type Level struct {
+	Name string
+
+	// Area in metres that ScatterTree drops trees into
+	Drop pixel.Rect
+
+	// Number of trees that suit the level, for applications that scatter
+	// trees over it when it's loaded
+	Trees int
+
+	// Areas in metres that trees are counted in by BinCounts, if any
+	Bins []pixel.Rect
+
+	// Slow fields that are part of the level
+	SlowFields []SlowField
+
+	// Build adds the terrain to a static ground body and draws it, in pixels
+	Build func(ground *box2d.B2Body, imd *imdraw.IMDraw)
+
+	// Setup adds anything else the level starts with, such as entities, once
+	// the terrain is built. It can be nil.
+	Setup func(w *World)
+
+	// Decor is drawn over the terrain, behind everything else, and isn't part
+	// of the physics, such as the tiles of a map made in Tiled
+	Decor []Decoration
+}
+
+// Decoration is a sprite drawn as part of a level, with a matrix in pixels
+// like the terrain
+type Decoration struct {
+	Sprite *pixel.Sprite
+	Matrix pixel.Matrix
+}
+
+var levels = map[string]Level{}
+
+// RegisterLevel makes a level available to every world, replacing any
+// existing level with the same name
+func RegisterLevel(l Level) {
+	levels[l.Name] = l
+}
+
+// UnregisterLevel takes a level out of the registry. Worlds already built on
+// it carry on as they were.
+func UnregisterLevel(name string) {
+	delete(levels, name)
+}
+
+// LookupLevel finds a registered level by name, or generates the Hills the
+// name is for
+func LookupLevel(name string) (Level, bool) {
+	if l, ok := levels[name]; ok {
+		return l, true
+	}
+	return hillsLevel(name)
+}
+
+// Levels lists the registered levels sorted by name
+func Levels() []Level {
+	var all []Level
+	for _, l := range levels {
+		all = append(all, l)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Name < all[j].Name })
+	return all
+}
+
+// Level is the level the World was built on
+func (w *World) Level() Level {
+	return w.level
+}
+
+// drawDecor draws the level's Decor, batched up the first time it's drawn
+func (w *World) drawDecor(t pixel.Target) {
+	if w.decor == nil {
+		var picture pixel.Picture
+		for _, d := range w.level.Decor {
+			if d.Sprite.Picture() != picture || len(w.decor) == 0 {
+				picture = d.Sprite.Picture()
+				w.decor = append(w.decor, pixel.NewBatch(&pixel.TrianglesData{}, picture))
+			}
+			d.Sprite.Draw(w.decor[len(w.decor)-1], d.Matrix)
+		}
+	}
+	for _, b := range w.decor {
+		b.Draw(t)
+	}
+}
+
+// BinCounts counts the trees whose centres are in each of the level's bins
+func (w *World) BinCounts() []int {
+	counts := make([]int, len(w.level.Bins))
+	for i, bin := range w.level.Bins {
+		for _, e := range w.Query(bin) {
+			p := e.Body.GetWorldCenter()
+			if _, isTree := e.Type.(treeType); isTree && bin.Contains(pixel.V(p.X, p.Y)) {
+				counts[i]++
+			}
+		}
+	}
+	return counts
+}
+
+// addPolygon adds a convex polygon in metres to the ground and draws it
+func addPolygon(ground *box2d.B2Body, imd *imdraw.IMDraw, points ...pixel.Vec) {
+	shape := box2d.MakeB2PolygonShape()
+	vertices := make([]box2d.B2Vec2, len(points))
+	for i, p := range points {
+		vertices[i] = box2d.MakeB2Vec2(p.X, p.Y)
+		imd.Push(p.Scaled(PixelsPerMetre))
+	}
+	shape.Set(vertices, len(vertices))
+	ground.CreateFixture(&shape, 0)
+	imd.Polygon(0)
+}
+
+// addBox adds a rectangle in metres to the ground and draws it
+func addBox(ground *box2d.B2Body, imd *imdraw.IMDraw, r pixel.Rect) {
+	addPolygon(ground, imd, r.Min, pixel.V(r.Max.X, r.Min.Y), r.Max, pixel.V(r.Min.X, r.Max.Y))
+}
+
+// addChain adds a line of terrain in metres through any number of points to
+// the ground, which unlike a polygon doesn't have to be convex, without
+// drawing it
+func addChain(ground *box2d.B2Body, points []pixel.Vec) {
+	vertices := make([]box2d.B2Vec2, len(points))
+	for i, p := range points {
+		vertices[i] = box2d.MakeB2Vec2(p.X, p.Y)
+	}
+	chain := box2d.MakeB2ChainShape()
+	chain.CreateChain(vertices, len(vertices))
+	ground.CreateFixture(&chain, 0)
+}
+
+// addPeg adds a small circle in metres to the ground and draws it
+func addPeg(ground *box2d.B2Body, imd *imdraw.IMDraw, centre pixel.Vec, radius float64) {
+	shape := box2d.MakeB2CircleShape()
+	shape.M_p.Set(centre.X, centre.Y)
+	shape.SetRadius(radius)
+	ground.CreateFixture(&shape, 0)
+	imd.Push(centre.Scaled(PixelsPerMetre))
+	imd.Circle(radius*PixelsPerMetre, 0)
+}
+
+// The small mountain on a base that isn't quite big enough to hold every tree
+var mountain = Level{
+	Name:  "mountain",
+	Drop:  pixel.R(-100, 8, 100, 88),
+	Trees: 800,
+	Build: func(ground *box2d.B2Body, imd *imdraw.IMDraw) {
+		imd.Color = colornames.Sandybrown
+		addPolygon(ground, imd, pixel.V(10, 1), pixel.V(0, 10), pixel.V(-10, 1))
+		addBox(ground, imd, pixel.R(-50, -1, 50, 1))
+	},
+}
+
+// buildFlat builds nothing but the base, for levels that are all about what's
+// put on it
+func buildFlat(ground *box2d.B2Body, imd *imdraw.IMDraw) {
+	imd.Color = colornames.Sandybrown
+	addBox(ground, imd, pixel.R(-50, -1, 50, 1))
+}
+
+// Layout of the Galton board in metres
+const (
+	galtonRows       = 10
+	galtonSpacing    = 6.0 // Between pegs across a row, and the width of a bin
+	galtonRowHeight  = 4.0 // Between rows of pegs
+	galtonPegRadius  = 0.3
+	galtonBinHeight  = 30
+	galtonPegsFrom   = 34 // Height of the bottom row of pegs
+	galtonWall       = 0.3
+	galtonDrag       = 0.5 // Of the slow field over the pegs
+	galtonDragRadius = 36
+	galtonMouth      = 5  // Half the width of the bottom of the funnel
+	galtonFunnel     = 14 // Half the width of the top of the funnel
+)
+
+// galton is a peg board. Trees funnelled into the top bounce left or right
+// off each row of pegs and pile up in the bins at the bottom, where the
+// counts build up a bell curve.
+var galton = func() Level {
+	bins := make([]pixel.Rect, galtonRows+1)
+	for i := range bins {
+		x := (float64(i) - galtonRows/2.0) * galtonSpacing
+		bins[i] = pixel.R(x-galtonSpacing/2, 1, x+galtonSpacing/2, galtonPegsFrom-galtonRowHeight/2)
+	}
+	top := galtonPegsFrom + (galtonRows-1)*galtonRowHeight
+	return Level{
+		Name:  "galton",
+		Drop:  pixel.R(-galtonFunnel+2, top+24, galtonFunnel-2, Bounds.Max.Y-2),
+		Trees: 120,
+		Bins:  bins,
+
+		// Damp the trees as they fall through the pegs so they bounce from
+		// peg to peg rather than flying right across the board
+		SlowFields: []SlowField{{Centre: pixel.V(0, (galtonPegsFrom+top)/2), Radius: galtonDragRadius, Drag: galtonDrag}},
+
+		Build: func(ground *box2d.B2Body, imd *imdraw.IMDraw) {
+			imd.Color = colornames.Sandybrown
+			addBox(ground, imd, pixel.R(-50, -1, 50, 1))
+
+			// Walls between the bins, and up the sides of the board to keep in
+			// trees bouncing off the outer pegs
+			for _, bin := range bins[1:] {
+				addBox(ground, imd, pixel.R(bin.Min.X-galtonWall/2, 1, bin.Min.X+galtonWall/2, galtonBinHeight))
+			}
+			left, right := bins[0].Min.X, bins[len(bins)-1].Max.X
+			addBox(ground, imd, pixel.R(left-galtonWall, 1, left, top+22))
+			addBox(ground, imd, pixel.R(right, 1, right+galtonWall, top+22))
+
+			// Staggered rows of pegs right across the board, with a peg under
+			// the middle of the funnel at the top and one over every bin wall
+			// at the bottom
+			imd.Color = colornames.Dimgray
+			for row := 0; row < galtonRows; row++ {
+				y := top - float64(row)*galtonRowHeight
+				offset := float64(row%2) * galtonSpacing / 2
+				for x := left + galtonSpacing/2 - offset; x < right; x += galtonSpacing {
+					if x > left {
+						addPeg(ground, imd, pixel.V(x, y), galtonPegRadius)
+					}
+				}
+			}
+
+			// A funnel over the top, steep enough that trees don't come to rest
+			// on it and with a mouth wide enough that they don't jam in it
+			imd.Color = colornames.Sandybrown
+			addPolygon(ground, imd, pixel.V(-galtonMouth-galtonWall, top+5), pixel.V(-galtonMouth, top+5), pixel.V(-galtonFunnel, top+22), pixel.V(-galtonFunnel-galtonWall, top+22))
+			addPolygon(ground, imd, pixel.V(galtonMouth, top+5), pixel.V(galtonMouth+galtonWall, top+5), pixel.V(galtonFunnel+galtonWall, top+22), pixel.V(galtonFunnel, top+22))
+		},
+	}
+}()
+
+func init() {
+	RegisterLevel(mountain)
+	RegisterLevel(galton)
+}
@@ -0,0 +1,80 @@
+package trees
+
+import "github.com/ByteArena/box2d"
+
+// SetLifetime makes every tree or entity of the named type despawn after it
+// has been in the world for this many seconds of simulated time, fading away
+// over the last LifetimeFade seconds. Zero lets them stay forever. Bodies
+// already in the world are aged from when they were spawned.
+func (w *World) SetLifetime(typ string, seconds float64) {
+	lifetimes := map[string]float64{}
+	for name, l := range w.opts.Lifetimes {
+		lifetimes[name] = l
+	}
+	if seconds > 0 {
+		lifetimes[typ] = seconds
+	} else {
+		delete(lifetimes, typ)
+	}
+	w.opts.Lifetimes = lifetimes
+}
+
+// Lifetime is how many seconds bodies of the named type last, or zero if
+// they stay forever
+func (w *World) Lifetime(typ string) float64 {
+	return w.opts.Lifetimes[typ]
+}
+
+// Age is how many seconds of simulated time an entity has been in the world
+func (w *World) Age(e *Entity) float64 {
+	return w.elapsed - e.born
+}
+
+// expire flags everything that has outlived its lifetime for the destroy
+// queue
+func (w *World) expire() {
+	if len(w.opts.Lifetimes) == 0 {
+		return
+	}
+	for _, tree := range w.trees {
+		if e, ok := w.Lookup(tree); ok && w.expired(e) {
+			w.Destroy(tree)
+		}
+	}
+	for _, e := range w.entities {
+		if w.expired(e) {
+			w.Destroy(e.Body)
+		}
+	}
+}
+
+func (w *World) expired(e *Entity) bool {
+	l := w.opts.Lifetimes[e.Type.Name()]
+	return l > 0 && w.Age(e) >= l
+}
+
+// fade is how opaque a body is drawn, from 1 for most of its life down to 0
+// as it's about to despawn. Pixel colours are premultiplied by their alpha, so
+// it's applied as a mask of pixel.Alpha.
+func (w *World) fade(body *box2d.B2Body) float64 {
+	e, ok := w.Lookup(body)
+	if !ok {
+		return 1
+	}
+	l := w.opts.Lifetimes[e.Type.Name()]
+	if l <= 0 {
+		return 1
+	}
+	fade := w.opts.LifetimeFade
+	if fade > l {
+		fade = l
+	}
+	left := l - w.Age(e)
+	switch {
+	case left >= fade:
+		return 1
+	case left <= 0:
+		return 0
+	}
+	return left / fade
+}
@@ -0,0 +1,32 @@
+package trees
+
+import (
+	"github.com/ByteArena/box2d"
+	"github.com/faiface/pixel"
+)
+
+// A tree touching this many others is as buried as a tree gets, since a pile
+// of circles tumbled onto a slope rarely packs any tighter
+const fullyBuried = 5
+
+// touching counts the bodies a body is in contact with
+func touching(body *box2d.B2Body) int {
+	n := 0
+	for edge := body.GetContactList(); edge != nil; edge = edge.Next {
+		if edge.Contact.IsTouching() {
+			n++
+		}
+	}
+	return n
+}
+
+// shade is how much light reaches a tree, from one out in the open down to
+// one less the Occlusion option for a tree buried deep in the pile. A tree
+// resting on a single thing isn't darkened at all.
+func (w *World) shade(body *box2d.B2Body) float64 {
+	if w.opts.Occlusion <= 0 {
+		return 1
+	}
+	buried := float64(touching(body)-1) / (fullyBuried - 1)
+	return 1 - w.opts.Occlusion*pixel.Clamp(buried, 0, 1)
+}
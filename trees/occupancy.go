@@ -0,0 +1,173 @@
+package trees
+
+import (
+	"math"
+
+	"github.com/ByteArena/box2d"
+	"github.com/faiface/pixel"
+)
+
+// Occupant is what fills a cell of an Occupancy grid
+type Occupant uint8
+
+// What can fill a cell. Where more than one thing overlaps a cell, the one
+// last in this list is the one seen.
+const (
+	OccupantNone   Occupant = iota
+	OccupantGround          // The terrain and anything else that doesn't move
+	OccupantTree
+	OccupantEntity // Anything else that moves
+)
+
+// Occupancy is a coarse picture of what fills a grid of cells laid over an
+// area, for agents and minimaps that want to see the world without drawing
+// it. Cells run along rows from the bottom left corner of Area, which is in
+// metres, like a DensityGrid.
+type Occupancy struct {
+	Area          pixel.Rect
+	Columns, Rows int
+	Cells         []Occupant
+}
+
+// NewOccupancy makes an empty grid of so many columns and rows over an area
+func NewOccupancy(area pixel.Rect, columns, rows int) *Occupancy {
+	return &Occupancy{Area: area.Norm(), Columns: columns, Rows: rows, Cells: make([]Occupant, columns*rows)}
+}
+
+// At is what fills a cell
+func (g *Occupancy) At(column, row int) Occupant {
+	return g.Cells[row*g.Columns+column]
+}
+
+// CellSize is the width and height of a cell in metres
+func (g *Occupancy) CellSize() pixel.Vec {
+	return pixel.V(g.Area.W()/float64(g.Columns), g.Area.H()/float64(g.Rows))
+}
+
+// Rasterize fills in the grid with whatever covers the centre of each cell,
+// testing each fixture only against the cells inside its bounding box so it
+// takes no OpenGL and little time. So that trees, pegs and walls smaller than
+// a cell still show up, the cells along the edges of every polygon and the
+// cell at the middle of every fixture are filled too. Chains and edges, such
+// as the hills, are lines with nothing inside them, so they only fill the
+// cells they pass through. Sensors aren't solid and are left out.
+func (w *World) Rasterize(g *Occupancy) {
+	for i := range g.Cells {
+		g.Cells[i] = OccupantNone
+	}
+	size := g.CellSize()
+	if g.Columns <= 0 || g.Rows <= 0 || size.X <= 0 || size.Y <= 0 {
+		return
+	}
+	for _, body := range w.bodies() {
+		kind := OccupantEntity
+		if e, ok := w.Lookup(body); ok {
+			if _, isTree := e.Type.(treeType); isTree {
+				kind = OccupantTree
+			}
+		} else if body.GetType() == box2d.B2BodyType.B2_staticBody {
+			kind = OccupantGround
+		}
+		for f := body.GetFixtureList(); f != nil; f = f.GetNext() {
+			if f.IsSensor() {
+				continue
+			}
+			switch shape := f.GetShape().(type) {
+			case *box2d.B2EdgeShape:
+				g.line(body.GetWorldPoint(shape.M_vertex1), body.GetWorldPoint(shape.M_vertex2), kind)
+			case *box2d.B2ChainShape:
+				for i := 1; i < shape.M_count; i++ {
+					g.line(body.GetWorldPoint(shape.M_vertices[i-1]), body.GetWorldPoint(shape.M_vertices[i]), kind)
+				}
+			case *box2d.B2PolygonShape:
+				g.fill(f, kind)
+				for i := range shape.M_vertices[:shape.M_count] {
+					g.line(body.GetWorldPoint(shape.M_vertices[i]), body.GetWorldPoint(shape.M_vertices[(i+1)%shape.M_count]), kind)
+				}
+			default:
+				g.fill(f, kind)
+			}
+		}
+	}
+}
+
+// fill marks the cells whose centres are inside a fixture, and the cell at
+// the middle of it
+func (g *Occupancy) fill(f *box2d.B2Fixture, kind Occupant) {
+	box := f.GetAABB(0)
+	if box.UpperBound.X < g.Area.Min.X || box.LowerBound.X > g.Area.Max.X || box.UpperBound.Y < g.Area.Min.Y || box.LowerBound.Y > g.Area.Max.Y {
+		return
+	}
+	size := g.CellSize()
+	c0, r0 := g.cell(box.LowerBound.X, box.LowerBound.Y)
+	c1, r1 := g.cell(box.UpperBound.X, box.UpperBound.Y)
+	for row := r0; row <= r1; row++ {
+		y := g.Area.Min.Y + (float64(row)+0.5)*size.Y
+		for column := c0; column <= c1; column++ {
+			i := row*g.Columns + column
+			if g.Cells[i] >= kind {
+				continue
+			}
+			x := g.Area.Min.X + (float64(column)+0.5)*size.X
+			if f.TestPoint(box2d.MakeB2Vec2(x, y)) {
+				g.Cells[i] = kind
+			}
+		}
+	}
+	centre := box.GetCenter()
+	g.mark(centre.X, centre.Y, kind)
+}
+
+// line marks the cells a line from a to b passes through, sampling it at
+// least twice a cell
+func (g *Occupancy) line(a, b box2d.B2Vec2, kind Occupant) {
+	size := g.CellSize()
+	steps := int(math.Ceil(2 * math.Max(math.Abs(b.X-a.X)/size.X, math.Abs(b.Y-a.Y)/size.Y)))
+	for s := 0; s <= steps; s++ {
+		t := float64(s) / math.Max(float64(steps), 1)
+		g.mark(a.X+(b.X-a.X)*t, a.Y+(b.Y-a.Y)*t, kind)
+	}
+}
+
+// mark fills the cell a point in metres is in, if it's in the grid
+func (g *Occupancy) mark(x, y float64, kind Occupant) {
+	if !g.Area.Contains(pixel.V(x, y)) {
+		return
+	}
+	column, row := g.cell(x, y)
+	if i := row*g.Columns + column; g.Cells[i] < kind {
+		g.Cells[i] = kind
+	}
+}
+
+// cell is the cell a point in metres is in, clamped to the grid
+func (g *Occupancy) cell(x, y float64) (int, int) {
+	size := g.CellSize()
+	column := int(math.Floor((x - g.Area.Min.X) / size.X))
+	row := int(math.Floor((y - g.Area.Min.Y) / size.Y))
+	clamp := func(i, n int) int {
+		if i < 0 {
+			return 0
+		}
+		if i >= n {
+			return n - 1
+		}
+		return i
+	}
+	return clamp(column, g.Columns), clamp(row, g.Rows)
+}
+
+// SetOccupancy has the world rasterize into a grid at the end of every step,
+// before Stepped is published, so it's always up to date with the last step.
+// Nil stops it.
+func (w *World) SetOccupancy(g *Occupancy) {
+	w.occupancy = g
+	if g != nil {
+		w.Rasterize(g)
+	}
+}
+
+// Occupancy is the grid set with SetOccupancy, as of the last step
+func (w *World) Occupancy() *Occupancy {
+	return w.occupancy
+}
@@ -0,0 +1,192 @@
+package trees
+
+import (
+	"math"
+
+	"github.com/ByteArena/box2d"
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"github.com/scottyw/falling-trees/spline"
+	"golang.org/x/image/colornames"
+)
+
+// Path is a route through the world made of straight segments between
+// points in metres
+type Path struct {
+	points []pixel.Vec
+	ends   []float64 // Distance along the path to the end of each segment
+}
+
+// NewPath makes a path through two or more points in metres
+func NewPath(points []pixel.Vec) *Path {
+	p := &Path{points: append([]pixel.Vec(nil), points...)}
+	total := 0.0
+	for i := 1; i < len(points); i++ {
+		total += points[i].Sub(points[i-1]).Len()
+		p.ends = append(p.ends, total)
+	}
+	return p
+}
+
+// Points lists the points the path goes through. The slice belongs to the
+// Path and must not be modified.
+func (p *Path) Points() []pixel.Vec {
+	return p.points
+}
+
+// Length is the distance along the whole path in metres
+func (p *Path) Length() float64 {
+	if len(p.ends) == 0 {
+		return 0
+	}
+	return p.ends[len(p.ends)-1]
+}
+
+// At finds the position a distance in metres along the path, clamped to its ends
+func (p *Path) At(distance float64) pixel.Vec {
+	if len(p.ends) == 0 {
+		return p.points[0]
+	}
+	for i, end := range p.ends {
+		if distance <= end || i == len(p.ends)-1 {
+			start := 0.0
+			if i > 0 {
+				start = p.ends[i-1]
+			}
+			t := 0.0
+			if end > start {
+				t = math.Max(0, math.Min(1, (distance-start)/(end-start)))
+			}
+			return pixel.Lerp(p.points[i], p.points[i+1], t)
+		}
+	}
+	return p.points[len(p.points)-1]
+}
+
+// guide moves a kinematic body back and forth along a path at a steady speed
+type guide struct {
+	entity    *Entity
+	path      *Path
+	speed     float64 // Metres per second
+	travelled float64 // Distance covered since the path was last at its start
+}
+
+// position is where the body should be after travelling a distance, which
+// runs out along the path and back again
+func (g *guide) position(travelled float64) pixel.Vec {
+	length := g.path.Length()
+	if length == 0 {
+		return g.path.At(0)
+	}
+	d := math.Mod(travelled, 2*length)
+	if d > length {
+		d = 2*length - d
+	}
+	return g.path.At(d)
+}
+
+// SpawnGuided spawns a glider at the start of a path which then shuttles back
+// and forth along it at a speed in metres per second, ignoring gravity and
+// pushing aside anything in its way
+func (w *World) SpawnGuided(path *Path, speed float64) *Entity {
+	e := w.Spawn(gliderType{}, path.At(0))
+	w.guides = append(w.guides, &guide{entity: e, path: path, speed: speed})
+	return e
+}
+
+// moveGuided sets the velocity of every guided body so that it reaches the
+// next point along its path by the end of a step of dt seconds
+func (w *World) moveGuided(dt float64) {
+	if dt <= 0 {
+		return
+	}
+	live := w.guides[:0]
+	for _, g := range w.guides {
+		if _, ok := w.Lookup(g.entity.Body); !ok {
+			continue
+		}
+		live = append(live, g)
+		g.travelled += g.speed * dt
+		target := g.position(g.travelled)
+		p := g.entity.Body.GetPosition()
+		v := target.Sub(pixel.V(p.X, p.Y)).Scaled(1 / dt)
+		g.entity.Body.SetLinearVelocity(box2d.MakeB2Vec2(v.X, v.Y))
+	}
+	w.guides = live
+}
+
+// drawPaths traces every path a guided body is following
+func (w *World) drawPaths(imd *imdraw.IMDraw) {
+	imd.Color = colornames.Darkgray
+	for _, g := range w.guides {
+		for _, p := range g.path.points {
+			imd.Push(p.Scaled(PixelsPerMetre))
+		}
+		imd.Line(2)
+	}
+}
+
+// Half the size of a glider in metres
+var gliderSize = pixel.V(2, 0.3)
+
+// Speed of gliders laid down with the path tool in metres per second
+const gliderSpeed = 4
+
+// gliderType spawns flat kinematic platforms. On their own they hang in the
+// air, but SpawnGuided sends them along a path.
+type gliderType struct{}
+
+func (gliderType) Name() string { return "glider" }
+
+func (gliderType) Spawn(w *World, pos pixel.Vec) *box2d.B2Body {
+	bodyDef := box2d.MakeB2BodyDef()
+	bodyDef.Type = box2d.B2BodyType.B2_kinematicBody
+	bodyDef.Position.Set(pos.X, pos.Y)
+	body := w.physics.CreateBody(&bodyDef)
+	shape := box2d.MakeB2PolygonShape()
+	shape.SetAsBox(gliderSize.X, gliderSize.Y)
+	fixtureDef := box2d.MakeB2FixtureDef()
+	fixtureDef.Shape = &shape
+	fixtureDef.Friction = 1
+	body.CreateFixtureFromDef(&fixtureDef)
+	return body
+}
+
+func (gliderType) Draw(imd *imdraw.IMDraw, body *box2d.B2Body) {
+	imd.Color = colornames.Darkorange
+	for _, corner := range []pixel.Vec{
+		pixel.V(-gliderSize.X, -gliderSize.Y),
+		pixel.V(gliderSize.X, -gliderSize.Y),
+		pixel.V(gliderSize.X, gliderSize.Y),
+		pixel.V(-gliderSize.X, gliderSize.Y),
+	} {
+		p := body.GetWorldPoint(box2d.MakeB2Vec2(corner.X, corner.Y))
+		imd.Push(pixel.V(p.X, p.Y).Scaled(PixelsPerMetre))
+	}
+	imd.Polygon(0)
+}
+
+// pathTool draws a smooth path with the mouse and sends a glider along it
+type pathTool struct {
+	sketch
+}
+
+func (t *pathTool) Name() string                  { return "path" }
+func (t *pathTool) Press(w *World, pos pixel.Vec) { t.begin(w, pos) }
+func (t *pathTool) Drag(w *World, pos pixel.Vec)  { t.extend(pos) }
+
+func (t *pathTool) Release(w *World, pos pixel.Vec) {
+	if points := t.finish(pos); points != nil {
+		w.SpawnGuided(NewPath(spline.Sample(points, w.opts.Subdivisions)), gliderSpeed)
+	}
+}
+
+func (t *pathTool) DrawOverlay(imd *imdraw.IMDraw) {
+	imd.Color = colornames.Darkorange
+	t.draw(imd, 2)
+}
+
+func init() {
+	RegisterEntityType(gliderType{})
+	RegisterTool(&pathTool{})
+}
@@ -0,0 +1,173 @@
+package trees
+
+import (
+	"sort"
+
+	"github.com/ByteArena/box2d"
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"golang.org/x/image/colornames"
+)
+
+// Tool is something the mouse can do to a world. Positions are in metres.
+// Register tools from an init function so they're available from the start.
+type Tool interface {
+	Name() string
+	Press(w *World, pos pixel.Vec)
+	Drag(w *World, pos pixel.Vec)
+	Release(w *World, pos pixel.Vec)
+}
+
+// ToolOverlay is implemented by tools that draw something while they're in
+// use, such as the outline of an area being dragged out. Shapes are drawn in
+// pixels, the same as Render.
+type ToolOverlay interface {
+	DrawOverlay(imd *imdraw.IMDraw)
+}
+
+// EntityType is a kind of object that can be spawned into a world. Every
+// registered entity type automatically gets a tool that spawns it.
+type EntityType interface {
+	Name() string
+
+	// Spawn creates the object centred on a position in metres
+	Spawn(w *World, pos pixel.Vec) *box2d.B2Body
+
+	// Draw adds the object to a batch of shapes drawn in pixels
+	Draw(imd *imdraw.IMDraw, body *box2d.B2Body)
+}
+
+var (
+	tools       []Tool
+	entityTypes = map[string]EntityType{}
+)
+
+// RegisterTool makes a tool available to every world
+func RegisterTool(t Tool) {
+	tools = append(tools, t)
+}
+
+// RegisterEntityType makes an entity type available to every world, replacing
+// any existing type with the same name
+func RegisterEntityType(e EntityType) {
+	entityTypes[e.Name()] = e
+}
+
+// LookupEntityType finds a registered entity type by name
+func LookupEntityType(name string) (EntityType, bool) {
+	e, ok := entityTypes[name]
+	return e, ok
+}
+
+// EntityTypes lists the registered entity types sorted by name
+func EntityTypes() []EntityType {
+	var types []EntityType
+	for _, e := range entityTypes {
+		types = append(types, e)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i].Name() < types[j].Name() })
+	return types
+}
+
+// Tools lists the registered tools in the order they were registered,
+// followed by a spawn tool for each entity type and a stamp tool for each stamp
+func Tools() []Tool {
+	all := append([]Tool(nil), tools...)
+	for _, e := range EntityTypes() {
+		all = append(all, SpawnTool{Type: e})
+	}
+	for _, s := range Stamps() {
+		all = append(all, &StampTool{Stamp: s})
+	}
+	return all
+}
+
+// Spawn creates an entity of the given type and keeps track of it so it gets
+// drawn along with everything else
+func (w *World) Spawn(typ EntityType, pos pixel.Vec) *Entity {
+	body := typ.Spawn(w, pos)
+	if e, isTree := w.Lookup(body); isTree {
+		return e
+	}
+	setLayers(body, layersOf(typ))
+	e := w.registry.add(body, typ)
+	e.Wave, e.born = w.wave, w.elapsed
+	w.entities = append(w.entities, e)
+	w.events.Publish(BodySpawned{Body: body, Type: typ})
+	return e
+}
+
+// Entities lists everything spawned through Spawn other than trees, which are
+// listed by Trees. The slice belongs to the World and must not be modified.
+func (w *World) Entities() []*Entity {
+	return w.entities
+}
+
+// SpawnTool drops an entity wherever the mouse is pressed
+type SpawnTool struct {
+	Type EntityType
+}
+
+func (t SpawnTool) Name() string                    { return "spawn " + t.Type.Name() }
+func (t SpawnTool) Press(w *World, pos pixel.Vec)   { w.Spawn(t.Type, pos) }
+func (t SpawnTool) Drag(w *World, pos pixel.Vec)    {}
+func (t SpawnTool) Release(w *World, pos pixel.Vec) {}
+
+// explodeTool blows things up wherever the mouse is pressed
+type explodeTool struct{}
+
+func (explodeTool) Name() string                    { return "explode" }
+func (explodeTool) Press(w *World, pos pixel.Vec)   { w.Explode(pos, 12, 60) }
+func (explodeTool) Drag(w *World, pos pixel.Vec)    {}
+func (explodeTool) Release(w *World, pos pixel.Vec) {}
+
+// treeType spawns the usual round trees, which the World draws itself
+type treeType struct{}
+
+func (treeType) Name() string                                { return "tree" }
+func (treeType) Spawn(w *World, pos pixel.Vec) *box2d.B2Body { return w.SpawnTree(pos) }
+func (treeType) Draw(imd *imdraw.IMDraw, body *box2d.B2Body) {}
+
+// Half the width of a rock in metres
+const rockSize = 0.75
+
+// rockType spawns square rocks
+type rockType struct{}
+
+func (rockType) Name() string { return "rock" }
+
+func (rockType) Spawn(w *World, pos pixel.Vec) *box2d.B2Body {
+	bodyDef := box2d.MakeB2BodyDef()
+	bodyDef.Type = box2d.B2BodyType.B2_dynamicBody
+	bodyDef.Position.Set(pos.X, pos.Y)
+	body := w.physics.CreateBody(&bodyDef)
+	shape := box2d.MakeB2PolygonShape()
+	shape.SetAsBox(rockSize, rockSize)
+	fixtureDef := box2d.MakeB2FixtureDef()
+	fixtureDef.Shape = &shape
+	fixtureDef.Density = 2.5
+	fixtureDef.Friction = 0.8
+	fixtureDef.Restitution = 0.1
+	body.CreateFixtureFromDef(&fixtureDef)
+	return body
+}
+
+func (rockType) Draw(imd *imdraw.IMDraw, body *box2d.B2Body) {
+	imd.Color = colornames.Slategray
+	for _, corner := range []pixel.Vec{
+		pixel.V(-rockSize, -rockSize),
+		pixel.V(rockSize, -rockSize),
+		pixel.V(rockSize, rockSize),
+		pixel.V(-rockSize, rockSize),
+	} {
+		p := body.GetWorldPoint(box2d.MakeB2Vec2(corner.X, corner.Y))
+		imd.Push(pixel.V(p.X, p.Y).Scaled(PixelsPerMetre))
+	}
+	imd.Polygon(0)
+}
+
+func init() {
+	RegisterTool(explodeTool{})
+	RegisterEntityType(treeType{})
+	RegisterEntityType(rockType{})
+}
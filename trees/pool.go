@@ -0,0 +1,73 @@
+package trees
+
+import (
+	"github.com/ByteArena/box2d"
+	"github.com/faiface/pixel"
+)
+
+// Most removed trees kept for spawning again
+const maxPooled = 512
+
+// treePool keeps the bodies and entities of removed trees so spawning a tree
+// can reuse them rather than build another, which keeps a world that's
+// constantly spawning and removing trees from making garbage. A pooled body
+// stays in the physics world but is static and inactive, so it's in no
+// contacts or queries and nothing simulates it.
+type treePool struct {
+	parked []*Entity
+}
+
+// park takes a removed tree's body out of the simulation and keeps it, or
+// destroys it if the pool is full
+func (w *World) park(e *Entity) {
+	body := e.Body
+	if len(w.pool.parked) >= maxPooled {
+		w.physics.DestroyBody(body)
+		return
+	}
+	// Destroying a body takes its joints with it, so parking has to as well
+	for edge := body.GetJointList(); edge != nil; {
+		next := edge.Next
+		w.physics.DestroyJoint(edge.Joint)
+		edge = next
+	}
+	if w.grab != nil && w.grab.entity == e {
+		w.grab = nil
+	}
+	body.SetType(box2d.B2BodyType.B2_staticBody)
+	body.SetActive(false)
+	body.SetUserData(nil)
+	w.pool.parked = append(w.pool.parked, e)
+}
+
+// unpark brings a pooled tree back as a new one at pos, as SpawnTree would
+// have built it, or returns nil if the pool is empty
+func (w *World) unpark(pos pixel.Vec) *Entity {
+	n := len(w.pool.parked)
+	if n == 0 {
+		return nil
+	}
+	e := w.pool.parked[n-1]
+	w.pool.parked[n-1] = nil
+	w.pool.parked = w.pool.parked[:n-1]
+
+	body := e.Body
+	fixture := body.GetFixtureList()
+	fixture.GetShape().(*box2d.B2CircleShape).SetRadius(TreeRadius)
+	fixture.SetRestitution(w.opts.TreeRestitution)
+	body.SetTransform(box2d.MakeB2Vec2(pos.X, pos.Y), 0)
+	body.SetLinearVelocity(box2d.MakeB2Vec2(0, 0))
+	body.SetAngularVelocity(0)
+	body.SetLinearDamping(w.opts.TreeDamping)
+	body.SetType(box2d.B2BodyType.B2_dynamicBody)
+	body.SetActive(true)
+	body.SetAwake(true)
+	*e = Entity{Body: body}
+	return e
+}
+
+// Pooled counts the removed trees kept for spawning again, which are still
+// among the bodies of Physics
+func (w *World) Pooled() int {
+	return len(w.pool.parked)
+}
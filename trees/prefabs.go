@@ -0,0 +1,161 @@
+package trees
+
+import (
+	"math"
+
+	"github.com/ByteArena/box2d"
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"golang.org/x/image/colornames"
+)
+
+// Half the size in metres of the paddle of a spinner and the plank of a
+// seesaw, and the radius of a marble
+var (
+	spinnerSize = pixel.V(3, 0.2)
+	seesawSize  = pixel.V(4, 0.15)
+)
+
+const marbleRadius = 0.5
+
+const (
+	// Speed in radians per second a spinner's motor turns it, and the most
+	// torque in newton-metres it puts in to keep turning under load
+	spinnerSpeed  = 1.5
+	spinnerTorque = 5000
+
+	// Furthest a seesaw tips either way in radians
+	seesawTilt = 0.45
+)
+
+// addBoxBody creates a dynamic box centred on pos with half size half
+func addBoxBody(w *World, pos, half pixel.Vec, density, friction float64) *box2d.B2Body {
+	bodyDef := box2d.MakeB2BodyDef()
+	bodyDef.Type = box2d.B2BodyType.B2_dynamicBody
+	bodyDef.Position.Set(pos.X, pos.Y)
+	body := w.physics.CreateBody(&bodyDef)
+	shape := box2d.MakeB2PolygonShape()
+	shape.SetAsBox(half.X, half.Y)
+	fixtureDef := box2d.MakeB2FixtureDef()
+	fixtureDef.Shape = &shape
+	fixtureDef.Density = density
+	fixtureDef.Friction = friction
+	body.CreateFixtureFromDef(&fixtureDef)
+	return body
+}
+
+// pin sets up a joint fixing a body to the ground at its centre so it can
+// only turn
+func (w *World) pin(body *box2d.B2Body) *box2d.B2RevoluteJointDef {
+	jointDef := box2d.MakeB2RevoluteJointDef()
+	jointDef.Initialize(w.anchor, body, body.GetPosition())
+	return &jointDef
+}
+
+// drawBoxBody pushes the corners of a box with half size half on a body and
+// fills it in, marking the pivot it turns about
+func drawBoxBody(imd *imdraw.IMDraw, body *box2d.B2Body, half pixel.Vec) {
+	for _, corner := range []pixel.Vec{
+		pixel.V(-half.X, -half.Y),
+		pixel.V(half.X, -half.Y),
+		pixel.V(half.X, half.Y),
+		pixel.V(-half.X, half.Y),
+	} {
+		p := body.GetWorldPoint(box2d.MakeB2Vec2(corner.X, corner.Y))
+		imd.Push(pixel.V(p.X, p.Y).Scaled(PixelsPerMetre))
+	}
+	imd.Polygon(0)
+	p := body.GetPosition()
+	imd.Color = colornames.Black
+	imd.Push(pixel.V(p.X, p.Y).Scaled(PixelsPerMetre))
+	imd.Circle(0.15*PixelsPerMetre, 0)
+}
+
+// spinnerType spawns a paddle pinned in place and turned by a motor, for
+// shovelling things along in a contraption
+type spinnerType struct{}
+
+func (spinnerType) Name() string { return "spinner" }
+
+func (spinnerType) Spawn(w *World, pos pixel.Vec) *box2d.B2Body {
+	body := addBoxBody(w, pos, spinnerSize, 2, 0.8)
+	jointDef := w.pin(body)
+	jointDef.EnableMotor = true
+	jointDef.MotorSpeed = spinnerSpeed
+	jointDef.MaxMotorTorque = spinnerTorque
+	w.physics.CreateJoint(jointDef)
+	return body
+}
+
+func (spinnerType) Draw(imd *imdraw.IMDraw, body *box2d.B2Body) {
+	imd.Color = colornames.Mediumpurple
+	drawBoxBody(imd, body, spinnerSize)
+}
+
+// seesawType spawns a plank pinned in the middle that tips whichever way
+// has more weight on it
+type seesawType struct{}
+
+func (seesawType) Name() string { return "seesaw" }
+
+func (seesawType) Spawn(w *World, pos pixel.Vec) *box2d.B2Body {
+	body := addBoxBody(w, pos, seesawSize, 1, 0.8)
+	jointDef := w.pin(body)
+	jointDef.EnableLimit = true
+	jointDef.LowerAngle = -seesawTilt
+	jointDef.UpperAngle = seesawTilt
+	w.physics.CreateJoint(jointDef)
+	return body
+}
+
+func (seesawType) Draw(imd *imdraw.IMDraw, body *box2d.B2Body) {
+	imd.Color = colornames.Peru
+	drawBoxBody(imd, body, seesawSize)
+}
+
+// marbleType spawns small heavy balls that roll a long way
+type marbleType struct{}
+
+func (marbleType) Name() string { return "marble" }
+
+func (marbleType) Spawn(w *World, pos pixel.Vec) *box2d.B2Body {
+	bodyDef := box2d.MakeB2BodyDef()
+	bodyDef.Type = box2d.B2BodyType.B2_dynamicBody
+	bodyDef.Position.Set(pos.X, pos.Y)
+	bodyDef.Bullet = true
+	body := w.physics.CreateBody(&bodyDef)
+	shape := box2d.MakeB2CircleShape()
+	shape.SetRadius(marbleRadius)
+	fixtureDef := box2d.MakeB2FixtureDef()
+	fixtureDef.Shape = &shape
+	fixtureDef.Density = 3
+	fixtureDef.Friction = 0.2
+	fixtureDef.Restitution = 0.3
+	body.CreateFixtureFromDef(&fixtureDef)
+	return body
+}
+
+func (marbleType) Draw(imd *imdraw.IMDraw, body *box2d.B2Body) {
+	p := body.GetPosition()
+	centre := pixel.V(p.X, p.Y).Scaled(PixelsPerMetre)
+	imd.Color = colornames.Teal
+	imd.Push(centre)
+	imd.Circle(marbleRadius*PixelsPerMetre, 0)
+
+	// A spot off centre so it's clear the marble is rolling
+	angle := body.GetAngle()
+	imd.Color = colornames.White
+	imd.Push(centre.Add(pixel.V(math.Cos(angle), math.Sin(angle)).Scaled(marbleRadius * PixelsPerMetre / 2)))
+	imd.Circle(marbleRadius*PixelsPerMetre/4, 0)
+}
+
+func init() {
+	RegisterEntityType(spinnerType{})
+	RegisterEntityType(seesawType{})
+	RegisterEntityType(marbleType{})
+	RegisterLevel(Level{
+		Name:  "workshop",
+		Drop:  mountain.Drop,
+		Build: buildFlat,
+	})
+}
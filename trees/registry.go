@@ -0,0 +1,170 @@
+package trees
+
+import (
+	"fmt"
+
+	"github.com/ByteArena/box2d"
+	"github.com/faiface/pixel"
+)
+
+// Entity is something in a World, trees included: the body simulating it and
+// the type it was spawned from. IDs are handed out in spawn order and never reused,
+// and a restored snapshot keeps the IDs it was saved with.
+type Entity struct {
+	ID   uint64
+	Body *box2d.B2Body
+	Type EntityType
+	Wave int // The wave it was spawned in, counting from 1
+
+	rest     float64 // Seconds spent crawling along, for SolverProfile.SleepSpeed
+	settling float64 // Seconds a tree has stayed settled, towards landing
+	landed   bool    // Trees only, once they've first come to rest
+	sprite   int     // Trees only, which of Options.Sprites it's drawn with
+	played   float64 // Trees only, seconds of its sprite's animation played so far
+	still    float64 // Trees only, seconds at rest, towards snow settling on it
+	snow     float64 // Trees only, how much snow it has on it from 0 to 1
+
+	// Trees grown from seed only, the tint they inherited and seconds left
+	// growing to full size
+	tint pixel.RGBA
+	grow float64
+
+	previous    pixel.Vec // Centre in metres before the last fixed step, for drawing between steps
+	interpolate bool      // Whether previous has been set yet
+	born        float64   // World.elapsed when it was spawned, for lifetimes
+}
+
+func (e *Entity) String() string {
+	return fmt.Sprintf("%s#%d", e.Type.Name(), e.ID)
+}
+
+// registry maps bodies back to the entities they belong to. Each body's user
+// data points at its entity too, for code that only has Box2D to hand.
+type registry struct {
+	byBody map[*box2d.B2Body]*Entity
+	nextID uint64
+}
+
+func newRegistry() *registry {
+	return &registry{byBody: map[*box2d.B2Body]*Entity{}, nextID: 1}
+}
+
+func (r *registry) add(body *box2d.B2Body, typ EntityType) *Entity {
+	e := &Entity{Body: body}
+	r.adopt(e, typ)
+	return e
+}
+
+// adopt registers an entity that has its body already, under a new ID
+func (r *registry) adopt(e *Entity, typ EntityType) {
+	e.ID, e.Type = r.nextID, typ
+	r.nextID++
+	r.byBody[e.Body] = e
+	e.Body.SetUserData(e)
+}
+
+func (r *registry) remove(body *box2d.B2Body) {
+	delete(r.byBody, body)
+}
+
+// reserve makes sure an ID taken from a snapshot is never handed out again
+func (r *registry) reserve(id uint64) {
+	if id >= r.nextID {
+		r.nextID = id + 1
+	}
+}
+
+// bodies lists every body in an order that doesn't depend on how Box2D keeps
+// them, which pooling shuffles: those that don't belong to an entity in the
+// order they were made, then trees and entities together by ID. Trees and
+// entities are each kept in ID order already. The slice is reused by the next
+// call.
+func (w *World) bodies() []*box2d.B2Body {
+	all := w.ordered[:0]
+	for body := w.physics.GetBodyList(); body != nil; body = body.GetNext() {
+		if _, ok := w.Lookup(body); !ok && body.IsActive() {
+			all = append(all, body)
+		}
+	}
+	// Box2D puts each new body at the front of its list
+	for i, j := 0, len(all)-1; i < j; i, j = i+1, j-1 {
+		all[i], all[j] = all[j], all[i]
+	}
+	trees, entities := w.trees, w.entities
+	for len(trees) > 0 || len(entities) > 0 {
+		if len(entities) == 0 || len(trees) > 0 && w.registry.byBody[trees[0]].ID < entities[0].ID {
+			all = append(all, trees[0])
+			trees = trees[1:]
+		} else {
+			all = append(all, entities[0].Body)
+			entities = entities[1:]
+		}
+	}
+	w.ordered = all
+	return all
+}
+
+// Lookup finds the entity a body belongs to. The ground and anything created
+// directly through Physics don't belong to any entity.
+func (w *World) Lookup(body *box2d.B2Body) (*Entity, bool) {
+	e, ok := w.registry.byBody[body]
+	return e, ok
+}
+
+// Pick finds the entity nearest to a position in metres, as long as it's
+// within radius metres. It returns nil if there's nothing close enough.
+func (w *World) Pick(pos pixel.Vec, radius float64) *Entity {
+	aabb := box2d.MakeB2AABB()
+	aabb.LowerBound = box2d.MakeB2Vec2(pos.X-radius, pos.Y-radius)
+	aabb.UpperBound = box2d.MakeB2Vec2(pos.X+radius, pos.Y+radius)
+
+	var nearest *Entity
+	best := radius
+	w.physics.QueryAABB(func(fixture *box2d.B2Fixture) bool {
+		e, ok := w.Lookup(fixture.GetBody())
+		if !ok {
+			return true
+		}
+		p := e.Body.GetPosition()
+		if d := pixel.V(p.X, p.Y).Sub(pos).Len(); d <= best {
+			nearest = e
+			best = d
+		}
+		return true
+	}, aabb)
+	return nearest
+}
+
+// At finds the entity with a body under a position in metres, if there is one
+func (w *World) At(pos pixel.Vec) *Entity {
+	point := box2d.MakeB2Vec2(pos.X, pos.Y)
+	aabb := box2d.MakeB2AABB()
+	aabb.LowerBound = box2d.MakeB2Vec2(pos.X-0.01, pos.Y-0.01)
+	aabb.UpperBound = box2d.MakeB2Vec2(pos.X+0.01, pos.Y+0.01)
+	var found *Entity
+	w.physics.QueryAABB(func(fixture *box2d.B2Fixture) bool {
+		if fixture.TestPoint(point) {
+			found, _ = w.Lookup(fixture.GetBody())
+		}
+		return found == nil
+	}, aabb)
+	return found
+}
+
+// Query finds every entity with a fixture overlapping a rectangle in metres
+func (w *World) Query(r pixel.Rect) []*Entity {
+	aabb := box2d.MakeB2AABB()
+	aabb.LowerBound = box2d.MakeB2Vec2(r.Min.X, r.Min.Y)
+	aabb.UpperBound = box2d.MakeB2Vec2(r.Max.X, r.Max.Y)
+
+	seen := map[*Entity]bool{}
+	var found []*Entity
+	w.physics.QueryAABB(func(fixture *box2d.B2Fixture) bool {
+		if e, ok := w.Lookup(fixture.GetBody()); ok && !seen[e] {
+			seen[e] = true
+			found = append(found, e)
+		}
+		return true
+	}, aabb)
+	return found
+}
@@ -0,0 +1,125 @@
+package trees
+
+import (
+	"math"
+
+	"github.com/ByteArena/box2d"
+	"github.com/faiface/pixel"
+)
+
+// Where the pile of trees on the mountain can form
+var pileArea = pixel.R(-50, 0, 50, Bounds.Max.Y)
+
+const (
+	// Width in metres of the columns the surface of the pile is measured in
+	reposeColumn = 2
+
+	// Trees slower than this in metres per second count as settled
+	reposeSettled = 0.2
+
+	// Fewest columns on a side of the pile that are worth fitting a slope to
+	reposeMinColumns = 3
+)
+
+// Slope is a straight line fitted to one side of the pile, running from
+// the bottom to the top in metres, and how steep it is in radians
+type Slope struct {
+	From, To pixel.Vec
+	Angle    float64
+}
+
+// Repose is the angle of repose of the pile of trees on the mountain, which
+// is the steepest slope a pile of loose material settles into. Angle is the
+// average of the two sides in radians.
+type Repose struct {
+	Left, Right Slope
+	Angle       float64
+}
+
+// AngleOfRepose measures the surface of the pile of settled trees on the
+// mountain and fits a slope to each side. It reports false until there's
+// enough of a pile to measure.
+func (w *World) AngleOfRepose() (Repose, bool) {
+	// The top of the highest settled tree in each column
+	columns := int(pileArea.W() / reposeColumn)
+	tops := make([]float64, columns)
+	for i := range tops {
+		tops[i] = math.Inf(-1)
+	}
+	for _, e := range w.Query(pileArea) {
+		if _, isTree := e.Type.(treeType); !isTree || !settled(e.Body) {
+			continue
+		}
+		p := e.Body.GetPosition()
+		i := int((p.X - pileArea.Min.X) / reposeColumn)
+		if i >= 0 && i < columns && p.Y+TreeRadius > tops[i] {
+			tops[i] = p.Y + TreeRadius
+		}
+	}
+
+	// Split the surface at the peak and fit a slope to each side
+	peak := -1
+	for i, top := range tops {
+		if !math.IsInf(top, -1) && (peak < 0 || top > tops[peak]) {
+			peak = i
+		}
+	}
+	if peak < 0 {
+		return Repose{}, false
+	}
+	side := func(from, to int) []pixel.Vec {
+		var surface []pixel.Vec
+		for i := from; i <= to; i++ {
+			if !math.IsInf(tops[i], -1) {
+				surface = append(surface, pixel.V(pileArea.Min.X+(float64(i)+0.5)*reposeColumn, tops[i]))
+			}
+		}
+		return surface
+	}
+	left, right := side(0, peak), side(peak, columns-1)
+	if len(left) < reposeMinColumns || len(right) < reposeMinColumns {
+		return Repose{}, false
+	}
+	r := Repose{Left: fitSlope(left), Right: fitSlope(right)}
+	r.Angle = (r.Left.Angle + r.Right.Angle) / 2
+	return r, true
+}
+
+// settled reports whether a body has come to rest on something
+func settled(body *box2d.B2Body) bool {
+	if !body.IsAwake() {
+		return true
+	}
+	if v := body.GetLinearVelocity(); math.Hypot(v.X, v.Y) >= reposeSettled {
+		return false
+	}
+	for edge := body.GetContactList(); edge != nil; edge = edge.Next {
+		if edge.Contact.IsTouching() {
+			return true
+		}
+	}
+	return false
+}
+
+// fitSlope fits a straight line through points by least squares
+func fitSlope(points []pixel.Vec) Slope {
+	var sx, sy, sxx, sxy float64
+	for _, p := range points {
+		sx += p.X
+		sy += p.Y
+		sxx += p.X * p.X
+		sxy += p.X * p.Y
+	}
+	n := float64(len(points))
+	gradient := 0.0
+	if d := n*sxx - sx*sx; d != 0 {
+		gradient = (n*sxy - sx*sy) / d
+	}
+	intercept := (sy - gradient*sx) / n
+	at := func(x float64) pixel.Vec { return pixel.V(x, gradient*x+intercept) }
+	from, to := at(points[0].X), at(points[len(points)-1].X)
+	if from.Y > to.Y {
+		from, to = to, from
+	}
+	return Slope{From: from, To: to, Angle: math.Atan(math.Abs(gradient))}
+}
@@ -0,0 +1,120 @@
+package trees
+
+import (
+	"github.com/ByteArena/box2d"
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"github.com/scottyw/falling-trees/spline"
+	"golang.org/x/image/colornames"
+)
+
+// Gap in metres between the control points of a curve drawn with the mouse
+const sketchSpacing = 3
+
+// sketch collects control points for a smooth curve while the mouse is dragged
+type sketch struct {
+	points       []pixel.Vec
+	subdivisions int
+}
+
+func (s *sketch) begin(w *World, pos pixel.Vec) {
+	s.points = []pixel.Vec{pos}
+	s.subdivisions = w.opts.Subdivisions
+}
+
+func (s *sketch) extend(pos pixel.Vec) {
+	if len(s.points) > 0 && pos.Sub(s.points[len(s.points)-1]).Len() >= sketchSpacing {
+		s.points = append(s.points, pos)
+	}
+}
+
+// finish adds the final point and hands back the control points, if there
+// are enough to make a curve
+func (s *sketch) finish(pos pixel.Vec) []pixel.Vec {
+	if len(s.points) > 0 && pos != s.points[len(s.points)-1] {
+		s.points = append(s.points, pos)
+	}
+	points := s.points
+	s.points = nil
+	if len(points) < 2 {
+		return nil
+	}
+	return points
+}
+
+// draw previews the curve so far
+func (s *sketch) draw(imd *imdraw.IMDraw, thickness float64) {
+	drawCurve(imd, s.points, s.subdivisions, thickness)
+}
+
+// drawCurve draws the smooth curve through control points in metres
+func drawCurve(imd *imdraw.IMDraw, points []pixel.Vec, subdivisions int, thickness float64) {
+	scaled := make([]pixel.Vec, len(points))
+	for i, p := range points {
+		scaled[i] = p.Scaled(PixelsPerMetre)
+	}
+	spline.Draw(imd, scaled, subdivisions, thickness)
+}
+
+// ridge is a static strip of terrain following a smooth curve
+type ridge struct {
+	points []pixel.Vec // Control points in metres
+	body   *box2d.B2Body
+}
+
+// AddRidge builds a thin ridge of terrain along the smooth curve through two
+// or more control points in metres
+func (w *World) AddRidge(points []pixel.Vec) {
+	bodyDef := box2d.MakeB2BodyDef()
+	body := w.physics.CreateBody(&bodyDef)
+	samples := spline.Sample(points, w.opts.Subdivisions)
+	for i := 1; i < len(samples); i++ {
+		edge := box2d.MakeB2EdgeShape()
+		edge.Set(box2d.MakeB2Vec2(samples[i-1].X, samples[i-1].Y), box2d.MakeB2Vec2(samples[i].X, samples[i].Y))
+		fixtureDef := box2d.MakeB2FixtureDef()
+		fixtureDef.Shape = &edge
+		fixtureDef.Friction = 1
+		body.CreateFixtureFromDef(&fixtureDef)
+	}
+	setLayers(body, CollisionLayers{Category: LayerTerrain, Mask: LayerAll})
+	w.ridges = append(w.ridges, &ridge{points: append([]pixel.Vec(nil), points...), body: body})
+}
+
+// removeRidges levels every ridge
+func (w *World) removeRidges() {
+	for _, r := range w.ridges {
+		w.physics.DestroyBody(r.body)
+	}
+	w.ridges = nil
+}
+
+func (w *World) drawRidges(imd *imdraw.IMDraw) {
+	imd.Color = colornames.Saddlebrown
+	for _, r := range w.ridges {
+		drawCurve(imd, r.points, w.opts.Subdivisions, 6)
+	}
+}
+
+// ridgeTool draws a smooth ridge of terrain with the mouse
+type ridgeTool struct {
+	sketch
+}
+
+func (t *ridgeTool) Name() string                  { return "ridge" }
+func (t *ridgeTool) Press(w *World, pos pixel.Vec) { t.begin(w, pos) }
+func (t *ridgeTool) Drag(w *World, pos pixel.Vec)  { t.extend(pos) }
+
+func (t *ridgeTool) Release(w *World, pos pixel.Vec) {
+	if points := t.finish(pos); points != nil {
+		w.AddRidge(points)
+	}
+}
+
+func (t *ridgeTool) DrawOverlay(imd *imdraw.IMDraw) {
+	imd.Color = colornames.Saddlebrown
+	t.draw(imd, 6)
+}
+
+func init() {
+	RegisterTool(&ridgeTool{})
+}
@@ -0,0 +1,67 @@
+package trees
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/ByteArena/box2d"
+	"github.com/faiface/pixel"
+)
+
+// Scene freezes the World as it is now into a level file called name. The
+// terrain is written out shape by shape and the level starts from exactly
+// this moment, with every tree and entity where it is and moving as it is.
+// Trees and entities take their shapes from their types, so only their state
+// is kept, and the level's Decor isn't kept at all.
+func (w *World) Scene(name string) LevelFile {
+	f := LevelFile{
+		Version:    LevelFormat.Version(),
+		Name:       name,
+		Drop:       w.level.Drop,
+		Bins:       w.level.Bins,
+		SlowFields: w.level.SlowFields,
+	}
+	for fixture := w.anchor.GetFixtureList(); fixture != nil; fixture = fixture.GetNext() {
+		switch shape := fixture.GetShape().(type) {
+		case *box2d.B2PolygonShape:
+			points := make([]pixel.Vec, shape.M_count)
+			for i := range points {
+				points[i] = pixel.V(shape.M_vertices[i].X, shape.M_vertices[i].Y)
+			}
+			f.Polygons = append(f.Polygons, points)
+		case *box2d.B2CircleShape:
+			f.Pegs = append(f.Pegs, Peg{Centre: pixel.V(shape.M_p.X, shape.M_p.Y), Radius: shape.M_radius})
+		case *box2d.B2ChainShape:
+			points := make([]pixel.Vec, shape.M_count)
+			for i := range points {
+				points[i] = pixel.V(shape.M_vertices[i].X, shape.M_vertices[i].Y)
+			}
+			f.Chains = append(f.Chains, points)
+		}
+	}
+
+	// Box2D keeps its fixtures newest first, so put the terrain back in the
+	// order it was built
+	for i, j := 0, len(f.Polygons)-1; i < j; i, j = i+1, j-1 {
+		f.Polygons[i], f.Polygons[j] = f.Polygons[j], f.Polygons[i]
+	}
+	for i, j := 0, len(f.Pegs)-1; i < j; i, j = i+1, j-1 {
+		f.Pegs[i], f.Pegs[j] = f.Pegs[j], f.Pegs[i]
+	}
+	for i, j := 0, len(f.Chains)-1; i < j; i, j = i+1, j-1 {
+		f.Chains[i], f.Chains[j] = f.Chains[j], f.Chains[i]
+	}
+
+	s := w.Snapshot()
+	s.Level = ""
+	f.Scene = &s
+	return f
+}
+
+// WriteLevel saves a level file as indented JSON, for ReadLevel to load
+func WriteLevel(out io.Writer, f LevelFile) error {
+	f.Version = LevelFormat.Version()
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "\t")
+	return enc.Encode(f)
+}
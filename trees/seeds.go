@@ -0,0 +1,233 @@
+package trees
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/ByteArena/box2d"
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"golang.org/x/image/colornames"
+)
+
+const (
+	// Radius of a seed in metres
+	seedRadius = 0.15
+
+	// Most seeds there can be in the world at once
+	maxSeeds = 40
+
+	// Fastest a seed is thrown sideways from its tree, in metres per second
+	seedThrow = 3
+
+	// Seconds a seed has to lie on the soil before it sprouts, and the most it
+	// lasts before rotting if it never does. Seeds that fall below the kill
+	// plane rot straight away.
+	sproutDelay = 4
+	seedRot     = 30
+
+	// Seeds lying slower than this, in metres per second, are resting
+	seedResting = 0.1
+
+	// A seed only sprouts with no tree or object within this many metres,
+	// which keeps the forest from growing into a pile of its own
+	seedSpacing = 2
+
+	// How far each channel of a sprout's tint can stray from its parent's
+	seedTintVariation = 0.08
+
+	// Seconds a sprout takes to grow from a sapling of saplingSize times the
+	// size of a tree to a full tree
+	growTime    = 20
+	saplingSize = 0.3
+)
+
+// seedLayers put seeds in with the objects but only let them land on the
+// ground and the trees, so they don't knock anything else about
+var seedLayers = CollisionLayers{Category: LayerObjects, Mask: LayerTerrain | LayerTrees}
+
+// seed is thrown from a tree and grows into one like it if it comes to rest
+// on the soil. Seeds aren't entities, so they can't be picked or grabbed and
+// aren't kept in snapshots.
+type seed struct {
+	body    *box2d.B2Body
+	species int        // Which of Options.Sprites its parent was drawn with
+	tint    pixel.RGBA // Its parent's tint
+	parent  uint64
+	age     float64
+	resting float64 // Seconds lying on the soil
+}
+
+// Sprouted is published when a seed grows into a new tree, with the ID of the
+// tree it fell from, which may be gone by now
+type Sprouted struct {
+	Tree   *Entity
+	Parent uint64
+}
+
+// random is a number from 0 up to 1 from Options.Rand if there is one
+func (w *World) random() float64 {
+	if w.opts.Rand != nil {
+		return w.opts.Rand.Float64()
+	}
+	return rand.Float64()
+}
+
+// seeding lets trees that have landed with open sky above them drop seeds,
+// each one every Options.Seeding seconds on average, and sprouts the seeds
+// that lie on the soil long enough. Soil is the soft ground where there is
+// some and the bare terrain otherwise.
+func (w *World) seeding(dt float64) {
+	if w.opts.Seeding <= 0 {
+		return
+	}
+	for _, tree := range w.trees {
+		if len(w.seeds) >= maxSeeds {
+			break
+		}
+		e, ok := w.Lookup(tree)
+		if !ok || !e.landed || e.grow > 0 || w.random() >= dt/w.opts.Seeding || !w.exposed(tree) {
+			continue
+		}
+		w.dropSeed(e)
+	}
+
+	kept := w.seeds[:0]
+	for _, s := range w.seeds {
+		s.age += dt
+		if v := s.body.GetLinearVelocity(); w.onSoil(s.body) && math.Hypot(v.X, v.Y) < seedResting {
+			s.resting += dt
+		} else {
+			s.resting = 0
+		}
+		switch {
+		case s.resting >= sproutDelay:
+			w.sprout(s)
+		case s.age < seedRot && s.body.GetPosition().Y > w.killPlane():
+			kept = append(kept, s)
+			continue
+		}
+		w.physics.DestroyBody(s.body)
+	}
+	for i := len(kept); i < len(w.seeds); i++ {
+		w.seeds[i] = nil
+	}
+	w.seeds = kept
+}
+
+// dropSeed throws a seed off the top of a tree
+func (w *World) dropSeed(parent *Entity) {
+	c := parent.Body.GetWorldCenter()
+	bodyDef := box2d.MakeB2BodyDef()
+	bodyDef.Type = box2d.B2BodyType.B2_dynamicBody
+	bodyDef.Position.Set(c.X, c.Y+TreeRadius+seedRadius)
+	bodyDef.LinearVelocity.Set((2*w.random()-1)*seedThrow, seedThrow/2)
+	body := w.physics.CreateBody(&bodyDef)
+	shape := box2d.MakeB2CircleShape()
+	shape.SetRadius(seedRadius)
+	fixtureDef := box2d.MakeB2FixtureDef()
+	fixtureDef.Shape = &shape
+	fixtureDef.Density = 0.5
+	fixtureDef.Friction = 1
+	body.CreateFixtureFromDef(&fixtureDef)
+	setLayers(body, seedLayers)
+	w.seeds = append(w.seeds, &seed{body: body, species: parent.sprite, tint: treeTint(parent), parent: parent.ID})
+}
+
+// onSoil reports whether a body is touching the soil
+func (w *World) onSoil(body *box2d.B2Body) bool {
+	for edge := body.GetContactList(); edge != nil; edge = edge.Next {
+		if !edge.Contact.IsTouching() {
+			continue
+		}
+		other := edge.Contact.GetFixtureA()
+		if other.GetBody() == body {
+			other = edge.Contact.GetFixtureB()
+		}
+		if w.soil != nil {
+			if _, ok := w.soil.fixture[other]; ok {
+				return true
+			}
+			continue
+		}
+		if other.GetBody() == w.anchor && fixtureLayers(other).Category == LayerTerrain {
+			return true
+		}
+	}
+	return false
+}
+
+// sprout grows a sapling of the seed's species where it lies, tinted a little
+// differently from its parent, unless it's too crowded for one there
+func (w *World) sprout(s *seed) {
+	p := s.body.GetPosition()
+	at := pixel.V(p.X, p.Y-seedRadius+saplingSize*TreeRadius)
+	if len(w.Query(pixel.R(at.X-seedSpacing, at.Y-seedSpacing, at.X+seedSpacing, at.Y+seedSpacing))) > 0 {
+		return
+	}
+	tree := w.SpawnTree(at)
+	e, _ := w.Lookup(tree)
+	e.sprite = s.species
+	e.tint = s.tint
+	for _, c := range []*float64{&e.tint.R, &e.tint.G, &e.tint.B} {
+		*c = math.Min(1, math.Max(0, *c+(2*w.random()-1)*seedTintVariation))
+	}
+	e.grow = growTime
+	w.resize(e)
+	w.events.Publish(Sprouted{Tree: e, Parent: s.parent})
+}
+
+// growSaplings grows sprouted trees towards their full size
+func (w *World) growSaplings(dt float64) {
+	for _, tree := range w.trees {
+		if e, ok := w.Lookup(tree); ok && e.grow > 0 {
+			e.grow = math.Max(0, e.grow-dt)
+			w.resize(e)
+		}
+	}
+}
+
+// resize makes a tree's fixture as big as the tree has grown
+func (w *World) resize(e *Entity) {
+	e.Body.GetFixtureList().GetShape().(*box2d.B2CircleShape).SetRadius(TreeRadius * treeScale(e))
+	e.Body.ResetMassData()
+	e.Body.SetAwake(true)
+}
+
+// treeScale is how big a tree has grown, as a fraction of a full tree
+func treeScale(e *Entity) float64 {
+	return 1 - (1-saplingSize)*e.grow/growTime
+}
+
+// treeTint is what a tree's sprite is multiplied by, which is white for trees
+// that didn't grow from a seed
+func treeTint(e *Entity) pixel.RGBA {
+	if e.tint.A == 0 {
+		return pixel.RGB(1, 1, 1)
+	}
+	return e.tint
+}
+
+// Tint is the colour a tree's sprite is multiplied by, which strays a little
+// further from the original trees with each generation grown from seed
+func (w *World) Tint(e *Entity) pixel.RGBA {
+	return treeTint(e)
+}
+
+// removeSeeds clears every seed out of the world
+func (w *World) removeSeeds() {
+	for _, s := range w.seeds {
+		w.physics.DestroyBody(s.body)
+	}
+	w.seeds = nil
+}
+
+// drawSeeds draws each seed as a small brown dot in pixels
+func (w *World) drawSeeds(imd *imdraw.IMDraw) {
+	imd.Color = colornames.Saddlebrown
+	for _, s := range w.seeds {
+		p := s.body.GetPosition()
+		imd.Push(pixel.V(p.X, p.Y).Scaled(PixelsPerMetre))
+		imd.Circle(seedRadius*PixelsPerMetre, 0)
+	}
+}
@@ -0,0 +1,112 @@
+package trees
+
+import (
+	"math"
+
+	"github.com/ByteArena/box2d"
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"golang.org/x/image/colornames"
+)
+
+// Defaults for slow fields placed with the slow field tool
+const (
+	slowFieldRadius = 6
+	slowFieldDrag   = 6
+)
+
+// SlowField is a bullet-time bubble. Trees and entities inside it lose speed
+// at Drag per second, so they drift through slowly and come out the other side
+// moving much slower than they went in. Centre and Radius are in metres.
+type SlowField struct {
+	Centre pixel.Vec `json:"centre"`
+	Radius float64   `json:"radius"`
+	Drag   float64   `json:"drag"`
+}
+
+// Contains reports whether a position in metres is inside the field
+func (f *SlowField) Contains(pos pixel.Vec) bool {
+	return pos.Sub(f.Centre).Len() < f.Radius
+}
+
+// AddSlowField places a slow field in the world
+func (w *World) AddSlowField(f SlowField) *SlowField {
+	field := &f
+	w.fields = append(w.fields, field)
+	return field
+}
+
+// RemoveSlowField takes a slow field out of the world
+func (w *World) RemoveSlowField(field *SlowField) {
+	for i, f := range w.fields {
+		if f == field {
+			w.fields = append(w.fields[:i], w.fields[i+1:]...)
+			return
+		}
+	}
+}
+
+// SlowFields lists every slow field in the world. The slice belongs to the
+// World and must not be modified.
+func (w *World) SlowFields() []*SlowField {
+	return w.fields
+}
+
+// slowDown damps the velocity of everything inside a slow field, ahead of a
+// step of dt seconds
+func (w *World) slowDown(dt float64) {
+	for _, f := range w.fields {
+		scale := math.Exp(-f.Drag * dt)
+		damp := func(body *box2d.B2Body) {
+			p := body.GetPosition()
+			if body.GetType() != box2d.B2BodyType.B2_dynamicBody || !f.Contains(pixel.V(p.X, p.Y)) {
+				return
+			}
+			v := body.GetLinearVelocity()
+			body.SetLinearVelocity(box2d.MakeB2Vec2(v.X*scale, v.Y*scale))
+			body.SetAngularVelocity(body.GetAngularVelocity() * scale)
+		}
+		for _, tree := range w.trees {
+			damp(tree)
+		}
+		for _, e := range w.entities {
+			damp(e.Body)
+		}
+	}
+}
+
+// drawSlowFields washes a translucent tint over each field and whatever is in it
+func (w *World) drawSlowFields(imd *imdraw.IMDraw) {
+	for _, f := range w.fields {
+		centre := f.Centre.Scaled(PixelsPerMetre)
+		radius := f.Radius * PixelsPerMetre
+		imd.Color = pixel.ToRGBA(colornames.Lightskyblue).Scaled(0.35)
+		imd.Push(centre)
+		imd.Circle(radius, 0)
+		imd.Color = pixel.ToRGBA(colornames.Steelblue).Scaled(0.6)
+		imd.Push(centre)
+		imd.Circle(radius, 2)
+	}
+}
+
+// slowFieldTool places a slow field, or takes one away if pressed inside it
+type slowFieldTool struct{}
+
+func (slowFieldTool) Name() string { return "slow field" }
+
+func (slowFieldTool) Press(w *World, pos pixel.Vec) {
+	for _, f := range w.fields {
+		if f.Contains(pos) {
+			w.RemoveSlowField(f)
+			return
+		}
+	}
+	w.AddSlowField(SlowField{Centre: pos, Radius: slowFieldRadius, Drag: slowFieldDrag})
+}
+
+func (slowFieldTool) Drag(w *World, pos pixel.Vec)    {}
+func (slowFieldTool) Release(w *World, pos pixel.Vec) {}
+
+func init() {
+	RegisterTool(slowFieldTool{})
+}
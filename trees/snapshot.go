@@ -0,0 +1,296 @@
+package trees
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+
+	"github.com/ByteArena/box2d"
+	"github.com/faiface/pixel"
+)
+
+// Snapshot is the state of every movable body in a World, ready to be saved
+// as JSON and restored later
+type Snapshot struct {
+	Version    int           `json:"version"`
+	Level      string        `json:"level,omitempty"`
+	Trees      []BodyState   `json:"trees"`
+	Entities   []EntityState `json:"entities,omitempty"`
+	SlowFields []SlowField   `json:"slowFields,omitempty"`
+	Paths      []PathState   `json:"paths,omitempty"`
+	Ridges     [][]pixel.Vec `json:"ridges,omitempty"`
+	Stamps     []StampState  `json:"stamps,omitempty"`
+
+	// Metres the soft ground had sunk at each edge of its columns
+	Soil []float64 `json:"soil,omitempty"`
+}
+
+// BodyState is the position in metres, angle in radians and velocity of a body
+type BodyState struct {
+	X     float64 `json:"x"`
+	Y     float64 `json:"y"`
+	Angle float64 `json:"angle"`
+	VX    float64 `json:"vx"`
+	VY    float64 `json:"vy"`
+	Spin  float64 `json:"spin"`
+	Awake bool    `json:"awake"`
+
+	// Frozen bodies are static until thawed
+	Frozen bool `json:"frozen,omitempty"`
+
+	// ID of its entity, or 0 for a new one as it's restored
+	ID uint64 `json:"id,omitempty"`
+
+	// Wave it was spawned in, or 0 for whichever wave it's restored in
+	Wave int `json:"wave,omitempty"`
+
+	// Seconds it had been in the world, towards its lifetime
+	Age float64 `json:"age,omitempty"`
+
+	// Trees only, which of the world's sprites it's drawn with counting from
+	// 1, or 0 for whichever it picks as it's restored
+	Sprite int `json:"sprite,omitempty"`
+
+	// Trees only, how much snow had settled on it
+	Snow float64 `json:"snow,omitempty"`
+
+	// Trees grown from seed only, the red, green and blue of their tint and
+	// seconds they had left to grow
+	Tint    []float64 `json:"tint,omitempty"`
+	Growing float64   `json:"growing,omitempty"`
+}
+
+// PathState is a path being followed by one of the entities in a snapshot,
+// given by its index in Entities, and how far along it has got
+type PathState struct {
+	Entity    int         `json:"entity"`
+	Points    []pixel.Vec `json:"points"`
+	Speed     float64     `json:"speed"`
+	Travelled float64     `json:"travelled"`
+}
+
+// EntityState is a BodyState for something spawned from a registered EntityType
+type EntityState struct {
+	Type string `json:"type"`
+	BodyState
+}
+
+func bodyState(body *box2d.B2Body) BodyState {
+	p := body.GetPosition()
+	v := body.GetLinearVelocity()
+	return BodyState{
+		X:     p.X,
+		Y:     p.Y,
+		Angle: body.GetAngle(),
+		VX:    v.X,
+		VY:    v.Y,
+		Spin:  body.GetAngularVelocity(),
+		Awake: body.IsAwake(),
+
+		Frozen: body.GetType() == box2d.B2BodyType.B2_staticBody,
+	}
+}
+
+func (s BodyState) apply(body *box2d.B2Body) {
+	if s.Frozen {
+		body.SetType(box2d.B2BodyType.B2_staticBody)
+	}
+	body.SetTransform(box2d.MakeB2Vec2(s.X, s.Y), s.Angle)
+	body.SetLinearVelocity(box2d.MakeB2Vec2(s.VX, s.VY))
+	body.SetAngularVelocity(s.Spin)
+	body.SetAwake(s.Awake)
+}
+
+// Snapshot captures the current state of the World
+func (w *World) Snapshot() Snapshot {
+	s := Snapshot{Version: SnapshotFormat.Version(), Level: w.level.Name}
+	for _, tree := range w.trees {
+		t := bodyState(tree)
+		if e, ok := w.Lookup(tree); ok {
+			t.ID, t.Wave, t.Age, t.Snow = e.ID, e.Wave, w.Age(e), e.snow
+			if e.tint.A > 0 {
+				t.Tint, t.Growing = []float64{e.tint.R, e.tint.G, e.tint.B}, e.grow
+			}
+			if n := len(w.opts.Sprites); n > 0 {
+				t.Sprite = e.sprite%n + 1
+			}
+		}
+		s.Trees = append(s.Trees, t)
+	}
+	index := map[*Entity]int{}
+	for i, e := range w.entities {
+		index[e] = i
+		state := EntityState{Type: e.Type.Name(), BodyState: bodyState(e.Body)}
+		state.ID, state.Wave, state.Age = e.ID, e.Wave, w.Age(e)
+		s.Entities = append(s.Entities, state)
+	}
+	for _, f := range w.fields {
+		s.SlowFields = append(s.SlowFields, *f)
+	}
+	for _, r := range w.ridges {
+		s.Ridges = append(s.Ridges, r.points)
+	}
+	for _, st := range w.stamps {
+		s.Stamps = append(s.Stamps, st.StampState)
+	}
+	s.Soil = w.SoilSunk()
+	for _, g := range w.guides {
+		if i, ok := index[g.entity]; ok {
+			s.Paths = append(s.Paths, PathState{Entity: i, Points: g.path.points, Speed: g.speed, Travelled: g.travelled})
+		}
+	}
+	return s
+}
+
+// restoreEntity puts back the ID, wave, age, sprite, snow and tint of a
+// restored entity, moving the World on to its wave if that's later than the
+// current one
+func (w *World) restoreEntity(e *Entity, s BodyState) {
+	if s.ID > 0 {
+		e.ID = s.ID
+	}
+	e.born = w.elapsed - s.Age
+	if s.Sprite > 0 {
+		e.sprite = s.Sprite - 1
+	}
+	e.snow = s.Snow
+	if len(s.Tint) == 3 {
+		e.tint = pixel.RGB(s.Tint[0], s.Tint[1], s.Tint[2])
+		e.grow = math.Min(math.Max(s.Growing, 0), growTime)
+		w.resize(e)
+	}
+	if s.Wave <= 0 {
+		return
+	}
+	e.Wave = s.Wave
+	if s.Wave > w.wave {
+		w.wave = s.Wave
+	}
+}
+
+// resolve looks up the entity types and stamps in the snapshot and checks its
+// ridges and paths, so that Restore doesn't fail half way through
+func (s Snapshot) resolve() ([]EntityType, []Stamp, error) {
+	types := make([]EntityType, len(s.Entities))
+	for i, e := range s.Entities {
+		typ, ok := LookupEntityType(e.Type)
+		if !ok {
+			return nil, nil, fmt.Errorf("trees: unknown entity type %q", e.Type)
+		}
+		types[i] = typ
+	}
+	library := make([]Stamp, len(s.Stamps))
+	for i, p := range s.Stamps {
+		st, ok := LookupStamp(p.Stamp)
+		if !ok {
+			return nil, nil, fmt.Errorf("trees: unknown stamp %q", p.Stamp)
+		}
+		library[i] = st
+	}
+	for _, r := range s.Ridges {
+		if len(r) < 2 {
+			return nil, nil, fmt.Errorf("trees: ridge with %d points", len(r))
+		}
+	}
+	ids := map[uint64]bool{}
+	states := make([]BodyState, 0, len(s.Trees)+len(s.Entities))
+	states = append(states, s.Trees...)
+	for _, e := range s.Entities {
+		states = append(states, e.BodyState)
+	}
+	for _, b := range states {
+		if b.ID > 0 && ids[b.ID] {
+			return nil, nil, fmt.Errorf("trees: more than one body with ID %d", b.ID)
+		}
+		ids[b.ID] = true
+	}
+	for _, p := range s.Paths {
+		if p.Entity < 0 || p.Entity >= len(s.Entities) || len(p.Points) == 0 {
+			return nil, nil, fmt.Errorf("trees: bad path for entity %d", p.Entity)
+		}
+	}
+	return types, library, nil
+}
+
+// Restore replaces every tree, entity, slow field, path, ridge and stamp in the
+// World with those in a snapshot, and sinks any soft ground as deep as it
+// was. Every entity type and stamp in the snapshot must be registered, and the
+// snapshot must be of the World's level.
+func (w *World) Restore(s Snapshot) error {
+	if s.Level != "" && s.Level != w.level.Name {
+		return fmt.Errorf("trees: snapshot is of level %q, not %q", s.Level, w.level.Name)
+	}
+	types, library, err := s.resolve()
+	if err != nil {
+		return err
+	}
+
+	for len(w.trees) > 0 {
+		w.RemoveTree(w.trees[len(w.trees)-1])
+	}
+	for len(w.entities) > 0 {
+		w.RemoveEntity(w.entities[len(w.entities)-1].Body)
+	}
+	w.removeSeeds()
+	w.restoreSoil(s.Soil)
+	// Bodies restored without an ID mustn't be given one that's coming up
+	for _, t := range s.Trees {
+		w.registry.reserve(t.ID)
+	}
+	for _, e := range s.Entities {
+		w.registry.reserve(e.ID)
+	}
+	for _, t := range s.Trees {
+		tree := w.SpawnTree(pixel.V(t.X, t.Y))
+		t.apply(tree)
+		e, _ := w.Lookup(tree)
+		w.restoreEntity(e, t)
+	}
+	spawned := make([]*Entity, len(s.Entities))
+	for i, e := range s.Entities {
+		spawned[i] = w.Spawn(types[i], pixel.V(e.X, e.Y))
+		e.apply(spawned[i].Body)
+		w.restoreEntity(spawned[i], e.BodyState)
+	}
+	// Snapshots list them by ID, but one written by hand might not
+	sort.SliceStable(w.trees, func(i, j int) bool { return w.registry.byBody[w.trees[i]].ID < w.registry.byBody[w.trees[j]].ID })
+	sort.SliceStable(w.entities, func(i, j int) bool { return w.entities[i].ID < w.entities[j].ID })
+	w.removeRidges()
+	for _, r := range s.Ridges {
+		w.AddRidge(r)
+	}
+	w.removeStamps()
+	for i, p := range s.Stamps {
+		w.addStamp(library[i], p)
+	}
+	w.guides = nil
+	for _, p := range s.Paths {
+		w.guides = append(w.guides, &guide{entity: spawned[p.Entity], path: NewPath(p.Points), speed: p.Speed, travelled: p.Travelled})
+	}
+	w.fields = nil
+	for _, f := range s.SlowFields {
+		w.AddSlowField(f)
+	}
+	w.decals = nil
+	w.landings = nil
+	w.topples = nil
+	w.toppled = map[*Entity]bool{}
+	return nil
+}
+
+// WriteSnapshot saves the current state of the World as JSON
+func (w *World) WriteSnapshot(out io.Writer) error {
+	return json.NewEncoder(out).Encode(w.Snapshot())
+}
+
+// ReadSnapshot restores the World from JSON written by WriteSnapshot, in this
+// build or an older one
+func (w *World) ReadSnapshot(in io.Reader) error {
+	var s Snapshot
+	if err := SnapshotFormat.Decode(in, &s); err != nil {
+		return err
+	}
+	return w.Restore(s)
+}
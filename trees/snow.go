@@ -0,0 +1,97 @@
+package trees
+
+import (
+	"math"
+
+	"github.com/ByteArena/box2d"
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+)
+
+const (
+	// Seconds of simulated time between looks at which trees are snowed on,
+	// since looking up what's above every tree each step would be wasteful
+	snowCheck = 0.5
+
+	// Seconds a tree has to have been at rest, asleep or moving slower than
+	// snowMoving metres per second, before snow settles on it
+	snowSettle = 3
+	snowMoving = 0.2
+
+	// Seconds a cap takes to melt away once its tree is moving
+	snowMelt = 1
+)
+
+// snowfall builds up caps of snow on trees that have been at rest a while
+// with nothing above them, and melts them off trees that move, while
+// Options.Snowfall is set. Piles hardly ever fall fully asleep, so trees that
+// are barely moving count as resting too.
+func (w *World) snowfall(dt float64) {
+	if w.opts.Snowfall <= 0 {
+		return
+	}
+	w.snowed += dt
+	if w.snowed < snowCheck {
+		return
+	}
+	elapsed := w.snowed
+	w.snowed = 0
+	for _, tree := range w.trees {
+		e, ok := w.Lookup(tree)
+		if !ok {
+			continue
+		}
+		if v := tree.GetLinearVelocity(); tree.IsAwake() && math.Hypot(v.X, v.Y) > snowMoving {
+			e.still = 0
+			e.snow = math.Max(0, e.snow-elapsed/snowMelt)
+			continue
+		}
+		e.still += elapsed
+		if e.still >= snowSettle && e.snow < 1 && w.exposed(tree) {
+			e.snow = math.Min(1, e.snow+elapsed/w.opts.Snowfall)
+		}
+	}
+}
+
+// exposed reports whether there's nothing above a body to keep the snow off
+// it, all the way up to the top of the world
+func (w *World) exposed(body *box2d.B2Body) bool {
+	c := body.GetWorldCenter()
+	from := box2d.MakeB2Vec2(c.X, c.Y+TreeRadius)
+	if from.Y >= Bounds.Max.Y {
+		return true
+	}
+	open := true
+	w.physics.RayCast(func(f *box2d.B2Fixture, point, normal box2d.B2Vec2, fraction float64) float64 {
+		if f.GetBody() == body || f.IsSensor() {
+			return -1
+		}
+		open = false
+		return 0
+	}, from, box2d.MakeB2Vec2(c.X, Bounds.Max.Y))
+	return open
+}
+
+// Snow is how much snow has settled on a tree, from 0 for none to 1 for a
+// full cap
+func (w *World) Snow(e *Entity) float64 {
+	return e.snow
+}
+
+// drawSnow draws a white cap over the canopy of each tree with snow on it,
+// growing deeper as the snow builds up
+func (w *World) drawSnow(imd *imdraw.IMDraw) {
+	if w.opts.Snowfall <= 0 {
+		return
+	}
+	for _, tree := range w.trees {
+		e, ok := w.Lookup(tree)
+		if !ok || e.snow <= 0 {
+			continue
+		}
+		at := w.drawnAt(e).Add(pixel.V(0, 0.55*TreeRadius))
+		imd.Color = pixel.Alpha(0.95 * w.fade(tree))
+		imd.Push(at.Scaled(PixelsPerMetre))
+		imd.EllipseArc(pixel.V(0.8, 0.45*e.snow).Scaled(TreeRadius*PixelsPerMetre), 0, math.Pi, 0)
+	}
+}
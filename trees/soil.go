@@ -0,0 +1,272 @@
+package trees
+
+import (
+	"math"
+
+	"github.com/ByteArena/box2d"
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"golang.org/x/image/colornames"
+)
+
+// SoftGround is a layer of soil over the terrain that sinks under whatever
+// rests on it, so a big pile slowly presses a hollow into the ground
+type SoftGround struct {
+	// Metres of soil laid over the terrain, or 0 for none
+	Depth float64
+
+	// Pressure in newtons per metre of ground the soil bears without giving
+	Firmness float64
+
+	// Metres per second the soil sinks for every newton per metre of
+	// pressure beyond its firmness
+	Softness float64
+}
+
+// SoftSoil is a couple of metres of soil that holds up a tree or two but
+// gives under a pile over half a minute or so
+var SoftSoil = SoftGround{Depth: 2, Firmness: 60, Softness: 0.0002}
+
+const (
+	// Width in metres of each column of soil
+	soilWidth = 1
+
+	// Soil can be packed down to this thin and no further, in metres
+	soilPacked = 0.1
+
+	// Metres an edge of a column sinks before its fixture is rebuilt, so the
+	// physics isn't rebuilding the ground every step
+	soilRebuild = 0.05
+
+	// Bodies moving slower than this, in metres per second, are resting on
+	// the soil rather than landing on it and press it down
+	soilResting = 0.5
+)
+
+// soil is the soft ground laid over a world's terrain, as columns of soil
+// between evenly spaced edges, each column a fixture of its own so only the
+// columns that have sunk are rebuilt
+type soil struct {
+	body    *box2d.B2Body
+	bed     []float64     // Height of the terrain under each edge
+	sunk    []float64     // Metres the soil at each edge has sunk
+	columns []*soilColumn // Nil where there's no soil, over gaps and pegs
+	fixture map[*box2d.B2Fixture]int
+}
+
+type soilColumn struct {
+	fixture *box2d.B2Fixture
+	built   [2]float64 // How far each edge had sunk when the fixture was built
+	load    float64    // Newtons pressing down on it this step
+}
+
+// edgeX is where the i'th edge between columns is
+func edgeX(i int) float64 {
+	return Bounds.Min.X + float64(i)*soilWidth
+}
+
+// laySoil covers the level's terrain in soil wherever it's even enough to
+// hold it, which leaves out pegs, drops and anything the level started with
+// on the ground, since soil laid over that would bury it
+func (w *World) laySoil() {
+	if w.opts.SoftGround.Depth <= 0 {
+		return
+	}
+	n := int(Bounds.W() / soilWidth)
+	bodyDef := box2d.MakeB2BodyDef()
+	s := &soil{
+		body:    w.physics.CreateBody(&bodyDef),
+		bed:     make([]float64, n+1),
+		sunk:    make([]float64, n+1),
+		columns: make([]*soilColumn, n),
+		fixture: map[*box2d.B2Fixture]int{},
+	}
+	found := make([]bool, n+1)
+	for i := range s.bed {
+		s.bed[i], found[i] = w.terrainHeight(edgeX(i))
+	}
+	for i := range s.columns {
+		if !found[i] || !found[i+1] || math.Abs(s.bed[i+1]-s.bed[i]) > soilWidth {
+			continue
+		}
+		mid, ok := w.terrainHeight(edgeX(i) + soilWidth/2)
+		if !ok || math.Abs(mid-(s.bed[i]+s.bed[i+1])/2) > soilPacked {
+			continue
+		}
+		top := math.Max(s.bed[i], s.bed[i+1]) + w.opts.SoftGround.Depth
+		if len(w.Query(pixel.R(edgeX(i), math.Min(s.bed[i], s.bed[i+1]), edgeX(i+1), top))) > 0 {
+			continue
+		}
+		s.columns[i] = &soilColumn{}
+	}
+	w.soil = s
+	for i := range s.columns {
+		w.buildColumn(i)
+	}
+}
+
+// terrainHeight is the height of the top of the level's terrain at x, if
+// there's any there
+func (w *World) terrainHeight(x float64) (float64, bool) {
+	height, found := 0.0, false
+	w.physics.RayCast(func(f *box2d.B2Fixture, point, normal box2d.B2Vec2, fraction float64) float64 {
+		if f.GetBody() != w.anchor || fixtureLayers(f).Category != LayerTerrain {
+			return -1
+		}
+		height, found = point.Y, true
+		return fraction
+	}, box2d.MakeB2Vec2(x, Bounds.Max.Y), box2d.MakeB2Vec2(x, Bounds.Min.Y))
+	return height, found
+}
+
+// soilTop is the height of the top of the soil at an edge
+func (w *World) soilTop(i int) float64 {
+	return w.soil.bed[i] + w.opts.SoftGround.Depth - w.soil.sunk[i]
+}
+
+// buildColumn replaces the fixture of a column of soil with one as deep as
+// the soil is now, waking anything resting on it so it settles onto the new
+// surface rather than hanging where the old one was
+func (w *World) buildColumn(i int) {
+	s := w.soil
+	c := s.columns[i]
+	if c == nil {
+		return
+	}
+	if c.fixture != nil {
+		delete(s.fixture, c.fixture)
+		s.body.DestroyFixture(c.fixture)
+	}
+	shape := box2d.MakeB2PolygonShape()
+	shape.Set([]box2d.B2Vec2{
+		box2d.MakeB2Vec2(edgeX(i), s.bed[i]),
+		box2d.MakeB2Vec2(edgeX(i+1), s.bed[i+1]),
+		box2d.MakeB2Vec2(edgeX(i+1), w.soilTop(i+1)),
+		box2d.MakeB2Vec2(edgeX(i), w.soilTop(i)),
+	}, 4)
+	fixtureDef := box2d.MakeB2FixtureDef()
+	fixtureDef.Shape = &shape
+	fixtureDef.Friction = 1
+	c.fixture = s.body.CreateFixtureFromDef(&fixtureDef)
+	c.built = [2]float64{s.sunk[i], s.sunk[i+1]}
+	s.fixture[c.fixture] = i
+
+	top := math.Max(w.soilTop(i), w.soilTop(i+1)) + soilRebuild + TreeRadius
+	for _, e := range w.Query(pixel.R(edgeX(i), math.Min(s.bed[i], s.bed[i+1]), edgeX(i+1), top)) {
+		e.Body.SetAwake(true)
+	}
+}
+
+// settleSoil sinks the soil under the weight resting on it over a step of dt
+// seconds, measured from the impulses the solver held it up with
+func (w *World) settleSoil(dt float64) {
+	s := w.soil
+	if s == nil || dt <= 0 {
+		return
+	}
+	for _, c := range s.columns {
+		if c != nil {
+			c.load = 0
+		}
+	}
+	// Impulses are those of the last substep
+	sub := dt
+	if n := w.opts.Solver.SubSteps; n > 1 {
+		sub = dt / float64(n)
+	}
+	for contact := w.physics.GetContactList(); contact != nil; contact = contact.GetNext() {
+		if !contact.IsTouching() {
+			continue
+		}
+		i, ok := s.fixture[contact.GetFixtureA()]
+		other := contact.GetFixtureB().GetBody()
+		if !ok {
+			i, ok = s.fixture[contact.GetFixtureB()]
+			other = contact.GetFixtureA().GetBody()
+		}
+		if !ok || other.GetType() != box2d.B2BodyType.B2_dynamicBody {
+			continue
+		}
+		if v := other.GetLinearVelocity(); math.Hypot(v.X, v.Y) > soilResting {
+			continue
+		}
+		var manifold box2d.B2WorldManifold
+		contact.GetWorldManifold(&manifold)
+		for p := 0; p < contact.GetManifold().PointCount; p++ {
+			s.columns[i].load += contact.GetManifold().Points[p].NormalImpulse * math.Abs(manifold.Normal.Y) / sub
+		}
+	}
+
+	// Each edge sinks by the average of the columns either side
+	ground := w.opts.SoftGround
+	sinks := make([]float64, len(s.columns))
+	for i, c := range s.columns {
+		if c != nil {
+			sinks[i] = math.Max(0, c.load/soilWidth-ground.Firmness) * ground.Softness * dt
+		}
+	}
+	for i := range s.sunk {
+		var sink float64
+		if i > 0 {
+			sink += sinks[i-1] / 2
+		}
+		if i < len(sinks) {
+			sink += sinks[i] / 2
+		}
+		s.sunk[i] = math.Min(s.sunk[i]+sink, ground.Depth-soilPacked)
+	}
+	for i, c := range s.columns {
+		if c != nil && (s.sunk[i]-c.built[0] >= soilRebuild || s.sunk[i+1]-c.built[1] >= soilRebuild) {
+			w.buildColumn(i)
+		}
+	}
+}
+
+// SoilSunk is how far the soft ground has sunk at each edge between its
+// columns, a metre apart from the left of Bounds, or nil without soft ground
+func (w *World) SoilSunk() []float64 {
+	if w.soil == nil {
+		return nil
+	}
+	return append([]float64(nil), w.soil.sunk...)
+}
+
+// restoreSoil sinks the soft ground to how it was, or puts it back as it was
+// laid if the depths are for some other ground
+func (w *World) restoreSoil(sunk []float64) {
+	s := w.soil
+	if s == nil {
+		return
+	}
+	for i := range s.sunk {
+		s.sunk[i] = 0
+		if len(sunk) == len(s.sunk) {
+			s.sunk[i] = math.Min(math.Max(sunk[i], 0), w.opts.SoftGround.Depth-soilPacked)
+		}
+	}
+	for i := range s.columns {
+		w.buildColumn(i)
+	}
+}
+
+// drawSoil draws the soil as it is, in pixels, which can be ahead of its
+// fixtures by up to soilRebuild
+func (w *World) drawSoil(imd *imdraw.IMDraw) {
+	s := w.soil
+	if s == nil {
+		return
+	}
+	imd.Color = colornames.Sienna
+	for i, c := range s.columns {
+		if c == nil {
+			continue
+		}
+		imd.Push(
+			pixel.V(edgeX(i), s.bed[i]).Scaled(PixelsPerMetre),
+			pixel.V(edgeX(i+1), s.bed[i+1]).Scaled(PixelsPerMetre),
+			pixel.V(edgeX(i+1), w.soilTop(i+1)).Scaled(PixelsPerMetre),
+			pixel.V(edgeX(i), w.soilTop(i)).Scaled(PixelsPerMetre),
+		)
+		imd.Polygon(0)
+	}
+}
@@ -0,0 +1,125 @@
+package trees
+
+import (
+	"math"
+
+	"github.com/ByteArena/box2d"
+)
+
+// SolverProfile trades the quality of the physics against how much work each
+// step takes
+type SolverProfile struct {
+	Name string
+
+	// Solver iterations for each sub-step
+	VelocityIterations int
+	PositionIterations int
+
+	// Each step is split into this many smaller steps, which keeps fast
+	// bodies from tunnelling and piles from sinking into each other
+	SubSteps int
+
+	// Bodies slower than SleepSpeed in metres per second for SleepAfter
+	// seconds are put to sleep, on top of Box2D's own much stricter test.
+	// Zero leaves sleeping to Box2D.
+	SleepSpeed float64
+	SleepAfter float64
+
+	// NoSleep keeps every body awake all the time
+	NoSleep bool
+}
+
+// The built-in solver profiles, from cheapest to best
+var (
+	SolverFast = SolverProfile{
+		Name:               "fast",
+		VelocityIterations: 4,
+		PositionIterations: 2,
+		SubSteps:           1,
+		SleepSpeed:         0.25,
+		SleepAfter:         0.25,
+	}
+	SolverBalanced = SolverProfile{
+		Name:               "balanced",
+		VelocityIterations: 8,
+		PositionIterations: 3,
+		SubSteps:           1,
+	}
+	SolverAccurate = SolverProfile{
+		Name:               "accurate",
+		VelocityIterations: 10,
+		PositionIterations: 8,
+		SubSteps:           4,
+		NoSleep:            true,
+	}
+)
+
+// SolverProfiles lists the built-in solver profiles from cheapest to best
+func SolverProfiles() []SolverProfile {
+	return []SolverProfile{SolverFast, SolverBalanced, SolverAccurate}
+}
+
+// LookupSolverProfile finds a built-in solver profile by name
+func LookupSolverProfile(name string) (SolverProfile, bool) {
+	for _, p := range SolverProfiles() {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return SolverProfile{}, false
+}
+
+// SetSolver switches to a different solver profile from the next step
+func (w *World) SetSolver(p SolverProfile) {
+	w.opts.Solver = p
+	w.physics.SetAllowSleeping(!p.NoSleep)
+}
+
+// Solver is the solver profile currently in use
+func (w *World) Solver() SolverProfile {
+	return w.opts.Solver
+}
+
+// stepPhysics runs the physics for dt seconds using the current solver profile
+func (w *World) stepPhysics(dt float64) {
+	p := w.opts.Solver
+	n := p.SubSteps
+	if n < 1 {
+		n = 1
+	}
+	for i := 0; i < n; i++ {
+		w.physics.Step(dt/float64(n), p.VelocityIterations, p.PositionIterations)
+	}
+	if p.SleepSpeed > 0 && !p.NoSleep {
+		w.sleepSlow(dt)
+	}
+}
+
+// sleepSlow puts to sleep anything that has been crawling along for a while
+func (w *World) sleepSlow(dt float64) {
+	p := w.opts.Solver
+	check := func(e *Entity) {
+		body := e.Body
+		if body.GetType() != box2d.B2BodyType.B2_dynamicBody || !body.IsAwake() {
+			e.rest = 0
+			return
+		}
+		v := body.GetLinearVelocity()
+		spin := body.GetAngularVelocity() * TreeRadius
+		if math.Hypot(v.X, v.Y) > p.SleepSpeed || math.Abs(spin) > p.SleepSpeed {
+			e.rest = 0
+			return
+		}
+		e.rest += dt
+		if e.rest >= p.SleepAfter {
+			body.SetAwake(false)
+			e.rest = 0
+		}
+	}
+	for _, tree := range w.trees {
+		check(w.registry.byBody[tree])
+	}
+	for _, e := range w.entities {
+		check(e)
+	}
+}
@@ -0,0 +1,59 @@
+package trees
+
+import (
+	"math"
+
+	"github.com/ByteArena/box2d"
+)
+
+// SetMaxSpeed caps how fast trees or entities of the named type move, in
+// metres per second, so that an explosion or a tool can't fling them fast
+// enough to tunnel through the ground or upset the solver. Zero takes the cap
+// off again.
+func (w *World) SetMaxSpeed(typ string, speed float64) {
+	speeds := map[string]float64{}
+	for name, s := range w.opts.MaxSpeeds {
+		speeds[name] = s
+	}
+	if speed > 0 {
+		speeds[typ] = speed
+	} else {
+		delete(speeds, typ)
+	}
+	w.opts.MaxSpeeds = speeds
+}
+
+// MaxSpeed is how fast bodies of the named type may move in metres per
+// second, or zero if there's no limit
+func (w *World) MaxSpeed(typ string) float64 {
+	return w.opts.MaxSpeeds[typ]
+}
+
+// clampSpeeds slows anything moving faster than its type allows down to its
+// maximum speed, heading the same way. It runs once everything pushing on the
+// world has had its say and just before the physics steps, so impulses given
+// between steps are caught before they move anything. Only bodies the physics
+// moves are slowed, since paths and the like move theirs on purpose.
+func (w *World) clampSpeeds() {
+	if len(w.opts.MaxSpeeds) == 0 {
+		return
+	}
+	clamp := func(e *Entity) {
+		max := w.opts.MaxSpeeds[e.Type.Name()]
+		if max <= 0 || e.Body.GetType() != box2d.B2BodyType.B2_dynamicBody {
+			return
+		}
+		v := e.Body.GetLinearVelocity()
+		if speed := math.Hypot(v.X, v.Y); speed > max {
+			e.Body.SetLinearVelocity(box2d.MakeB2Vec2(v.X*max/speed, v.Y*max/speed))
+		}
+	}
+	for _, tree := range w.trees {
+		if e, ok := w.Lookup(tree); ok {
+			clamp(e)
+		}
+	}
+	for _, e := range w.entities {
+		clamp(e)
+	}
+}
@@ -0,0 +1,90 @@
+package trees
+
+import (
+	"github.com/ByteArena/box2d"
+	"github.com/faiface/pixel"
+)
+
+// TreeSprite is one of the sprites trees can be drawn with, along with the
+// point on it placed over the centre of mass of the tree, in sprite pixels
+// from the centre of its frame
+type TreeSprite struct {
+	Sprite *pixel.Sprite
+	Pivot  pixel.Vec
+
+	// Frames of an animation, such as the tree swaying, cycled through
+	// FrameRate times a second of simulated time. Empty for a sprite that
+	// stays as it is. Every frame shares the pivot.
+	Frames    []*pixel.Sprite
+	FrameRate float64
+}
+
+// frame is the sprite to show after t seconds of animation
+func (s TreeSprite) frame(t float64) *pixel.Sprite {
+	if len(s.Frames) == 0 || s.FrameRate <= 0 {
+		return s.Sprite
+	}
+	return s.Frames[int(t*s.FrameRate)%len(s.Frames)]
+}
+
+// Animate moves every tree's animation on by dt seconds, for sprites with
+// Frames. Trees start at different points in their animations so they don't
+// sway in step, and frozen trees hold still.
+func (w *World) Animate(dt float64) {
+	for _, tree := range w.trees {
+		if tree.GetType() == box2d.B2BodyType.B2_staticBody {
+			continue
+		}
+		if e, ok := w.Lookup(tree); ok {
+			e.played += dt
+		}
+	}
+}
+
+// pickSprite chooses which of the sprites a tree is drawn with. Mixing up its
+// ID rather than drawing from Options.Rand scatters the choices just as well
+// without changing where any tree is scattered, so worlds and replays from
+// the same seed still play out the same.
+func pickSprite(id uint64) int {
+	id ^= id >> 33
+	id *= 0xff51afd7ed558ccd
+	id ^= id >> 33
+	id *= 0xc4ceb9fe1a85ec53
+	id ^= id >> 33
+	return int(id >> 1)
+}
+
+// spriteMatrix places a tree sprite so it covers the body of a tree at pos,
+// in pixels, with its pivot over the centre, for a tree grown to size times
+// the size of a full one
+func spriteMatrix(sprite *pixel.Sprite, pivot, pos pixel.Vec, size float64) pixel.Matrix {
+	scale := 2 * size * TreeRadius * PixelsPerMetre / sprite.Frame().W()
+	return pixel.IM.Moved(pivot.Scaled(-1)).Scaled(pixel.ZV, scale).Moved(pos)
+}
+
+// spritePicture is the picture trees are drawn from, or nil if they're drawn
+// as circles
+func (w *World) spritePicture() pixel.Picture {
+	if len(w.opts.Sprites) > 0 {
+		return w.opts.Sprites[0].Sprite.Picture()
+	}
+	if w.opts.Sprite != nil {
+		return w.opts.Sprite.Picture()
+	}
+	return nil
+}
+
+// treeSprite is the sprite a tree is drawn with right now, the frame of its
+// animation it's up to if it has one
+func (w *World) treeSprite(tree *box2d.B2Body) (*pixel.Sprite, pixel.Vec) {
+	n := len(w.opts.Sprites)
+	if n == 0 {
+		return w.opts.Sprite, w.opts.SpritePivot
+	}
+	e, ok := w.Lookup(tree)
+	if !ok {
+		return w.opts.Sprites[0].Sprite, w.opts.Sprites[0].Pivot
+	}
+	s := w.opts.Sprites[e.sprite%n]
+	return s.frame(e.played), s.Pivot
+}
@@ -0,0 +1,190 @@
+package trees
+
+import (
+	"math"
+	"sort"
+
+	"github.com/ByteArena/box2d"
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"golang.org/x/image/colornames"
+)
+
+// Stamp is a prefab piece of terrain. Each piece is a convex polygon of up to
+// eight points in metres around the spot the stamp is placed.
+type Stamp struct {
+	Name   string
+	Pieces [][]pixel.Vec
+}
+
+var stamps = map[string]Stamp{}
+
+// RegisterStamp adds a shape to the library of terrain stamps, replacing any
+// existing stamp with the same name. Each stamp gets a tool that places it.
+func RegisterStamp(s Stamp) {
+	stamps[s.Name] = s
+}
+
+// LookupStamp finds a registered stamp by name
+func LookupStamp(name string) (Stamp, bool) {
+	s, ok := stamps[name]
+	return s, ok
+}
+
+// Stamps lists the registered stamps sorted by name
+func Stamps() []Stamp {
+	var all []Stamp
+	for _, s := range stamps {
+		all = append(all, s)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Name < all[j].Name })
+	return all
+}
+
+// StampState is where a stamp went down in metres, turned by Angle radians
+// and scaled up by Scale
+type StampState struct {
+	Stamp string  `json:"stamp"`
+	X     float64 `json:"x"`
+	Y     float64 `json:"y"`
+	Angle float64 `json:"angle"`
+	Scale float64 `json:"scale"`
+}
+
+// matrix takes the stamp's own coordinates to world coordinates in metres
+func (p StampState) matrix() pixel.Matrix {
+	return pixel.IM.Scaled(pixel.ZV, p.Scale).Rotated(pixel.ZV, p.Angle).Moved(pixel.V(p.X, p.Y))
+}
+
+// stamped is a stamp that's part of the world
+type stamped struct {
+	StampState
+	pieces [][]pixel.Vec // In world coordinates
+	body   *box2d.B2Body
+}
+
+// Stamp adds a piece of static terrain from the stamp library, centred on a
+// position in metres, turned by angle radians and scaled by scale
+func (w *World) Stamp(s Stamp, pos pixel.Vec, angle, scale float64) {
+	w.addStamp(s, StampState{Stamp: s.Name, X: pos.X, Y: pos.Y, Angle: angle, Scale: scale})
+}
+
+func (w *World) addStamp(s Stamp, p StampState) {
+	bodyDef := box2d.MakeB2BodyDef()
+	body := w.physics.CreateBody(&bodyDef)
+	st := &stamped{StampState: p, body: body}
+	m := p.matrix()
+	for _, piece := range s.Pieces {
+		var placed []pixel.Vec
+		var vertices []box2d.B2Vec2
+		for _, v := range piece {
+			v = m.Project(v)
+			placed = append(placed, v)
+			vertices = append(vertices, box2d.MakeB2Vec2(v.X, v.Y))
+		}
+		shape := box2d.MakeB2PolygonShape()
+		shape.Set(vertices, len(vertices))
+		fixtureDef := box2d.MakeB2FixtureDef()
+		fixtureDef.Shape = &shape
+		fixtureDef.Friction = 1
+		body.CreateFixtureFromDef(&fixtureDef)
+		st.pieces = append(st.pieces, placed)
+	}
+	setLayers(body, CollisionLayers{Category: LayerTerrain, Mask: LayerAll})
+	w.stamps = append(w.stamps, st)
+}
+
+// removeStamps clears away every stamp
+func (w *World) removeStamps() {
+	for _, s := range w.stamps {
+		w.physics.DestroyBody(s.body)
+	}
+	w.stamps = nil
+}
+
+func (w *World) drawStamps(imd *imdraw.IMDraw) {
+	imd.Color = colornames.Sandybrown
+	for _, s := range w.stamps {
+		drawPieces(imd, s.pieces, 0)
+	}
+}
+
+// drawPieces pushes polygons in metres, filled if thickness is zero
+func drawPieces(imd *imdraw.IMDraw, pieces [][]pixel.Vec, thickness float64) {
+	for _, piece := range pieces {
+		for _, v := range piece {
+			imd.Push(v.Scaled(PixelsPerMetre))
+		}
+		imd.Polygon(thickness)
+	}
+}
+
+// Dragging the stamp tool further than this in metres turns and scales the
+// stamp, with a drag of stampReach leaving it at its usual size
+const stampReach = 6
+
+// StampTool places a stamp where the mouse is pressed. Drag before letting go
+// to turn the stamp to face the mouse, and scale it by how far it's dragged.
+type StampTool struct {
+	Stamp Stamp
+
+	anchor, mouse pixel.Vec
+	pressed       bool
+}
+
+func (t *StampTool) Name() string { return "stamp " + t.Stamp.Name }
+
+func (t *StampTool) Press(w *World, pos pixel.Vec) {
+	t.anchor, t.mouse, t.pressed = pos, pos, true
+}
+
+func (t *StampTool) Drag(w *World, pos pixel.Vec) {
+	t.mouse = pos
+}
+
+func (t *StampTool) Release(w *World, pos pixel.Vec) {
+	t.mouse, t.pressed = pos, false
+	w.addStamp(t.Stamp, t.placement())
+}
+
+// placement works out where the stamp goes from the drag so far
+func (t *StampTool) placement() StampState {
+	p := StampState{Stamp: t.Stamp.Name, X: t.anchor.X, Y: t.anchor.Y, Scale: 1}
+	if drag := t.mouse.Sub(t.anchor); drag.Len() >= 1 {
+		p.Angle = drag.Angle()
+		p.Scale = math.Max(0.25, math.Min(4, drag.Len()/stampReach))
+	}
+	return p
+}
+
+// DrawOverlay outlines where the stamp will go
+func (t *StampTool) DrawOverlay(imd *imdraw.IMDraw) {
+	if !t.pressed {
+		return
+	}
+	m := t.placement().matrix()
+	var pieces [][]pixel.Vec
+	for _, piece := range t.Stamp.Pieces {
+		var placed []pixel.Vec
+		for _, v := range piece {
+			placed = append(placed, m.Project(v))
+		}
+		pieces = append(pieces, placed)
+	}
+	imd.Color = colornames.Saddlebrown
+	drawPieces(imd, pieces, 2)
+}
+
+func init() {
+	RegisterStamp(Stamp{Name: "ramp", Pieces: [][]pixel.Vec{
+		{pixel.V(-4, -1), pixel.V(4, -1), pixel.V(4, 2)},
+	}})
+	RegisterStamp(Stamp{Name: "bowl", Pieces: [][]pixel.Vec{
+		{pixel.V(-3, -1.5), pixel.V(3, -1.5), pixel.V(3, -1), pixel.V(-3, -1)},
+		{pixel.V(-3, -1.5), pixel.V(-3, -1), pixel.V(-5.5, 2), pixel.V(-6, 2)},
+		{pixel.V(3, -1.5), pixel.V(6, 2), pixel.V(5.5, 2), pixel.V(3, -1)},
+	}})
+	RegisterStamp(Stamp{Name: "ledge", Pieces: [][]pixel.Vec{
+		{pixel.V(-4, -0.25), pixel.V(4, -0.25), pixel.V(4, 0.25), pixel.V(-4, 0.25)},
+	}})
+}
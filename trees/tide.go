@@ -0,0 +1,71 @@
+package trees
+
+import (
+	"math"
+
+	"github.com/ByteArena/box2d"
+	"github.com/faiface/pixel"
+)
+
+// tideWake is how far gravity has to drift, as a fraction of its usual
+// strength, before sleeping bodies are woken to feel it. Waking them on every
+// step would keep the whole world awake for good.
+const tideWake = 0.02
+
+// Tide makes gravity ebb and flow over time, in strength, direction or both.
+// A Strength above 1 turns gravity right around for part of each cycle, so
+// trees lift off the pile and come crashing back down.
+type Tide struct {
+	// Seconds of simulated time for one whole cycle. Zero turns the tide off.
+	Period float64 `json:"period"`
+
+	// Gravity swings between 1-Strength and 1+Strength times its usual
+	// strength
+	Strength float64 `json:"strength"`
+
+	// Radians the direction of gravity swings each way
+	Swing float64 `json:"swing"`
+}
+
+// SetTide starts gravity ebbing and flowing about the gravity set with
+// SetGravity, or stops it with a zero Tide
+func (w *World) SetTide(t Tide) {
+	w.opts.Tide = t
+	w.applyTide(true)
+	w.events.Publish(GravityChanged{Gravity: w.opts.Gravity, Tide: t})
+}
+
+// Tide is the current tide
+func (w *World) Tide() Tide {
+	return w.opts.Tide
+}
+
+// applyTide sets gravity for this moment of the tide, waking everything if
+// it's moved far enough since they were last woken or if forced to
+func (w *World) applyTide(force bool) {
+	gravity := w.opts.Gravity
+	if t := w.opts.Tide; t.Period > 0 {
+		phase := math.Sin(2 * math.Pi * w.elapsed / t.Period)
+		gravity = gravity.Scaled(1 + t.Strength*phase).Rotated(t.Swing * phase)
+	}
+	w.physics.SetGravity(box2d.MakeB2Vec2(gravity.X, gravity.Y))
+	drift := gravity.Sub(w.woken).Len()
+	if force || drift > tideWake*math.Max(w.opts.Gravity.Len(), 1) {
+		w.woken = gravity
+		w.wakeAll()
+	}
+}
+
+// wakeAll wakes every moving body
+func (w *World) wakeAll() {
+	for _, body := range w.bodies() {
+		if body.GetType() == box2d.B2BodyType.B2_dynamicBody {
+			body.SetAwake(true)
+		}
+	}
+}
+
+// Gravity is the gravity set with SetGravity, before any tide
+func (w *World) Gravity() pixel.Vec {
+	return w.opts.Gravity
+}
@@ -0,0 +1,48 @@
+package trees
+
+import "github.com/faiface/pixel"
+
+// Advance moves the simulation on by dt seconds of simulated time in fixed
+// steps of FixedStep, however long the frame took, so the physics behaves the
+// same at any frame rate. Time left over that doesn't make up a whole step is
+// carried over to the next call, and Render draws trees part of the way from
+// where they were after the second to last step to where they are now to
+// make up for it. Advance returns how many steps it took. Time beyond
+// MaxAdvance is dropped rather than simulated.
+func (w *World) Advance(dt float64) int {
+	if limit := w.opts.MaxAdvance; limit > 0 && dt > limit {
+		w.events.Publish(Hitch{Elapsed: dt, Dropped: dt - limit})
+		dt = limit
+	}
+	step := w.opts.FixedStep
+	if step <= 0 {
+		w.Step(dt)
+		return 1
+	}
+	w.leftover += dt
+	steps := 0
+	for w.leftover >= step {
+		for _, tree := range w.trees {
+			if e, ok := w.Lookup(tree); ok {
+				c := tree.GetWorldCenter()
+				e.previous = pixel.V(c.X, c.Y)
+				e.interpolate = true
+			}
+		}
+		w.Step(step)
+		w.leftover -= step
+		steps++
+	}
+	w.blend = w.leftover / step
+	return steps
+}
+
+// drawnAt is where a tree is drawn in metres, between its last two steps
+func (w *World) drawnAt(e *Entity) pixel.Vec {
+	c := e.Body.GetWorldCenter()
+	now := pixel.V(c.X, c.Y)
+	if !e.interpolate || w.blend >= 1 {
+		return now
+	}
+	return pixel.Lerp(e.previous, now, w.blend)
+}
@@ -0,0 +1,580 @@
+package trees
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"image"
+	_ "image/jpeg" // Tilesets are usually PNGs, but can be JPEGs
+	_ "image/png"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/ByteArena/box2d"
+	"github.com/faiface/pixel"
+)
+
+// Bits of a tile's global ID that say how it's flipped, rather than which
+// tile it is
+const (
+	tmxFlippedAcross   = 0x80000000
+	tmxFlippedDown     = 0x40000000
+	tmxFlippedDiagonal = 0x20000000
+	tmxFlipBits        = 0xf0000000
+)
+
+// The parts of a Tiled map that make up a level
+type tmxMap struct {
+	Orientation string        `xml:"orientation,attr"`
+	Infinite    int           `xml:"infinite,attr"`
+	Width       int           `xml:"width,attr"`
+	Height      int           `xml:"height,attr"`
+	TileWidth   int           `xml:"tilewidth,attr"`
+	TileHeight  int           `xml:"tileheight,attr"`
+	Properties  []tmxProperty `xml:"properties>property"`
+	Tilesets    []tmxTileset  `xml:"tileset"`
+	Layers      []tmxLayer    `xml:"layer"`
+	Groups      []tmxGroup    `xml:"objectgroup"`
+
+	// Kinds of layer that can't be made into a level
+	Folders []struct{} `xml:"group"`
+	Images  []struct{} `xml:"imagelayer"`
+}
+
+type tmxProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type tmxTileset struct {
+	FirstGID   uint32   `xml:"firstgid,attr"`
+	Source     string   `xml:"source,attr"`
+	TileWidth  int      `xml:"tilewidth,attr"`
+	TileHeight int      `xml:"tileheight,attr"`
+	Spacing    int      `xml:"spacing,attr"`
+	Margin     int      `xml:"margin,attr"`
+	TileCount  int      `xml:"tilecount,attr"`
+	Columns    int      `xml:"columns,attr"`
+	Image      tmxImage `xml:"image"`
+}
+
+type tmxImage struct {
+	Source string `xml:"source,attr"`
+}
+
+type tmxLayer struct {
+	Name    string  `xml:"name,attr"`
+	Visible *int    `xml:"visible,attr"`
+	OffsetX float64 `xml:"offsetx,attr"`
+	OffsetY float64 `xml:"offsety,attr"`
+	Width   int     `xml:"width,attr"`
+	Height  int     `xml:"height,attr"`
+	Data    tmxData `xml:"data"`
+}
+
+type tmxData struct {
+	Encoding    string `xml:"encoding,attr"`
+	Compression string `xml:"compression,attr"`
+	Text        string `xml:",chardata"`
+}
+
+type tmxGroup struct {
+	Name    string      `xml:"name,attr"`
+	OffsetX float64     `xml:"offsetx,attr"`
+	OffsetY float64     `xml:"offsety,attr"`
+	Objects []tmxObject `xml:"object"`
+}
+
+type tmxObject struct {
+	Name     string     `xml:"name,attr"`
+	Type     string     `xml:"type,attr"`
+	Class    string     `xml:"class,attr"`
+	GID      uint32     `xml:"gid,attr"`
+	X        float64    `xml:"x,attr"`
+	Y        float64    `xml:"y,attr"`
+	Width    float64    `xml:"width,attr"`
+	Height   float64    `xml:"height,attr"`
+	Rotation float64    `xml:"rotation,attr"`
+	Ellipse  *struct{}  `xml:"ellipse"`
+	Point    *struct{}  `xml:"point"`
+	Polygon  *tmxPoints `xml:"polygon"`
+	Polyline *tmxPoints `xml:"polyline"`
+}
+
+type tmxPoints struct {
+	Points string `xml:"points,attr"`
+}
+
+// ReadTMX loads a level from a map made in the Tiled editor. Rectangles,
+// ellipses, polygons and polylines in its object layers become the terrain,
+// apart from a rectangle of the class drop, which is where trees are dropped,
+// and rectangles of the class bin, which are the level's bins. Tiles in its
+// tile layers, and tile objects, are drawn as the level's Decor. The map can
+// set the level's name, trees and colour with custom properties of those
+// names, and is named name otherwise.
+//
+// Tiled's pixels are the world's pixels, PixelsPerMetre to the metre, and the
+// bottom middle of the map is the world origin. Tilesets and their images are
+// opened from files, relative to the map, and if files is nil the tiles are
+// left out. The level isn't registered, so it's up to the caller to pass it
+// to RegisterLevel.
+func ReadTMX(in io.Reader, files fs.FS, name string) (Level, error) {
+	var m tmxMap
+	if err := xml.NewDecoder(in).Decode(&m); err != nil {
+		return Level{}, fmt.Errorf("trees: reading map: %v", err)
+	}
+	switch {
+	case m.Orientation != "orthogonal":
+		return Level{}, fmt.Errorf("trees: map is %s, not orthogonal", m.Orientation)
+	case m.Infinite != 0:
+		return Level{}, fmt.Errorf("trees: infinite maps aren't supported")
+	case len(m.Folders) > 0:
+		return Level{}, fmt.Errorf("trees: layers in groups aren't supported")
+	case len(m.Images) > 0:
+		return Level{}, fmt.Errorf("trees: image layers aren't supported")
+	}
+
+	f := LevelFile{Version: LevelFormat.Version(), Name: name}
+	for _, p := range m.Properties {
+		var err error
+		switch p.Name {
+		case "name":
+			f.Name = p.Value
+		case "trees":
+			f.Trees, err = strconv.Atoi(p.Value)
+		case "colour", "color":
+			f.Colour = p.Value
+		}
+		if err != nil {
+			return Level{}, fmt.Errorf("trees: map property %s: %v", p.Name, err)
+		}
+	}
+	origin := pixel.V(float64(m.Width*m.TileWidth)/2, float64(m.Height*m.TileHeight))
+	for _, g := range m.Groups {
+		for _, o := range g.Objects {
+			if o.GID != 0 || o.Point != nil {
+				continue // Tile objects are decor and points have no shape
+			}
+			if err := f.addObject(g, o, origin); err != nil {
+				return Level{}, fmt.Errorf("trees: object %q: %v", o.Name, err)
+			}
+		}
+	}
+	l, err := f.Level()
+	if err != nil || files == nil {
+		return l, err
+	}
+	tiles := &tmxTiles{files: files, sprites: map[uint32]*pixel.Sprite{}}
+	for _, ts := range m.Tilesets {
+		if err := tiles.add(ts); err != nil {
+			return Level{}, err
+		}
+	}
+	l.Decor, err = tiles.decor(m, origin)
+	return l, err
+}
+
+// addObject adds the shape of an object in its group to the level file
+func (f *LevelFile) addObject(g tmxGroup, o tmxObject, origin pixel.Vec) error {
+	// Tiled measures down from the top left of the map, and turns objects
+	// clockwise about their own top left
+	turn := pixel.IM.Rotated(pixel.ZV, o.Rotation*math.Pi/180)
+	at := pixel.V(o.X+g.OffsetX, o.Y+g.OffsetY)
+	world := func(p pixel.Vec) pixel.Vec {
+		p = at.Add(turn.Project(p))
+		return pixel.V(p.X-origin.X, origin.Y-p.Y).Scaled(1.0 / PixelsPerMetre)
+	}
+	class := o.Class
+	if class == "" {
+		class = o.Type
+	}
+
+	switch {
+	case o.Ellipse != nil:
+		radius := (o.Width + o.Height) / 4 / PixelsPerMetre
+		f.Pegs = append(f.Pegs, Peg{Centre: world(pixel.V(o.Width/2, o.Height/2)), Radius: radius})
+	case o.Polyline != nil:
+		points, err := o.Polyline.parse()
+		if err != nil {
+			return err
+		}
+		for i := range points {
+			points[i] = world(points[i])
+		}
+		f.Chains = append(f.Chains, points)
+	case o.Polygon != nil:
+		points, err := o.Polygon.parse()
+		if err != nil {
+			return err
+		}
+		for i := range points {
+			points[i] = world(points[i])
+		}
+		pieces, err := convexPieces(points)
+		if err != nil {
+			return err
+		}
+		f.Polygons = append(f.Polygons, pieces...)
+	default:
+		corners := []pixel.Vec{world(pixel.ZV), world(pixel.V(o.Width, 0)), world(pixel.V(o.Width, o.Height)), world(pixel.V(0, o.Height))}
+		bounds := pixel.Rect{Min: corners[0], Max: corners[0]}
+		for _, c := range corners {
+			bounds = bounds.Union(pixel.Rect{Min: c, Max: c})
+		}
+		switch {
+		case class == "drop":
+			f.Drop = bounds
+		case class == "bin":
+			f.Bins = append(f.Bins, bounds)
+		case o.Rotation == 0:
+			f.Boxes = append(f.Boxes, bounds)
+		default:
+			f.Polygons = append(f.Polygons, corners)
+		}
+	}
+	return nil
+}
+
+// parse reads the points of a polygon or polyline, in pixels from the
+// object's top left
+func (p tmxPoints) parse() ([]pixel.Vec, error) {
+	var points []pixel.Vec
+	for _, pair := range strings.Fields(p.Points) {
+		xy := strings.Split(pair, ",")
+		if len(xy) != 2 {
+			return nil, fmt.Errorf("bad point %q", pair)
+		}
+		x, err := strconv.ParseFloat(xy[0], 64)
+		if err != nil {
+			return nil, err
+		}
+		y, err := strconv.ParseFloat(xy[1], 64)
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, pixel.V(x, y))
+	}
+	return points, nil
+}
+
+// convexPieces cuts a polygon into pieces Box2D can make fixtures of: the
+// polygon itself if it's convex and has few enough points, or else triangles
+// clipped off it one ear at a time. It fails if any piece would be too small
+// or thin for Box2D.
+func convexPieces(points []pixel.Vec) ([][]pixel.Vec, error) {
+	pieces, err := earClip(points)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range pieces {
+		if degenerate(p) {
+			return nil, fmt.Errorf("polygon has a piece too small or thin to collide with")
+		}
+	}
+	return pieces, nil
+}
+
+// earClip cuts a polygon into convex pieces, without checking their size
+func earClip(points []pixel.Vec) ([][]pixel.Vec, error) {
+	if len(points) < 3 {
+		return nil, fmt.Errorf("polygon with %d points", len(points))
+	}
+	area := 0.0
+	for i, p := range points {
+		area += p.Cross(points[(i+1)%len(points)])
+	}
+	if math.Abs(area) < 1e-9 {
+		return nil, fmt.Errorf("polygon with no area")
+	}
+	if crossesItself(points) {
+		return nil, fmt.Errorf("polygon crosses itself")
+	}
+	// Work anticlockwise, so every ear turns left, leaving out points in a
+	// straight line with their neighbours since they make no ears
+	turnsLeft := func(a, b, c pixel.Vec) bool { return b.Sub(a).Cross(c.Sub(b)) > 0 }
+	var ring []pixel.Vec
+	for i, p := range points {
+		n := len(points)
+		if before, after := points[(i+n-1)%n], points[(i+1)%n]; p.Sub(before).Cross(after.Sub(p)) != 0 {
+			ring = append(ring, p)
+		}
+	}
+	if area < 0 {
+		for i, j := 0, len(ring)-1; i < j; i, j = i+1, j-1 {
+			ring[i], ring[j] = ring[j], ring[i]
+		}
+	}
+	convex := true
+	for i := range ring {
+		n := len(ring)
+		if !turnsLeft(ring[(i+n-1)%n], ring[i], ring[(i+1)%n]) {
+			convex = false
+		}
+	}
+	if convex && len(ring) <= box2d.B2_maxPolygonVertices {
+		return [][]pixel.Vec{ring}, nil
+	}
+
+	var pieces [][]pixel.Vec
+	for len(ring) > 3 {
+		clipped := false
+		for i := range ring {
+			n := len(ring)
+			a, b, c := ring[(i+n-1)%n], ring[i], ring[(i+1)%n]
+			if !turnsLeft(a, b, c) || anyInside(ring, a, b, c) {
+				continue
+			}
+			pieces = append(pieces, []pixel.Vec{a, b, c})
+			ring = append(ring[:i], ring[i+1:]...)
+			clipped = true
+			break
+		}
+		if !clipped {
+			return nil, fmt.Errorf("polygon crosses itself")
+		}
+	}
+	return append(pieces, ring), nil
+}
+
+// crossesItself reports whether any two edges of a polygon that aren't next to
+// each other cross or touch
+func crossesItself(points []pixel.Vec) bool {
+	n := len(points)
+	side := func(a, b, p pixel.Vec) float64 { return b.Sub(a).Cross(p.Sub(a)) }
+	between := func(a, b, p pixel.Vec) bool {
+		return math.Min(a.X, b.X) <= p.X && p.X <= math.Max(a.X, b.X) && math.Min(a.Y, b.Y) <= p.Y && p.Y <= math.Max(a.Y, b.Y)
+	}
+	for i := 0; i < n; i++ {
+		a, b := points[i], points[(i+1)%n]
+		for j := i + 2; j < n; j++ {
+			if i == 0 && j == n-1 {
+				continue
+			}
+			c, d := points[j], points[(j+1)%n]
+			ac, ad, ca, cb := side(a, b, c), side(a, b, d), side(c, d, a), side(c, d, b)
+			switch {
+			case ac*ad < 0 && ca*cb < 0:
+				return true
+			case ac == 0 && between(a, b, c), ad == 0 && between(a, b, d),
+				ca == 0 && between(c, d, a), cb == 0 && between(c, d, b):
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// anyInside reports whether any point of a ring other than a, b and c is
+// inside or on the triangle they make
+func anyInside(ring []pixel.Vec, a, b, c pixel.Vec) bool {
+	for _, p := range ring {
+		if p == a || p == b || p == c {
+			continue
+		}
+		if b.Sub(a).Cross(p.Sub(a)) >= 0 && c.Sub(b).Cross(p.Sub(b)) >= 0 && a.Sub(c).Cross(p.Sub(c)) >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// tmxTiles cuts the sprites of a map's tiles out of its tilesets' images
+type tmxTiles struct {
+	files    fs.FS
+	sets     []tmxTileset // With their pictures, by first global ID
+	pictures []pixel.Picture
+	sprites  map[uint32]*pixel.Sprite
+}
+
+// add loads a tileset, from its own file if it's kept in one
+func (t *tmxTiles) add(ts tmxTileset) error {
+	dir := "."
+	if ts.Source != "" {
+		first := ts.FirstGID
+		data, err := fs.ReadFile(t.files, ts.Source)
+		if err != nil {
+			return fmt.Errorf("trees: reading tileset: %v", err)
+		}
+		if err := xml.Unmarshal(data, &ts); err != nil {
+			return fmt.Errorf("trees: reading tileset %s: %v", ts.Source, err)
+		}
+		ts.FirstGID = first
+		dir = path.Dir(ts.Source)
+	}
+	if ts.Image.Source == "" {
+		return fmt.Errorf("trees: tilesets of separate images aren't supported")
+	}
+	file, err := t.files.Open(path.Join(dir, ts.Image.Source))
+	if err != nil {
+		return fmt.Errorf("trees: reading tileset image: %v", err)
+	}
+	defer file.Close()
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return fmt.Errorf("trees: reading tileset image %s: %v", ts.Image.Source, err)
+	}
+	t.sets = append(t.sets, ts)
+	t.pictures = append(t.pictures, pixel.PictureDataFromImage(img))
+	return nil
+}
+
+// sprite is the sprite of a tile by its global ID, without the flip bits
+func (t *tmxTiles) sprite(gid uint32) (*pixel.Sprite, error) {
+	if s, ok := t.sprites[gid]; ok {
+		return s, nil
+	}
+	set := -1
+	for i, ts := range t.sets {
+		if ts.FirstGID <= gid && (set < 0 || ts.FirstGID > t.sets[set].FirstGID) {
+			set = i
+		}
+	}
+	if set < 0 {
+		return nil, fmt.Errorf("trees: tile %d isn't in any tileset", gid)
+	}
+	ts, pic := t.sets[set], t.pictures[set]
+	id := int(gid - ts.FirstGID)
+	columns := ts.Columns
+	if columns <= 0 {
+		columns = (int(pic.Bounds().W()) - 2*ts.Margin + ts.Spacing) / (ts.TileWidth + ts.Spacing)
+	}
+	if columns <= 0 || (ts.TileCount > 0 && id >= ts.TileCount) {
+		return nil, fmt.Errorf("trees: tile %d isn't in its tileset", gid)
+	}
+	col, row := id%columns, id/columns
+	x := float64(ts.Margin + col*(ts.TileWidth+ts.Spacing))
+	top := pic.Bounds().Max.Y - float64(ts.Margin+row*(ts.TileHeight+ts.Spacing))
+	frame := pixel.R(x, top-float64(ts.TileHeight), x+float64(ts.TileWidth), top)
+	if !pic.Bounds().Contains(frame.Min) || !pic.Bounds().Contains(frame.Max) {
+		return nil, fmt.Errorf("trees: tile %d is off the edge of its tileset image", gid)
+	}
+	s := pixel.NewSprite(pic, frame)
+	t.sprites[gid] = s
+	return s, nil
+}
+
+// flipped turns a tile's flip bits into a matrix that flips it about its
+// middle
+func flipped(gid uint32) pixel.Matrix {
+	m := pixel.IM
+	if gid&tmxFlippedDiagonal != 0 {
+		m = pixel.Matrix{0, -1, -1, 0, 0, 0}
+	}
+	if gid&tmxFlippedAcross != 0 {
+		m = m.ScaledXY(pixel.ZV, pixel.V(-1, 1))
+	}
+	if gid&tmxFlippedDown != 0 {
+		m = m.ScaledXY(pixel.ZV, pixel.V(1, -1))
+	}
+	return m
+}
+
+// decor places every tile of the map's visible tile layers, then its tile
+// objects
+func (t *tmxTiles) decor(m tmxMap, origin pixel.Vec) ([]Decoration, error) {
+	var decor []Decoration
+	world := func(p pixel.Vec) pixel.Vec { return pixel.V(p.X-origin.X, origin.Y-p.Y) }
+	for _, layer := range m.Layers {
+		if layer.Visible != nil && *layer.Visible == 0 {
+			continue
+		}
+		gids, err := layer.Data.tiles(layer.Width * layer.Height)
+		if err != nil {
+			return nil, fmt.Errorf("trees: layer %q: %v", layer.Name, err)
+		}
+		for i, gid := range gids {
+			if gid&^tmxFlipBits == 0 {
+				continue
+			}
+			s, err := t.sprite(gid &^ tmxFlipBits)
+			if err != nil {
+				return nil, err
+			}
+			// Tiles bigger than the map's sit on the bottom left of their cell
+			col, row := i%layer.Width, i/layer.Width
+			size := s.Frame().Size()
+			centre := pixel.V(float64(col*m.TileWidth)+size.X/2, float64((row+1)*m.TileHeight)-size.Y/2)
+			centre = centre.Add(pixel.V(layer.OffsetX, layer.OffsetY))
+			decor = append(decor, Decoration{Sprite: s, Matrix: flipped(gid).Moved(world(centre))})
+		}
+	}
+	for _, g := range m.Groups {
+		for _, o := range g.Objects {
+			if o.GID&^tmxFlipBits == 0 {
+				continue
+			}
+			s, err := t.sprite(o.GID &^ tmxFlipBits)
+			if err != nil {
+				return nil, err
+			}
+			// Tile objects hang from their bottom left, and turn about it
+			size := s.Frame().Size()
+			turn := o.Rotation * math.Pi / 180
+			centre := pixel.V(o.X+g.OffsetX, o.Y+g.OffsetY).Add(pixel.V(o.Width/2, -o.Height/2).Rotated(turn))
+			matrix := flipped(o.GID).ScaledXY(pixel.ZV, pixel.V(o.Width/size.X, o.Height/size.Y)).Rotated(pixel.ZV, -turn)
+			decor = append(decor, Decoration{Sprite: s, Matrix: matrix.Moved(world(centre))})
+		}
+	}
+	return decor, nil
+}
+
+// tiles decodes the global IDs of a tile layer's tiles, row by row from the
+// top left
+func (d tmxData) tiles(count int) ([]uint32, error) {
+	if d.Encoding == "csv" {
+		var gids []uint32
+		for _, field := range strings.Split(d.Text, ",") {
+			gid, err := strconv.ParseUint(strings.TrimSpace(field), 10, 32)
+			if err != nil {
+				return nil, err
+			}
+			gids = append(gids, uint32(gid))
+		}
+		if len(gids) != count {
+			return nil, fmt.Errorf("%d tiles, not %d", len(gids), count)
+		}
+		return gids, nil
+	}
+	if d.Encoding != "base64" {
+		return nil, fmt.Errorf("tiles encoded as %q aren't supported, only csv or base64", d.Encoding)
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(d.Text))
+	if err != nil {
+		return nil, err
+	}
+	var in io.Reader = bytes.NewReader(raw)
+	switch d.Compression {
+	case "":
+	case "zlib":
+		if in, err = zlib.NewReader(in); err != nil {
+			return nil, err
+		}
+	case "gzip":
+		if in, err = gzip.NewReader(in); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("tiles compressed with %s aren't supported", d.Compression)
+	}
+	raw, err = ioutil.ReadAll(in)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) != 4*count {
+		return nil, fmt.Errorf("%d tiles, not %d", len(raw)/4, count)
+	}
+	gids := make([]uint32, count)
+	for i := range gids {
+		gids[i] = binary.LittleEndian.Uint32(raw[4*i:])
+	}
+	return gids, nil
+}
@@ -0,0 +1,354 @@
+package trees
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/png"
+	"math"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/ByteArena/box2d"
+	"github.com/faiface/pixel"
+)
+
+const epsilon = 1e-9
+
+func near(a, b pixel.Vec) bool {
+	return math.Abs(a.X-b.X) < epsilon && math.Abs(a.Y-b.Y) < epsilon
+}
+
+func nearRect(a, b pixel.Rect) bool {
+	return near(a.Min, b.Min) && near(a.Max, b.Max)
+}
+
+func nearMatrix(a, b pixel.Matrix) bool {
+	for i := range a {
+		if math.Abs(a[i]-b[i]) > epsilon {
+			return false
+		}
+	}
+	return true
+}
+
+// tmx is a map four tiles across and two down with layers, objects and
+// tilesets of its own, whose tileset image is tiles.png in tmxFiles
+func tmx(body string) string {
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<map orientation="orthogonal" infinite="0" width="4" height="2" tilewidth="16" tileheight="16">
+ <tileset firstgid="1" tilewidth="16" tileheight="16" tilecount="2" columns="2">
+  <image source="tiles.png" width="32" height="16"/>
+ </tileset>
+` + body + `
+</map>`
+}
+
+// tmxFiles holds a tileset image of two tiles side by side
+func tmxFiles(t *testing.T) fstest.MapFS {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 32, 16))); err != nil {
+		t.Fatal(err)
+	}
+	return fstest.MapFS{"tiles.png": {Data: buf.Bytes()}}
+}
+
+// zlibTiles encodes global IDs the way Tiled does for base64 and zlib
+func zlibTiles(t *testing.T, gids ...uint32) string {
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	if err := binary.Write(w, binary.LittleEndian, gids); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+func TestReadTMX(t *testing.T) {
+	// The tiles in the top left two cells, as decor
+	first := pixel.V(-24, 24)
+	second := pixel.V(-8, 24)
+	tile1, tile2 := pixel.R(0, 0, 16, 16), pixel.R(16, 0, 32, 16)
+	type tile struct {
+		frame  pixel.Rect
+		matrix pixel.Matrix
+	}
+	tests := []struct {
+		name  string
+		body  string
+		decor []tile
+		err   string
+	}{
+		{
+			"csv",
+			`<layer name="tiles" width="4" height="2"><data encoding="csv">1,2,0,0,
+0,0,0,0</data></layer>`,
+			[]tile{{tile1, pixel.IM.Moved(first)}, {tile2, pixel.IM.Moved(second)}},
+			"",
+		},
+		{
+			"base64 and zlib",
+			`<layer name="tiles" width="4" height="2"><data encoding="base64" compression="zlib">` +
+				zlibTiles(t, 1, 2, 0, 0, 0, 0, 0, 0) + `</data></layer>`,
+			[]tile{{tile1, pixel.IM.Moved(first)}, {tile2, pixel.IM.Moved(second)}},
+			"",
+		},
+		{
+			"flipped across and down",
+			fmt.Sprintf(`<layer name="tiles" width="4" height="2"><data encoding="csv">%d,%d,0,0,0,0,0,0</data></layer>`,
+				tmxFlippedAcross|1, tmxFlippedDown|2),
+			[]tile{{tile1, pixel.Matrix{-1, 0, 0, 1, 0, 0}.Moved(first)}, {tile2, pixel.Matrix{1, 0, 0, -1, 0, 0}.Moved(second)}},
+			"",
+		},
+		{
+			"flipped diagonally",
+			fmt.Sprintf(`<layer name="tiles" width="4" height="2"><data encoding="base64" compression="zlib">%s</data></layer>`,
+				zlibTiles(t, tmxFlippedDiagonal|1, tmxFlippedDiagonal|tmxFlippedAcross|2, 0, 0, 0, 0, 0, 0)),
+			[]tile{{tile1, pixel.Matrix{0, -1, -1, 0, 0, 0}.Moved(first)}, {tile2, pixel.Matrix{0, -1, 1, 0, 0, 0}.Moved(second)}},
+			"",
+		},
+		{
+			"hidden layer",
+			`<layer name="tiles" width="4" height="2" visible="0"><data encoding="csv">1,2,0,0,0,0,0,0</data></layer>`,
+			nil,
+			"",
+		},
+		{
+			"too few tiles",
+			`<layer name="tiles" width="4" height="2"><data encoding="csv">1,2,0,0</data></layer>`,
+			nil,
+			`layer "tiles": 4 tiles, not 8`,
+		},
+		{
+			"tile outside the tileset",
+			`<layer name="tiles" width="4" height="2"><data encoding="csv">3,0,0,0,0,0,0,0</data></layer>`,
+			nil,
+			"tile 3 isn't in its tileset",
+		},
+		{
+			"sliver of a polygon",
+			`<objectgroup><object name="sliver" x="0" y="0"><polygon points="0,0 32,0 16,0.0001"/></object></objectgroup>`,
+			nil,
+			`object "sliver": polygon has a piece too small or thin to collide with`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			l, err := ReadTMX(strings.NewReader(tmx(test.body)), tmxFiles(t), "test")
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("error is %v, want %q", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(l.Decor) != len(test.decor) {
+				t.Fatalf("%d decorations, want %d", len(l.Decor), len(test.decor))
+			}
+			for i, d := range l.Decor {
+				if got := d.Sprite.Frame(); got != test.decor[i].frame {
+					t.Errorf("decoration %d is the tile at %v, want %v", i, got, test.decor[i].frame)
+				}
+				if !nearMatrix(d.Matrix, test.decor[i].matrix) {
+					t.Errorf("decoration %d has matrix %v, want %v", i, d.Matrix, test.decor[i].matrix)
+				}
+			}
+		})
+	}
+}
+
+func TestAddObject(t *testing.T) {
+	// The middle of the bottom of a map 64 pixels across and 32 down
+	origin := pixel.V(32, 32)
+	rect := pixel.R(-1, 0.5, 0, 1)
+	tests := []struct {
+		name   string
+		object tmxObject
+		want   LevelFile
+	}{
+		{"box", tmxObject{Width: 32, Height: 16}, LevelFile{Boxes: []pixel.Rect{rect}}},
+		{"drop", tmxObject{Class: "drop", Width: 32, Height: 16}, LevelFile{Drop: rect}},
+		{"bin", tmxObject{Class: "bin", Width: 32, Height: 16}, LevelFile{Bins: []pixel.Rect{rect}}},
+		{"bin as a type", tmxObject{Type: "bin", Width: 32, Height: 16}, LevelFile{Bins: []pixel.Rect{rect}}},
+		{
+			"rotated drop",
+			tmxObject{Class: "drop", X: 32, Width: 32, Height: 32, Rotation: 90},
+			LevelFile{Drop: pixel.R(-1, 0, 0, 1)},
+		},
+		{
+			"rotated rectangle",
+			tmxObject{X: 32, Width: 32, Height: 32, Rotation: 90},
+			LevelFile{Polygons: [][]pixel.Vec{{pixel.V(0, 1), pixel.V(0, 0), pixel.V(-1, 0), pixel.V(-1, 1)}}},
+		},
+		{
+			"ellipse",
+			tmxObject{X: 32, Width: 32, Height: 32, Ellipse: &struct{}{}},
+			LevelFile{Pegs: []Peg{{Centre: pixel.V(0.5, 0.5), Radius: 0.5}}},
+		},
+		{
+			"polyline",
+			tmxObject{X: 32, Y: 32, Polyline: &tmxPoints{"0,0 32,-32"}},
+			LevelFile{Chains: [][]pixel.Vec{{pixel.V(0, 0), pixel.V(1, 1)}}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var f LevelFile
+			if err := f.addObject(tmxGroup{}, test.object, origin); err != nil {
+				t.Fatal(err)
+			}
+			if !nearRect(f.Drop, test.want.Drop) {
+				t.Errorf("drop is %v, want %v", f.Drop, test.want.Drop)
+			}
+			rects := func(what string, got, want []pixel.Rect) {
+				if len(got) != len(want) {
+					t.Fatalf("%s are %v, want %v", what, got, want)
+				}
+				for i := range got {
+					if !nearRect(got[i], want[i]) {
+						t.Errorf("%s are %v, want %v", what, got, want)
+					}
+				}
+			}
+			rects("boxes", f.Boxes, test.want.Boxes)
+			rects("bins", f.Bins, test.want.Bins)
+			lines := func(what string, got, want [][]pixel.Vec) {
+				if len(got) != len(want) {
+					t.Fatalf("%s are %v, want %v", what, got, want)
+				}
+				for i := range got {
+					if len(got[i]) != len(want[i]) {
+						t.Fatalf("%s are %v, want %v", what, got, want)
+					}
+					for j := range got[i] {
+						if !near(got[i][j], want[i][j]) {
+							t.Errorf("%s are %v, want %v", what, got, want)
+						}
+					}
+				}
+			}
+			lines("polygons", f.Polygons, test.want.Polygons)
+			lines("chains", f.Chains, test.want.Chains)
+			if len(f.Pegs) != len(test.want.Pegs) {
+				t.Fatalf("pegs are %v, want %v", f.Pegs, test.want.Pegs)
+			}
+			for i, p := range f.Pegs {
+				if want := test.want.Pegs[i]; !near(p.Centre, want.Centre) || math.Abs(p.Radius-want.Radius) > epsilon {
+					t.Errorf("pegs are %v, want %v", f.Pegs, test.want.Pegs)
+				}
+			}
+		})
+	}
+}
+
+// area is the area of a polygon, positive if it's anticlockwise
+func area(points []pixel.Vec) float64 {
+	a := 0.0
+	for i, p := range points {
+		a += p.Cross(points[(i+1)%len(points)]) / 2
+	}
+	return a
+}
+
+func TestConvexPieces(t *testing.T) {
+	tests := []struct {
+		name   string
+		points []pixel.Vec
+		pieces int // Or 0 for however many it takes
+		err    string
+	}{
+		{"triangle", []pixel.Vec{pixel.V(0, 0), pixel.V(1, 0), pixel.V(0, 1)}, 1, ""},
+		{"square", []pixel.Vec{pixel.V(0, 0), pixel.V(1, 0), pixel.V(1, 1), pixel.V(0, 1)}, 1, ""},
+		{"clockwise square", []pixel.Vec{pixel.V(0, 0), pixel.V(0, 1), pixel.V(1, 1), pixel.V(1, 0)}, 1, ""},
+		{"square with a point on an edge", []pixel.Vec{pixel.V(0, 0), pixel.V(0.5, 0), pixel.V(1, 0), pixel.V(1, 1), pixel.V(0, 1)}, 1, ""},
+		{
+			"concave",
+			[]pixel.Vec{pixel.V(0, 0), pixel.V(2, 0), pixel.V(2, 1), pixel.V(1, 1), pixel.V(1, 2), pixel.V(0, 2)},
+			0,
+			"",
+		},
+		{
+			"concave clockwise",
+			[]pixel.Vec{pixel.V(0, 0), pixel.V(0, 2), pixel.V(1, 2), pixel.V(1, 1), pixel.V(2, 1), pixel.V(2, 0)},
+			0,
+			"",
+		},
+		{"too many points", circle(12), 0, ""},
+		{"two points", []pixel.Vec{pixel.V(0, 0), pixel.V(1, 0)}, 0, "polygon with 2 points"},
+		{"line", []pixel.Vec{pixel.V(0, 0), pixel.V(1, 0), pixel.V(2, 0)}, 0, "polygon with no area"},
+		{
+			"crosses itself",
+			[]pixel.Vec{pixel.V(0, 0), pixel.V(2, 2), pixel.V(2, 0), pixel.V(0, 1)},
+			0,
+			"polygon crosses itself",
+		},
+		{
+			"sliver",
+			[]pixel.Vec{pixel.V(0, 0), pixel.V(1, 0), pixel.V(0.5, 0.00001)},
+			0,
+			"polygon has a piece too small or thin to collide with",
+		},
+		{
+			"points on top of each other",
+			[]pixel.Vec{pixel.V(0, 0), pixel.V(1, 0), pixel.V(1, 1), pixel.V(0.5005, 1.001), pixel.V(0.4995, 1.001), pixel.V(0, 1)},
+			0,
+			"polygon has a piece too small or thin to collide with",
+		},
+		{
+			"concave with a sliver of an ear",
+			[]pixel.Vec{pixel.V(0, 0), pixel.V(2, 0), pixel.V(2, 1), pixel.V(1, 0.00001), pixel.V(0, 1)},
+			0,
+			"polygon has a piece too small or thin to collide with",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pieces, err := convexPieces(test.points)
+			if test.err != "" {
+				if err == nil || err.Error() != test.err {
+					t.Fatalf("error is %v, want %q", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.pieces > 0 && len(pieces) != test.pieces {
+				t.Errorf("%d pieces, want %d", len(pieces), test.pieces)
+			}
+			total := 0.0
+			for _, p := range pieces {
+				if len(p) < 3 || len(p) > box2d.B2_maxPolygonVertices {
+					t.Errorf("piece %v has %d points", p, len(p))
+				}
+				for i := range p {
+					n := len(p)
+					if a, b, c := p[(i+n-1)%n], p[i], p[(i+1)%n]; b.Sub(a).Cross(c.Sub(b)) <= 0 {
+						t.Errorf("piece %v doesn't turn left at %v", p, b)
+					}
+				}
+				total += area(p)
+			}
+			if want := math.Abs(area(test.points)); math.Abs(total-want) > epsilon {
+				t.Errorf("pieces cover %v, want %v", total, want)
+			}
+		})
+	}
+}
+
+// circle is a convex polygon of n points around a circle
+func circle(n int) []pixel.Vec {
+	points := make([]pixel.Vec, n)
+	for i := range points {
+		points[i] = pixel.V(1, 0).Rotated(2 * math.Pi * float64(i) / float64(n))
+	}
+	return points
+}
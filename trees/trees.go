@@ -0,0 +1,553 @@
+// Package trees is a toy physics world of round trees tumbling onto a small
+// mountain. Box2D runs the simulation and Pixel draws it.
+//
+// A World can be stepped on its own, say in a test or a headless experiment,
+// or rendered onto any Pixel target such as a window or canvas:
+//
+//	world := trees.NewWorld(trees.DefaultOptions())
+//	for !win.Closed() {
+//		world.Step(1.0 / 60)
+//		win.Clear(colornames.Whitesmoke)
+//		world.Render(win)
+//		win.Update()
+//	}
+//
+// Other packages can add their own mouse tools and spawnable objects by
+// implementing Tool or EntityType and registering them from an init function.
+package trees
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/ByteArena/box2d"
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"golang.org/x/image/colornames"
+)
+
+// Trees are drawn with 32 pixels to the metre
+const PixelsPerMetre = 32
+
+// Every tree is a circle with this radius in metres
+const TreeRadius = 1
+
+// Bounds is the playable area in metres. Trees outside it have fallen off the world.
+var Bounds = pixel.R(-120, -80, 120, 120)
+
+// Options control how a World is set up
+type Options struct {
+	// Gravity in metres per second squared
+	Gravity pixel.Vec
+
+	// Name of the registered level whose terrain the world is built on
+	Level string
+
+	// Number of trees scattered above the terrain when the world is created
+	Trees int
+
+	// Source of randomness for scattering trees, or nil for the global source
+	Rand *rand.Rand
+
+	// Sprite drawn for every tree, scaled to cover its body. If nil the
+	// trees are drawn as plain circles.
+	Sprite *pixel.Sprite
+
+	// Point on the sprite placed over the centre of mass of each tree, in
+	// sprite pixels from the centre of its frame. Zero centres the sprite.
+	SpritePivot pixel.Vec
+
+	// Sprites for a forest of different trees, each tree drawn with one of
+	// them picked at random as it's spawned. They must all be cut from the
+	// same picture, since trees are drawn in one batch. If empty every tree
+	// is drawn with Sprite.
+	Sprites []TreeSprite
+
+	// Bus to publish events on. If nil the World creates its own, but
+	// passing one in allows subscribing before the World is built.
+	Events *Bus
+
+	// How hard the physics works on each step. It can be changed later with
+	// SetSolver.
+	Solver SolverProfile
+
+	// Number of straight pieces each span of a smooth curve is cut into, for
+	// both drawing and physics
+	Subdivisions int
+
+	// Collisions softer than this impulse in newton-seconds aren't published
+	MinImpulse float64
+
+	// Seconds of simulated time before another collision between the same two
+	// bodies is published. Zero publishes every one.
+	CollisionCooldown float64
+
+	// Collisions with the terrain at least this hard, in newton-seconds, leave
+	// a dent in it. Zero leaves none.
+	DecalImpulse float64
+
+	// Seconds of simulated time before a dent or scorch mark fades away
+	DecalLifetime float64
+
+	// How much darker a tree buried deep in the pile is drawn than one out in
+	// the open, from 0 to 1. Zero draws every tree the same.
+	Occlusion float64
+
+	// Seconds of simulated time in each step taken by Advance. Zero makes
+	// Advance a single step of whatever time it's given.
+	FixedStep float64
+
+	// Longest stretch of time in seconds a single call to Advance moves the
+	// simulation on by. Anything more is dropped and a Hitch is published, so
+	// a frame held up by dragging the window or a garbage collection doesn't
+	// hand the physics one enormous step, or hundreds of small ones. Zero
+	// lets Advance take any amount of time.
+	MaxAdvance float64
+
+	// Makes gravity ebb and flow, if its Period isn't zero
+	Tide Tide
+
+	// Lays soil over the terrain that sinks under piles, if its Depth isn't
+	// zero
+	SoftGround SoftGround
+
+	// Seconds of snowfall it takes to cap a tree that's been resting with
+	// nothing above it, or 0 for no snow
+	Snowfall float64
+
+	// Seconds a tree that's landed with open sky above it goes between
+	// dropping seeds on average, or 0 for none. Seeds that come to rest on
+	// the soil grow into trees like the one they fell from.
+	Seeding float64
+
+	// Height in metres below which trees and entities have fallen off the
+	// world and are destroyed, or 0 for the bottom of Bounds
+	KillPlane float64
+
+	// Seconds of simulated time trees and entities of each type, by name,
+	// last before they despawn. Types left out stay forever.
+	Lifetimes map[string]float64
+
+	// Seconds bodies spend fading away at the end of their lifetime
+	LifetimeFade float64
+
+	// Fastest trees and entities of each type, by name, move in metres per
+	// second. Types left out go as fast as the physics takes them.
+	MaxSpeeds map[string]float64
+
+	// Linear damping of every tree, which slows them a little as they fall
+	TreeDamping float64
+
+	// How bouncy trees are, from 0 for not at all to 1 for a perfect bounce
+	TreeRestitution float64
+}
+
+// DefaultOptions are the settings used by the falling trees demo
+func DefaultOptions() Options {
+	return Options{
+		Gravity:           pixel.V(0, -10),
+		Level:             mountain.Name,
+		Trees:             800,
+		Solver:            SolverBalanced,
+		Subdivisions:      8,
+		MinImpulse:        1,
+		CollisionCooldown: 0.25,
+		DecalImpulse:      40,
+		DecalLifetime:     60,
+		Occlusion:         0.4,
+		FixedStep:         1.0 / 60,
+		MaxAdvance:        0.25,
+		TreeDamping:       0.02,
+		TreeRestitution:   0.4,
+		LifetimeFade:      2,
+	}
+}
+
+// World holds the physics simulation and everything needed to draw it
+type World struct {
+	opts     Options
+	level    Level
+	physics  *box2d.B2World
+	anchor   *box2d.B2Body // The static ground, which jointed prefabs are pinned to
+	ground   *imdraw.IMDraw
+	circles  *imdraw.IMDraw
+	batch    *pixel.Batch // Every tree sprite, drawn in one go
+	shapes   *imdraw.IMDraw
+	decor    []*pixel.Batch // The level's Decor, a batch for each run of it from the same picture
+	trees    []*box2d.B2Body
+	entities []*Entity
+	registry *registry
+	doomed   destroyQueue
+	events   *Bus
+	contacts *contacts
+	filter   *collisionFilter
+	fields   []*SlowField
+	guides   []*guide
+	ridges   []*ridge
+	lastStep float64 // Seconds simulated by the last step
+	stamps   []*stamped
+	soil     *soil   // Only with Options.SoftGround
+	seeds    []*seed // Only with Options.Seeding
+	pool     treePool
+	ordered  []*box2d.B2Body // Reused by bodies
+	view     pixel.Rect      // Metres Render draws trees in, or everywhere if empty
+	drawn    int             // Trees Render drew last time
+	decals   []decal
+	landings []Landing
+	topples  []Topple
+	toppled  map[*Entity]bool
+	elapsed  float64 // Seconds simulated since the World was created
+	fallen   int     // Trees and entities destroyed below the kill plane
+	snowed   float64 // Seconds since trees were last looked at for snow
+	leftover float64 // Seconds given to Advance that don't yet make up a step
+	blend    float64 // How far trees are drawn from their previous step to their latest, from 0 to 1
+	grab     *grab
+	woken    pixel.Vec // Gravity when the tide last woke everything
+	wave     int       // What's spawned now belongs to this wave
+
+	showLayers bool
+	showWaves  bool
+	occupancy  *Occupancy // Rasterized at the end of every step, if set
+}
+
+// NewWorld builds the terrain of the level and scatters the requested number
+// of trees above it. It panics if the level isn't registered.
+func NewWorld(opts Options) *World {
+	level, ok := LookupLevel(opts.Level)
+	if !ok {
+		panic(fmt.Errorf("trees: unknown level %q", opts.Level))
+	}
+	w := &World{
+		opts:     opts,
+		level:    level,
+		circles:  imdraw.New(nil),
+		shapes:   imdraw.New(nil),
+		events:   opts.Events,
+		contacts: &contacts{fresh: map[box2d.B2ContactInterface]bool{}},
+		registry: newRegistry(),
+		doomed:   destroyQueue{flagged: map[*box2d.B2Body]bool{}},
+		toppled:  map[*Entity]bool{},
+		blend:    1,
+		woken:    opts.Gravity,
+		wave:     1,
+		filter: &collisionFilter{
+			minImpulse: opts.MinImpulse,
+			cooldown:   opts.CollisionCooldown,
+			last:       map[bodyPair]float64{},
+		},
+	}
+	if w.events == nil {
+		w.events = NewBus()
+	}
+	w.physics, w.anchor, w.ground = createGround(opts.Gravity, level)
+	w.physics.SetContactListener(w.contacts)
+	w.physics.SetContactFilter(layerFilter{})
+	w.SetSolver(opts.Solver)
+	for _, f := range level.SlowFields {
+		w.AddSlowField(f)
+	}
+	if level.Setup != nil {
+		level.Setup(w)
+	}
+	w.laySoil()
+	w.events.Publish(LevelLoaded{Name: level.Name})
+	for i := 0; i < opts.Trees; i++ {
+		w.ScatterTree()
+	}
+	return w
+}
+
+func createGround(gravity pixel.Vec, level Level) (*box2d.B2World, *box2d.B2Body, *imdraw.IMDraw) {
+	// Construct a world object, which will hold and simulate the rigid bodies.
+	world := box2d.MakeB2World(box2d.MakeB2Vec2(gravity.X, gravity.Y))
+
+	// Create the ground in the physics model, drawing it directly as it goes
+	groundBodyDef := box2d.MakeB2BodyDef()
+	groundBodyDef.Position.Set(0, 0)
+	groundBody := world.CreateBody(&groundBodyDef)
+	imd := imdraw.New(nil)
+	level.Build(groundBody, imd)
+
+	// Outline the world bounds so it's clear how far out there is anything to see
+	imd.Color = colornames.Lightgray
+	imd.Push(
+		Bounds.Min.Scaled(PixelsPerMetre),
+		Bounds.Max.Scaled(PixelsPerMetre),
+	)
+	imd.Rectangle(8)
+
+	return &world, groundBody, imd
+}
+
+// SpawnTree adds a tree centred on a position given in metres, reusing one
+// that was removed if any are pooled
+func (w *World) SpawnTree(pos pixel.Vec) *box2d.B2Body {
+	e := w.unpark(pos)
+	if e == nil {
+		e = &Entity{Body: w.buildTree(pos)}
+	}
+	body := e.Body
+	setLayers(body, treeLayers)
+	w.trees = append(w.trees, body)
+	w.registry.adopt(e, treeType{})
+	e.Wave, e.born = w.wave, w.elapsed
+	// Each tree picks a sprite and starts somewhere in its animation
+	e.sprite = pickSprite(e.ID)
+	e.played = float64(pickSprite(^e.ID)%1000) / 100
+	w.events.Publish(BodySpawned{Body: body, Type: treeType{}})
+	return body
+}
+
+// buildTree creates the body of a tree
+func (w *World) buildTree(pos pixel.Vec) *box2d.B2Body {
+	bodyDef := box2d.MakeB2BodyDef()
+	bodyDef.Type = box2d.B2BodyType.B2_dynamicBody
+	bodyDef.Position.Set(pos.X, pos.Y)
+	bodyDef.LinearDamping = w.opts.TreeDamping
+	body := w.physics.CreateBody(&bodyDef)
+	dynamicBox := box2d.MakeB2CircleShape()
+	dynamicBox.SetRadius(TreeRadius)
+	fixtureDef := box2d.MakeB2FixtureDef()
+	fixtureDef.Shape = &dynamicBox
+	fixtureDef.Density = 1
+	fixtureDef.Friction = 1
+	fixtureDef.Restitution = w.opts.TreeRestitution
+	body.CreateFixtureFromDef(&fixtureDef)
+	return body
+}
+
+// ScatterTree adds a tree at a random spot in the level's drop area
+func (w *World) ScatterTree() *box2d.B2Body {
+	drop := w.level.Drop
+	x := drop.Min.X + w.random()*drop.W()
+	y := drop.Min.Y + w.random()*drop.H()
+	return w.SpawnTree(pixel.V(x, y))
+}
+
+// RemoveTree takes a tree out of the world. If the world is mid-step, such as
+// inside a contact callback, removal waits until the step is over.
+func (w *World) RemoveTree(tree *box2d.B2Body) {
+	if w.physics.IsLocked() {
+		w.Destroy(tree)
+		return
+	}
+	for i, t := range w.trees {
+		if t == tree {
+			w.events.Publish(BodyDestroyed{Body: tree})
+			w.trees = append(w.trees[:i], w.trees[i+1:]...)
+			e, _ := w.Lookup(tree)
+			w.registry.remove(tree)
+			w.park(e)
+			return
+		}
+	}
+}
+
+// RemoveEntity takes something spawned through Spawn out of the world. Like
+// RemoveTree, it waits until the end of the step if the world is mid-step.
+func (w *World) RemoveEntity(body *box2d.B2Body) {
+	if w.physics.IsLocked() {
+		w.Destroy(body)
+		return
+	}
+	for i, e := range w.entities {
+		if e.Body == body {
+			w.events.Publish(BodyDestroyed{Body: body})
+			w.entities = append(w.entities[:i], w.entities[i+1:]...)
+			w.registry.remove(body)
+			w.physics.DestroyBody(body)
+			return
+		}
+	}
+}
+
+// Trees lists every tree in the order they were spawned. The slice belongs to
+// the World and must not be modified.
+func (w *World) Trees() []*box2d.B2Body {
+	return w.trees
+}
+
+// Events is the bus the World publishes on
+func (w *World) Events() *Bus {
+	return w.events
+}
+
+// Physics gives direct access to the underlying Box2D world
+func (w *World) Physics() *box2d.B2World {
+	return w.physics
+}
+
+// SetGravity changes gravity, in metres per second squared, waking every
+// body so that resting piles feel the change. Any tide ebbs and flows about
+// the new gravity.
+func (w *World) SetGravity(gravity pixel.Vec) {
+	w.opts.Gravity = gravity
+	w.applyTide(true)
+	w.events.Publish(GravityChanged{Gravity: gravity, Tide: w.opts.Tide})
+}
+
+// SetSprite swaps the sprite every tree is drawn with, which may be cut from
+// a different picture, from the next time the world is rendered
+func (w *World) SetSprite(sprite *pixel.Sprite, pivot pixel.Vec) {
+	w.opts.Sprite, w.opts.SpritePivot = sprite, pivot
+	w.batch = nil
+}
+
+// SetSprites swaps the sprites trees are picked from, from the next time the
+// world is rendered. Each tree keeps to its place in the list, so a new
+// spritesheet cut up the same way redraws every tree as the same kind.
+func (w *World) SetSprites(sprites []TreeSprite) {
+	w.opts.Sprites = sprites
+	w.batch = nil
+}
+
+// Step advances the simulation by dt seconds, publishes any collisions that
+// happened along the way and then removes everything flagged with Destroy or
+// past its lifetime.
+// Collisions that are too soft or too soon after the last one between the
+// same bodies are dropped according to MinImpulse and CollisionCooldown.
+func (w *World) Step(dt float64) {
+	w.blend = 1
+	if w.opts.Tide.Period > 0 {
+		w.applyTide(false)
+	}
+	w.slowDown(dt)
+	w.moveGuided(dt)
+	w.clampSpeeds()
+	w.contacts.watched = w.contacts.watched[:0]
+	w.lastStep = dt
+	w.stepPhysics(dt)
+	w.settleSoil(dt)
+	w.elapsed += dt
+	w.recordLandings(dt)
+	w.snowfall(dt)
+	w.seeding(dt)
+	w.growSaplings(dt)
+	w.recordTopples()
+	w.filter.advance(dt)
+	w.ageDecals(dt)
+	w.expire()
+	w.cull()
+	pending := w.contacts.pending
+	w.contacts.pending = nil
+	for _, e := range pending {
+		if c, ok := e.(Collision); ok {
+			if !w.filter.allow(c) {
+				continue
+			}
+			c.EntityA, _ = w.Lookup(c.A)
+			c.EntityB, _ = w.Lookup(c.B)
+			w.dent(c)
+			e = c
+		}
+		w.events.Publish(e)
+	}
+	w.flushDestroyed()
+	if w.occupancy != nil {
+		w.Rasterize(w.occupancy)
+	}
+	w.events.Publish(Stepped{Dt: dt})
+}
+
+// Render draws the ground and trees onto a target using its current matrix, or
+// every body tinted by its collision layer while ShowLayers is on. Tree
+// sprites are gathered into a batch and drawn together, so thousands of trees
+// cost one draw call, and trees outside the view set with SetView are left
+// out of it.
+func (w *World) Render(t pixel.Target) {
+	if w.showLayers {
+		w.renderLayers(t)
+		return
+	}
+	w.ground.Draw(t)
+	w.drawDecor(t)
+	w.shapes.Clear()
+	w.drawRidges(w.shapes)
+	w.drawStamps(w.shapes)
+	w.drawSoil(w.shapes)
+	w.drawDecals(w.shapes)
+	w.drawSeeds(w.shapes)
+	w.shapes.Draw(t)
+	drawn := w.spritePicture()
+	if drawn == nil {
+		w.circles.Clear()
+	} else {
+		if w.batch == nil {
+			w.batch = pixel.NewBatch(&pixel.TrianglesData{}, drawn)
+		}
+		w.batch.Clear()
+	}
+	w.drawn = 0
+	for _, tree := range w.trees {
+
+		// Physics X and Y of the centre of mass which are in metres, part of
+		// the way between the last two fixed steps
+		at, size := pixel.V(tree.GetWorldCenter().X, tree.GetWorldCenter().Y), 1.0
+		if e, ok := w.Lookup(tree); ok {
+			at, size = w.drawnAt(e), treeScale(e)
+		}
+
+		// Skip trees no part of which would land in the view
+		reach := size * TreeRadius
+		var sprite *pixel.Sprite
+		var pivot pixel.Vec
+		if drawn != nil {
+			sprite, pivot = w.treeSprite(tree)
+			reach = spriteReach(sprite, pivot, size)
+		}
+		if !w.inView(at, reach) {
+			continue
+		}
+		w.drawn++
+
+		// Determine the position on screen by scaling so that we get 32 pixels to the metre
+		pos := at.Scaled(PixelsPerMetre)
+
+		// Draw a tree sprite for this physics body, or a plain circle if there's
+		// no sprite, tinted if the tree is frozen and darkened the deeper it's
+		// buried in the pile. Trees grown from seed have a tint of their own,
+		// and while ShowWaves is on every tree is tinted by its wave instead.
+		tint := pixel.RGB(1, 1, 1)
+		if e, ok := w.Lookup(tree); ok {
+			tint = treeTint(e)
+			if w.showWaves {
+				tint = WaveColour(e.Wave)
+			}
+		}
+		if tree.GetType() == box2d.B2BodyType.B2_staticBody {
+			tint = pixel.ToRGBA(frozenTint)
+		}
+		shade := w.shade(tree)
+		tint = pixel.RGBA{R: tint.R * shade, G: tint.G * shade, B: tint.B * shade, A: tint.A}
+		tint = tint.Mul(pixel.Alpha(w.fade(tree)))
+		if drawn == nil {
+			w.circles.Color = pixel.ToRGBA(colornames.Forestgreen).Mul(tint)
+			if w.showWaves {
+				w.circles.Color = tint
+			}
+			w.circles.Push(pos)
+			w.circles.Circle(size*TreeRadius*PixelsPerMetre, 0)
+			continue
+		}
+		sprite.DrawColorMask(w.batch, spriteMatrix(sprite, pivot, pos, size), tint)
+
+	}
+	if drawn == nil {
+		w.circles.Draw(t)
+	} else {
+		w.batch.Draw(t)
+	}
+
+	// Everything else draws itself
+	w.shapes.Clear()
+	w.drawSnow(w.shapes)
+	w.drawPaths(w.shapes)
+	for _, e := range w.entities {
+		w.shapes.SetColorMask(pixel.Alpha(w.fade(e.Body)))
+		e.Type.Draw(w.shapes, e.Body)
+	}
+	w.shapes.SetColorMask(pixel.Alpha(1))
+	w.drawSlowFields(w.shapes)
+	w.drawGrab(w.shapes)
+	w.shapes.Draw(t)
+}
@@ -0,0 +1,91 @@
+package trees
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+
+	"github.com/scottyw/falling-trees/gzipped"
+)
+
+// Migration upgrades a decoded JSON document by one version, changing its
+// fields in place
+type Migration func(doc map[string]interface{}) error
+
+// Format is a versioned JSON file format. Every document carries its version
+// in a "version" field, and documents written by older builds are brought up
+// to date by running them through the migrations in turn before they are
+// decoded. Documents without a version are version 0, and documents
+// compressed with gzip are decompressed first.
+type Format struct {
+	Name string
+
+	// Migrations[i] upgrades a document from version i to version i+1, so the
+	// current version is the number of migrations
+	Migrations []Migration
+}
+
+// Version is the version documents are written at
+func (f Format) Version() int {
+	return len(f.Migrations)
+}
+
+// Decode reads a document of any version up to the current one into v
+func (f Format) Decode(in io.Reader, v interface{}) error {
+	in, err := gzipped.NewReader(in)
+	if err != nil {
+		return err
+	}
+	data, err := ioutil.ReadAll(in)
+	if err != nil {
+		return err
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	version := 0
+	if raw, ok := doc["version"]; ok {
+		n, ok := raw.(float64)
+		if !ok || n < 0 || n != math.Trunc(n) {
+			return fmt.Errorf("trees: %s has an invalid version %v", f.Name, raw)
+		}
+		if n > float64(f.Version()) {
+			return fmt.Errorf("trees: %s version %v is newer than this build understands", f.Name, n)
+		}
+		version = int(n)
+	}
+	if version == f.Version() {
+		return json.Unmarshal(data, v)
+	}
+	for _, m := range f.Migrations[version:] {
+		if err := m(doc); err != nil {
+			return fmt.Errorf("trees: %s version %d: %v", f.Name, version, err)
+		}
+		version++
+	}
+	doc["version"] = version
+	if data, err = json.Marshal(doc); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// levelBeforeLevels fills in the mountain for files written before there was
+// more than one level to choose from
+func levelBeforeLevels(doc map[string]interface{}) error {
+	if level, _ := doc["level"].(string); level == "" {
+		doc["level"] = mountain.Name
+	}
+	return nil
+}
+
+var (
+	// SnapshotFormat is the format of WriteSnapshot and ReadSnapshot
+	SnapshotFormat = Format{Name: "snapshot", Migrations: []Migration{levelBeforeLevels}}
+
+	// BlueprintFormat is the format of WriteBlueprint and ReadBlueprint
+	BlueprintFormat = Format{Name: "blueprint", Migrations: []Migration{levelBeforeLevels}}
+)
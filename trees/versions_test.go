@@ -0,0 +1,56 @@
+package trees
+
+import (
+	"strings"
+	"testing"
+)
+
+// counter is a format at version 2 whose migrations each add one to a count,
+// so the count says how many of them ran
+var counter = Format{Name: "counter", Migrations: []Migration{bump, bump}}
+
+func bump(doc map[string]interface{}) error {
+	n, _ := doc["count"].(float64)
+	doc["count"] = n + 1
+	return nil
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name  string
+		in    string
+		count int
+		err   string
+	}{
+		{"no version", `{}`, 2, ""},
+		{"oldest", `{"version": 0}`, 2, ""},
+		{"older", `{"version": 1}`, 1, ""},
+		{"current", `{"version": 2}`, 0, ""},
+		{"newer", `{"version": 3}`, 0, "newer than this build"},
+		{"far newer", `{"version": 1e300}`, 0, "newer than this build"},
+		{"negative", `{"version": -1}`, 0, "invalid version"},
+		{"fraction", `{"version": 1.5}`, 0, "invalid version"},
+		{"not a number", `{"version": "1"}`, 0, "invalid version"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var v struct{ Version, Count int }
+			err := counter.Decode(strings.NewReader(test.in), &v)
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Errorf("error is %v, want %q", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v.Version != counter.Version() {
+				t.Errorf("version is %d, want %d", v.Version, counter.Version())
+			}
+			if v.Count != test.count {
+				t.Errorf("%d migrations ran, want %d", v.Count, test.count)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package trees
+
+import (
+	"sort"
+
+	"github.com/faiface/pixel"
+	"golang.org/x/image/colornames"
+)
+
+// wavePalette colours trees by the wave they were dropped in, starting over
+// after the last colour
+var wavePalette = []pixel.RGBA{
+	pixel.ToRGBA(colornames.Forestgreen),
+	pixel.ToRGBA(colornames.Royalblue),
+	pixel.ToRGBA(colornames.Orange),
+	pixel.ToRGBA(colornames.Mediumorchid),
+	pixel.ToRGBA(colornames.Crimson),
+	pixel.ToRGBA(colornames.Turquoise),
+}
+
+// WaveColour is the colour trees from a wave are tinted while ShowWaves is on
+func WaveColour(wave int) pixel.RGBA {
+	return wavePalette[(wave-1+len(wavePalette))%len(wavePalette)]
+}
+
+// NewWave starts a new wave, which everything spawned from now on belongs
+// to, and returns its number. The trees a World starts with are wave 1.
+func (w *World) NewWave() int {
+	w.wave++
+	return w.wave
+}
+
+// Wave is the number of the current wave
+func (w *World) Wave() int {
+	return w.wave
+}
+
+// DropWave scatters n trees over the level as a new wave and returns its
+// number
+func (w *World) DropWave(n int) int {
+	wave := w.NewWave()
+	for i := 0; i < n; i++ {
+		w.ScatterTree()
+	}
+	return wave
+}
+
+// ShowWaves tints every tree by the wave it was dropped in, or stops
+func (w *World) ShowWaves(show bool) {
+	w.showWaves = show
+}
+
+// ShowingWaves reports whether trees are tinted by wave
+func (w *World) ShowingWaves() bool {
+	return w.showWaves
+}
+
+// WaveStats sums up where the trees of one wave have got to
+type WaveStats struct {
+	Wave  int
+	Trees int
+
+	// Average height of the trees above the origin in metres, leaving out
+	// any that have fallen off the world
+	MeanHeight float64
+
+	// Fraction of the trees surrounded on every side by others
+	Buried float64
+}
+
+// WaveStats sums up every wave that still has trees, oldest first. Later
+// waves landing on earlier ones push the earlier waves' Buried up.
+func (w *World) WaveStats() []WaveStats {
+	byWave := map[int]*WaveStats{}
+	heights := map[int]int{}
+	for _, tree := range w.trees {
+		e, ok := w.Lookup(tree)
+		if !ok {
+			continue
+		}
+		s := byWave[e.Wave]
+		if s == nil {
+			s = &WaveStats{Wave: e.Wave}
+			byWave[e.Wave] = s
+		}
+		s.Trees++
+		if p := tree.GetPosition(); Bounds.Contains(pixel.V(p.X, p.Y)) {
+			s.MeanHeight += p.Y
+			heights[e.Wave]++
+		}
+		if touching(tree) >= fullyBuried {
+			s.Buried++
+		}
+	}
+	var stats []WaveStats
+	for wave, s := range byWave {
+		if heights[wave] > 0 {
+			s.MeanHeight /= float64(heights[wave])
+		}
+		s.Buried /= float64(s.Trees)
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Wave < stats[j].Wave })
+	return stats
+}